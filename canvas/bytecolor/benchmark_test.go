@@ -0,0 +1,280 @@
+package bytecolor
+
+import (
+	"container/list"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+)
+
+// point represents a point in the coordinate system.
+// The coordinate system is zero-based.
+type point struct {
+	x int
+	y int
+}
+
+// boolBuffer is a helper type for the legacy bucket fill algorithm.
+type boolBuffer struct {
+	width  int
+	height int
+	values []bool
+}
+
+// newBoolBuffer returns a new boolBuffer.
+func newBoolBuffer(width, height int) *boolBuffer {
+	return &boolBuffer{
+		width:  width,
+		height: height,
+		values: make([]bool, width*height),
+	}
+}
+
+// At returns whether the pixel at (x, y) is processed.
+// It returns true for any point outside the canvas
+// to prevent it from really being processed.
+func (bb *boolBuffer) At(x, y int) bool {
+	index := xyToIndex(bb.width, x, y)
+	if index < 0 || index >= len(bb.values) {
+		return true
+	}
+	return bb.values[index]
+}
+
+// Set sets the pixel at (x, y) as already processed.
+func (bb *boolBuffer) Set(x, y int) {
+	index := xyToIndex(bb.width, x, y)
+	bb.values[index] = true
+}
+
+// legacyBucketFill is the bucket fill algorithm used before the span filling
+// algorithm. It is kept for comparison in the tests and the benchmarks.
+// Each pixel is pushed onto a list, and a whole-canvas boolBuffer is allocated.
+func legacyBucketFill(cnv *Buffer, x, y int, bc bytecolor.Color) {
+	colorToBeReplaced := cnv.at(x, y)
+	pointsToBeFilled := list.New()
+	pointsToBeFilled.PushBack(point{x, y})
+	pointsAlreadyProcessed := newBoolBuffer(cnv.width, cnv.height)
+	for pointsToBeFilled.Len() != 0 {
+		back := pointsToBeFilled.Back()
+		pointsToBeFilled.Remove(back)
+		p := back.Value.(point)
+		x, y := p.x, p.y
+		c, err := cnv.At(x, y)
+		if err != nil {
+			continue
+		}
+		pointsAlreadyProcessed.Set(x, y)
+		if !c.Equals(colorToBeReplaced) {
+			continue
+		}
+		cnv.set(x, y, bc)
+		if !pointsAlreadyProcessed.At(x-1, y) {
+			pointsToBeFilled.PushBack(point{x - 1, y})
+		}
+		if !pointsAlreadyProcessed.At(x+1, y) {
+			pointsToBeFilled.PushBack(point{x + 1, y})
+		}
+		if !pointsAlreadyProcessed.At(x, y-1) {
+			pointsToBeFilled.PushBack(point{x, y - 1})
+		}
+		if !pointsAlreadyProcessed.At(x, y+1) {
+			pointsToBeFilled.PushBack(point{x, y + 1})
+		}
+	}
+}
+
+// fillPattern is a pattern of walls to be bucket filled,
+// with the point from which the fill starts.
+type fillPattern struct {
+	name string
+	draw func(cnv *Buffer)
+	x    int
+	y    int
+}
+
+// fillPatterns are the patterns used in the tests and the benchmarks.
+var fillPatterns = []fillPattern{
+	// No walls, so that the whole canvas is filled
+	{"Empty", func(cnv *Buffer) {}, 0, 0},
+	// A corridor of width 1 spiraling to the center
+	{"Spiral", drawSpiral, 1, 1},
+	// A corridor of width 1 going up and down across the canvas
+	{"Serpentine", drawSerpentine, 0, 0},
+	// Teeth of width 1 hanging from a corridor,
+	// so that each row is split into the most spans
+	{"Comb", drawComb, 0, 0},
+	// Pixels connected diagonally only, so that a 4-connected fill
+	// covers only one of them, while an 8-connected fill covers half of them
+	{"Checkerboard", drawCheckerboard, 0, 0},
+	// Walls placed at random
+	{"Random", drawRandom, 0, 0},
+}
+
+func drawSpiral(cnv *Buffer) {
+	left, top, right, bottom := 0, 2, cnv.width-1, cnv.height-1
+	x, y := 0, 0
+	for step := 0; ; step++ {
+		nx, ny := x, y
+		switch step % 4 {
+		case 0:
+			nx, right = right, right-2
+		case 1:
+			ny, bottom = bottom, bottom-2
+		case 2:
+			nx, left = left, left+2
+		case 3:
+			ny, top = top, top+2
+		}
+		if (step%4 == 0 && nx <= x) || (step%4 == 1 && ny <= y) || (step%4 == 2 && nx >= x) || (step%4 == 3 && ny >= y) {
+			return
+		}
+		cnv.DrawLine(x, y, nx, ny)
+		x, y = nx, ny
+	}
+}
+
+func drawSerpentine(cnv *Buffer) {
+	for x := 1; x < cnv.width; x += 2 {
+		if x%4 == 1 {
+			cnv.DrawLine(x, 1, x, cnv.height-1)
+		} else {
+			cnv.DrawLine(x, 0, x, cnv.height-2)
+		}
+	}
+}
+
+func drawComb(cnv *Buffer) {
+	for x := 1; x < cnv.width; x += 2 {
+		cnv.DrawLine(x, 1, x, cnv.height-1)
+	}
+}
+
+func drawCheckerboard(cnv *Buffer) {
+	for y := 0; y < cnv.height; y++ {
+		for x := 1 - y%2; x < cnv.width; x += 2 {
+			cnv.set(x, y, cnv.foregroundColor)
+		}
+	}
+}
+
+func drawRandom(cnv *Buffer) {
+	r := rand.New(rand.NewSource(1))
+	for i := range cnv.pixels {
+		if r.Intn(5) < 2 {
+			cnv.pixels[i] = cnv.foregroundColor
+		}
+	}
+	cnv.pixels[0] = cnv.backgroundColor
+}
+
+// newPatternBuffer returns a new width x height Buffer with p drawn.
+func newPatternBuffer(width, height int, p fillPattern) *Buffer {
+	cnv, err := NewBuffer(width, height, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+	p.draw(cnv)
+	return cnv
+}
+
+func TestBuffer_BucketFill_Legacy(t *testing.T) {
+	for _, p := range fillPatterns {
+		for _, size := range []point{{1, 1}, {7, 5}, {64, 48}} {
+			if !isPointInsideCanvas(size.x, size.y, p.x, p.y) {
+				continue
+			}
+			expected := newPatternBuffer(size.x, size.y, p)
+			legacyBucketFill(expected, p.x, p.y, bytecolor.Color('o'))
+			cnv := newPatternBuffer(size.x, size.y, p)
+			err := cnv.BucketFill(p.x, p.y, bytecolor.Color('o'))
+			if err != nil {
+				t.Errorf("Case: %s %v, Expected: err == nil, Got: %#v", p.name, size, err)
+			}
+			if !reflect.DeepEqual(cnv.Pixels(), expected.Pixels()) {
+				t.Errorf("Case: %s %v, Expected: %q, Got: %q", p.name, size, expected.Pixels(), cnv.Pixels())
+			}
+		}
+	}
+}
+
+// benchmarkBucketFill benchmarks bucketFill on a 4096x4096 canvas
+// with the pattern named name drawn.
+// The canvas is filled with two colors alternately,
+// so that the same region is filled in each iteration.
+func benchmarkBucketFill(b *testing.B, name string, bucketFill func(cnv *Buffer, x, y int, bc bytecolor.Color)) {
+	for _, p := range fillPatterns {
+		if p.name != name {
+			continue
+		}
+		cnv := newPatternBuffer(4096, 4096, p)
+		colors := []bytecolor.Color{'o', ' '}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			bucketFill(cnv, p.x, p.y, colors[i%2])
+		}
+		return
+	}
+	panic("unknown pattern: " + name)
+}
+
+// bufferBucketFill fills the area by Buffer.BucketFill.
+func bufferBucketFill(cnv *Buffer, x, y int, bc bytecolor.Color) {
+	cnv.BucketFill(x, y, bc)
+}
+
+// bufferDiagonalFill fills the area by Buffer.FloodFill,
+// spreading to the diagonal neighbors.
+func bufferDiagonalFill(cnv *Buffer, x, y int, bc bytecolor.Color) {
+	cnv.FloodFill(x, y, bc, canvas.FillOptions{Diagonal: true})
+}
+
+func BenchmarkBuffer_BucketFill_Empty(b *testing.B) {
+	benchmarkBucketFill(b, "Empty", bufferBucketFill)
+}
+
+func BenchmarkBuffer_BucketFill_Spiral(b *testing.B) {
+	benchmarkBucketFill(b, "Spiral", bufferBucketFill)
+}
+
+func BenchmarkBuffer_BucketFill_Serpentine(b *testing.B) {
+	benchmarkBucketFill(b, "Serpentine", bufferBucketFill)
+}
+
+func BenchmarkBuffer_BucketFill_Comb(b *testing.B) {
+	benchmarkBucketFill(b, "Comb", bufferBucketFill)
+}
+
+func BenchmarkBuffer_BucketFill_Random(b *testing.B) {
+	benchmarkBucketFill(b, "Random", bufferBucketFill)
+}
+
+func BenchmarkBuffer_BucketFill_Legacy_Empty(b *testing.B) {
+	benchmarkBucketFill(b, "Empty", legacyBucketFill)
+}
+
+func BenchmarkBuffer_BucketFill_Legacy_Spiral(b *testing.B) {
+	benchmarkBucketFill(b, "Spiral", legacyBucketFill)
+}
+
+func BenchmarkBuffer_BucketFill_Legacy_Serpentine(b *testing.B) {
+	benchmarkBucketFill(b, "Serpentine", legacyBucketFill)
+}
+
+func BenchmarkBuffer_BucketFill_Legacy_Comb(b *testing.B) {
+	benchmarkBucketFill(b, "Comb", legacyBucketFill)
+}
+
+func BenchmarkBuffer_BucketFill_Legacy_Random(b *testing.B) {
+	benchmarkBucketFill(b, "Random", legacyBucketFill)
+}
+
+// NOTE: The legacy algorithm does not spread to the diagonal neighbors,
+// so there is no legacy counterpart of this benchmark
+func BenchmarkBuffer_FloodFill_Diagonal_Checkerboard(b *testing.B) {
+	benchmarkBucketFill(b, "Checkerboard", bufferDiagonalFill)
+}
@@ -1,32 +1,46 @@
 // Package bytecolor defines the Buffer type,
-// which implements the canvas.BufferBasedCanvas interface.
+// which implements the canvas.BufferBasedCanvas interface,
+// the canvas.ResizableCanvas interface,
+// and the canvas.TransformableCanvas interface.
 package bytecolor
 
 import (
-	"container/list"
+	"unicode/utf8"
 
 	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/canvas/font"
+	"github.com/asukakenji/drawing-challenge/canvas/raster"
 	"github.com/asukakenji/drawing-challenge/color"
 	"github.com/asukakenji/drawing-challenge/color/bytecolor"
 	"github.com/asukakenji/drawing-challenge/common"
 )
 
 // Buffer is a canvas based on a buffer of bytecolor.Color.
-// It implements the canvas.BufferBasedCanvas interface.
+// It implements the canvas.BufferBasedCanvas interface,
+// the canvas.ResizableCanvas interface,
+// and the canvas.TransformableCanvas interface.
 type Buffer struct {
 	width           int
 	height          int
 	backgroundColor bytecolor.Color
 	foregroundColor bytecolor.Color
+	lineMode        canvas.LineMode
+	textMode        canvas.TextMode
 	pixels          []bytecolor.Color
 }
 
-// Ensure that Buffer implements the canvas.BufferBasedCanvas interface.
+// Ensure that Buffer implements the canvas.BufferBasedCanvas interface,
+// the canvas.ResizableCanvas interface,
+// and the canvas.TransformableCanvas interface.
 var (
-	_ canvas.BufferBasedCanvas = &Buffer{}
+	_ canvas.BufferBasedCanvas   = &Buffer{}
+	_ canvas.ResizableCanvas     = &Buffer{}
+	_ canvas.TransformableCanvas = &Buffer{}
 )
 
 // NewBuffer returns a new Buffer.
+// The line mode of the new Buffer is canvas.LineModeHorizontalOrVertical,
+// and the text mode is canvas.TextModeCharacter.
 //
 // Errors
 //
@@ -53,6 +67,15 @@ func (cnv *Buffer) Dimensions() (width, height int) {
 	return cnv.width, cnv.height
 }
 
+// Clone returns a deep copy of the canvas,
+// which is not affected by the subsequent changes of the canvas.
+func (cnv *Buffer) Clone() canvas.Canvas {
+	clone := *cnv
+	clone.pixels = make([]bytecolor.Color, len(cnv.pixels))
+	copy(clone.pixels, cnv.pixels)
+	return &clone
+}
+
 // Pixels returns the underlying pixel buffer.
 func (cnv *Buffer) Pixels() []bytecolor.Color {
 	return cnv.pixels
@@ -106,27 +129,55 @@ func (cnv *Buffer) Set(x, y int, c color.Color) error {
 	return nil
 }
 
-// drawLine is the same as DrawLine, but without boundary checks.
-func (cnv *Buffer) drawLine(x1, y1, x2, y2 int) {
-	bc := cnv.foregroundColor
-	if x1 == x2 {
-		if y1 > y2 {
-			y1, y2 = y2, y1
-		}
-		for y := y1; y <= y2; y++ {
-			cnv.set(x1, y, bc)
-		}
-	} else {
-		if x1 > x2 {
-			x1, x2 = x2, x1
-		}
-		for x := x1; x <= x2; x++ {
-			cnv.set(x, y1, bc)
-		}
+// ForegroundColor returns the foreground color,
+// which is used to draw lines and shapes.
+func (cnv *Buffer) ForegroundColor() color.Color {
+	return cnv.foregroundColor
+}
+
+// SetForegroundColor sets the foreground color,
+// which is used to draw lines and shapes.
+//
+// Errors
+//
+// common.ErrColorTypeNotSupported:
+// Will be returned if c is not supported by the canvas.
+//
+func (cnv *Buffer) SetForegroundColor(c color.Color) error {
+	bc, ok := c.(bytecolor.Color)
+	if !ok {
+		return common.ErrColorTypeNotSupported
+	}
+	cnv.foregroundColor = bc
+	return nil
+}
+
+// SetLineMode sets the line mode used by DrawLine.
+//
+// Errors
+//
+// common.ErrLineModeNotSupported:
+// Will be returned if mode is not supported by the canvas.
+//
+func (cnv *Buffer) SetLineMode(mode canvas.LineMode) error {
+	switch mode {
+	case canvas.LineModeHorizontalOrVertical, canvas.LineModeAnyAngle:
+		cnv.lineMode = mode
+		return nil
+	default:
+		return common.ErrLineModeNotSupported
 	}
 }
 
-// DrawLine draws a horizontal or vertical line.
+// drawLine is the same as DrawLine, but without boundary checks,
+// and the line is drawn with bc.
+func (cnv *Buffer) drawLine(x1, y1, x2, y2 int, bc bytecolor.Color) {
+	raster.Line(x1, y1, x2, y2, func(x, y int) {
+		cnv.set(x, y, bc)
+	})
+}
+
+// DrawLine draws a line from (x1, y1) to (x2, y2).
 //
 // Errors
 //
@@ -134,20 +185,30 @@ func (cnv *Buffer) drawLine(x1, y1, x2, y2 int) {
 // Will be returned if (x1, y1) or (x2, y2) is outside the canvas.
 //
 // common.ErrLineNotHorizontalOrVertical:
-// Will be returned if the line is not horizontal or vertical.
+// Will be returned if the line mode is canvas.LineModeHorizontalOrVertical,
+// and the line is not horizontal or vertical.
 //
 func (cnv *Buffer) DrawLine(x1, y1, x2, y2 int) error {
 	if !isPointInsideCanvas(cnv.width, cnv.height, x1, y1) || !isPointInsideCanvas(cnv.width, cnv.height, x2, y2) {
 		return common.ErrPointOutsideCanvas
 	}
 	// Check whether (x1, y1) and (x2, y2) are horizontally or vertically aligned
-	if x1 != x2 && y1 != y2 {
+	if cnv.lineMode == canvas.LineModeHorizontalOrVertical && x1 != x2 && y1 != y2 {
 		return common.ErrLineNotHorizontalOrVertical
 	}
-	cnv.drawLine(x1, y1, x2, y2)
+	cnv.drawLine(x1, y1, x2, y2, cnv.foregroundColor)
 	return nil
 }
 
+// drawRect is the same as DrawRect, but without boundary checks,
+// and the rectangle is drawn with bc.
+func (cnv *Buffer) drawRect(x1, y1, x2, y2 int, bc bytecolor.Color) {
+	cnv.drawLine(x1, y1, x2, y1, bc)
+	cnv.drawLine(x1, y2, x2, y2, bc)
+	cnv.drawLine(x1, y1, x1, y2, bc)
+	cnv.drawLine(x2, y1, x2, y2, bc)
+}
+
 // DrawRect draws a rectangle.
 //
 // Errors
@@ -159,42 +220,253 @@ func (cnv *Buffer) DrawRect(x1, y1, x2, y2 int) error {
 	if !isPointInsideCanvas(cnv.width, cnv.height, x1, y1) || !isPointInsideCanvas(cnv.width, cnv.height, x2, y2) {
 		return common.ErrPointOutsideCanvas
 	}
-	cnv.drawLine(x1, y1, x2, y1)
-	cnv.drawLine(x1, y2, x2, y2)
-	cnv.drawLine(x1, y1, x1, y2)
-	cnv.drawLine(x2, y1, x2, y2)
+	cnv.drawRect(x1, y1, x2, y2, cnv.foregroundColor)
 	return nil
 }
 
-// bucketFill is the same as BucketFill, but without boundary checks.
-func (cnv *Buffer) bucketFill(bc, colorToBeReplaced bytecolor.Color, pointsToBeFilled *list.List, pointsAlreadyProcessed *boolBuffer) {
-	for pointsToBeFilled.Len() != 0 {
-		back := pointsToBeFilled.Back()
-		pointsToBeFilled.Remove(back)
-		p := back.Value.(point)
-		x, y := p.x, p.y
-		c, err := cnv.At(x, y)
-		if err != nil {
-			continue
+// fillRect paints the interior of the rectangle with bc,
+// without boundary checks.
+func (cnv *Buffer) fillRect(x1, y1, x2, y2 int, bc bytecolor.Color) {
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+	for y := y1 + 1; y < y2; y++ {
+		for x := x1 + 1; x < x2; x++ {
+			cnv.set(x, y, bc)
 		}
-		pointsAlreadyProcessed.Set(x, y)
-		if !c.Equals(colorToBeReplaced) {
-			continue
+	}
+}
+
+// FillRect draws a rectangle with stroke,
+// and paints the interior of the rectangle with fill.
+// The foreground color is used if stroke is nil.
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x1, y1) or (x2, y2) is outside the canvas.
+//
+// common.ErrColorTypeNotSupported:
+// Will be returned if stroke or fill is not supported by the canvas.
+//
+func (cnv *Buffer) FillRect(x1, y1, x2, y2 int, stroke, fill color.Color) error {
+	if !isPointInsideCanvas(cnv.width, cnv.height, x1, y1) || !isPointInsideCanvas(cnv.width, cnv.height, x2, y2) {
+		return common.ErrPointOutsideCanvas
+	}
+	strokeBC := cnv.foregroundColor
+	if stroke != nil {
+		bc, ok := stroke.(bytecolor.Color)
+		if !ok {
+			return common.ErrColorTypeNotSupported
 		}
+		strokeBC = bc
+	}
+	fillBC, ok := fill.(bytecolor.Color)
+	if !ok {
+		return common.ErrColorTypeNotSupported
+	}
+	cnv.fillRect(x1, y1, x2, y2, fillBC)
+	cnv.drawRect(x1, y1, x2, y2, strokeBC)
+	return nil
+}
+
+// drawEllipse is the same as DrawEllipse, but without boundary checks.
+func (cnv *Buffer) drawEllipse(xc, yc, rx, ry int, filled bool) {
+	bc := cnv.foregroundColor
+	raster.Ellipse(xc, yc, rx, ry, filled, func(x, y int) {
 		cnv.set(x, y, bc)
-		if !pointsAlreadyProcessed.At(x-1, y) {
-			pointsToBeFilled.PushBack(point{x - 1, y})
-		}
-		if !pointsAlreadyProcessed.At(x+1, y) {
-			pointsToBeFilled.PushBack(point{x + 1, y})
+	})
+}
+
+// DrawCircle draws a circle centered at (xc, yc) with radius r.
+// The interior of the circle is also painted if filled is true.
+//
+// Errors
+//
+// common.ErrRadiusNegative:
+// Will be returned if r < 0.
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if any part of the circle is outside the canvas.
+//
+func (cnv *Buffer) DrawCircle(xc, yc, r int, filled bool) error {
+	return cnv.DrawEllipse(xc, yc, r, r, filled)
+}
+
+// DrawEllipse draws an axis-aligned ellipse centered at (xc, yc)
+// with horizontal radius rx and vertical radius ry.
+// The interior of the ellipse is also painted if filled is true.
+//
+// Errors
+//
+// common.ErrRadiusNegative:
+// Will be returned if rx < 0, or ry < 0.
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if any part of the ellipse is outside the canvas.
+//
+func (cnv *Buffer) DrawEllipse(xc, yc, rx, ry int, filled bool) error {
+	if rx < 0 || ry < 0 {
+		return common.ErrRadiusNegative
+	}
+	if !isPointInsideCanvas(cnv.width, cnv.height, xc-rx, yc-ry) || !isPointInsideCanvas(cnv.width, cnv.height, xc+rx, yc+ry) {
+		return common.ErrPointOutsideCanvas
+	}
+	cnv.drawEllipse(xc, yc, rx, ry, filled)
+	return nil
+}
+
+// arePointsInsideCanvas returns whether all of points are within the bounds.
+func (cnv *Buffer) arePointsInsideCanvas(points []canvas.Point) bool {
+	for _, p := range points {
+		if !isPointInsideCanvas(cnv.width, cnv.height, p.X, p.Y) {
+			return false
 		}
-		if !pointsAlreadyProcessed.At(x, y-1) {
-			pointsToBeFilled.PushBack(point{x, y - 1})
+	}
+	return true
+}
+
+// drawPolyline is the same as DrawPolyline, but without boundary checks.
+func (cnv *Buffer) drawPolyline(points []canvas.Point) {
+	for i := 1; i < len(points); i++ {
+		cnv.drawLine(points[i-1].X, points[i-1].Y, points[i].X, points[i].Y, cnv.foregroundColor)
+	}
+}
+
+// DrawPolyline draws connected line segments through points.
+// The line mode does not apply to polylines.
+//
+// Errors
+//
+// common.ErrTooFewPoints:
+// Will be returned if len(points) < 2.
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if any of points is outside the canvas.
+//
+func (cnv *Buffer) DrawPolyline(points []canvas.Point) error {
+	if len(points) < 2 {
+		return common.ErrTooFewPoints
+	}
+	if !cnv.arePointsInsideCanvas(points) {
+		return common.ErrPointOutsideCanvas
+	}
+	cnv.drawPolyline(points)
+	return nil
+}
+
+// fillPolygon fills the interior of the polygon with vertices points
+// according to rule, without boundary checks.
+func (cnv *Buffer) fillPolygon(points []canvas.Point, rule canvas.FillRule) {
+	bc := cnv.foregroundColor
+	raster.FillPolygon(points, rule, func(x, y int) {
+		cnv.set(x, y, bc)
+	})
+}
+
+// DrawPolygon draws a closed polygon with vertices points.
+// The interior of the polygon is also painted according to rule
+// if filled is true. The line mode does not apply to polygons.
+//
+// Errors
+//
+// common.ErrTooFewPoints:
+// Will be returned if len(points) < 3.
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if any of points is outside the canvas.
+//
+// common.ErrFillRuleNotSupported:
+// Will be returned if rule is not supported by the canvas.
+//
+func (cnv *Buffer) DrawPolygon(points []canvas.Point, filled bool, rule canvas.FillRule) error {
+	if len(points) < 3 {
+		return common.ErrTooFewPoints
+	}
+	if !cnv.arePointsInsideCanvas(points) {
+		return common.ErrPointOutsideCanvas
+	}
+	if rule != canvas.FillRuleEvenOdd && rule != canvas.FillRuleNonZero {
+		return common.ErrFillRuleNotSupported
+	}
+	if filled {
+		cnv.fillPolygon(points, rule)
+	}
+	cnv.drawPolyline(points)
+	last := len(points) - 1
+	cnv.drawLine(points[last].X, points[last].Y, points[0].X, points[0].Y, cnv.foregroundColor)
+	return nil
+}
+
+// SetTextMode sets the text mode used by DrawText.
+//
+// Errors
+//
+// common.ErrTextModeNotSupported:
+// Will be returned if mode is not supported by the canvas.
+//
+func (cnv *Buffer) SetTextMode(mode canvas.TextMode) error {
+	switch mode {
+	case canvas.TextModeCharacter, canvas.TextModeBitmap:
+		cnv.textMode = mode
+		return nil
+	default:
+		return common.ErrTextModeNotSupported
+	}
+}
+
+// DrawText draws text. (x, y) is the top-left corner of the text.
+//
+// In canvas.TextModeCharacter, characters other than printable ASCII
+// characters are drawn as '?'.
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if any part of the text is outside the canvas.
+//
+func (cnv *Buffer) DrawText(x, y int, text string) error {
+	if !isPointInsideCanvas(cnv.width, cnv.height, x, y) {
+		return common.ErrPointOutsideCanvas
+	}
+	if cnv.textMode == canvas.TextModeBitmap {
+		width, height := font.Bounds(text)
+		if width != 0 && !isPointInsideCanvas(cnv.width, cnv.height, x+width-1, y+height-1) {
+			return common.ErrPointOutsideCanvas
 		}
-		if !pointsAlreadyProcessed.At(x, y+1) {
-			pointsToBeFilled.PushBack(point{x, y + 1})
+		bc := cnv.foregroundColor
+		font.Draw(x, y, text, func(x, y int) {
+			cnv.set(x, y, bc)
+		})
+		return nil
+	}
+	n := utf8.RuneCountInString(text)
+	if n != 0 && !isPointInsideCanvas(cnv.width, cnv.height, x+n-1, y) {
+		return common.ErrPointOutsideCanvas
+	}
+	for _, r := range text {
+		if r < ' ' || r > '~' {
+			r = '?'
 		}
+		cnv.set(x, y, bytecolor.Color(r))
+		x++
 	}
+	return nil
+}
+
+// bucketFill is the same as BucketFill, but without boundary checks.
+// bc must be different from colorToBeReplaced,
+// so that a filled pixel is never filled again.
+// The area spreads to the diagonal neighbors if diagonal is true.
+func (cnv *Buffer) bucketFill(x, y int, bc, colorToBeReplaced bytecolor.Color, diagonal bool) {
+	raster.FloodFill(cnv.width, cnv.height, x, y, diagonal, func(x, y int) bool {
+		return cnv.at(x, y) == colorToBeReplaced
+	}, func(x1, x2, y int) {
+		fill(cnv.pixels[xyToIndex(cnv.width, x1, y):xyToIndex(cnv.width, x2+1, y)], bc)
+	})
 }
 
 // BucketFill fills the area enclosing (x, y). The pixels connecting to
@@ -209,6 +481,29 @@ func (cnv *Buffer) bucketFill(bc, colorToBeReplaced bytecolor.Color, pointsToBeF
 // Will be returned if c is not supported by the canvas.
 //
 func (cnv *Buffer) BucketFill(x, y int, c color.Color) error {
+	return cnv.FloodFill(x, y, c, canvas.FillOptions{})
+}
+
+// FloodFill fills the area enclosing (x, y) like BucketFill,
+// but the area is determined according to opts.
+// A positive opts.Tolerance is not supported,
+// since the colors of the canvas have no components.
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x, y) is outside the canvas.
+//
+// common.ErrColorTypeNotSupported:
+// Will be returned if c is not supported by the canvas.
+//
+// common.ErrToleranceNegative:
+// Will be returned if opts.Tolerance < 0.
+//
+// common.ErrToleranceNotSupported:
+// Will be returned if opts.Tolerance > 0.
+//
+func (cnv *Buffer) FloodFill(x, y int, c color.Color, opts canvas.FillOptions) error {
 	if !isPointInsideCanvas(cnv.width, cnv.height, x, y) {
 		return common.ErrPointOutsideCanvas
 	}
@@ -216,10 +511,172 @@ func (cnv *Buffer) BucketFill(x, y int, c color.Color) error {
 	if !ok {
 		return common.ErrColorTypeNotSupported
 	}
+	if opts.Tolerance < 0 {
+		return common.ErrToleranceNegative
+	}
+	if opts.Tolerance > 0 {
+		return common.ErrToleranceNotSupported
+	}
 	colorToBeReplaced := cnv.at(x, y)
-	pointsToBeFilled := list.New()
-	pointsToBeFilled.PushBack(point{x, y})
-	pointsAlreadyProcessed := newBoolBuffer(cnv.width, cnv.height)
-	cnv.bucketFill(bc, colorToBeReplaced, pointsToBeFilled, pointsAlreadyProcessed)
+	if bc == colorToBeReplaced {
+		return nil
+	}
+	if opts.Global {
+		for i, pc := range cnv.pixels {
+			if pc == colorToBeReplaced {
+				cnv.pixels[i] = bc
+			}
+		}
+		return nil
+	}
+	cnv.bucketFill(x, y, bc, colorToBeReplaced, opts.Diagonal)
+	return nil
+}
+
+// Crop crops the canvas to the rectangle with corners (x1, y1) and (x2, y2).
+// The pixels on the edges of the rectangle are kept.
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x1, y1) or (x2, y2) is outside the canvas.
+//
+func (cnv *Buffer) Crop(x1, y1, x2, y2 int) error {
+	if !isPointInsideCanvas(cnv.width, cnv.height, x1, y1) || !isPointInsideCanvas(cnv.width, cnv.height, x2, y2) {
+		return common.ErrPointOutsideCanvas
+	}
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+	width, height := x2-x1+1, y2-y1+1
+	pixels := make([]bytecolor.Color, width*height)
+	for y := 0; y < height; y++ {
+		copy(pixels[xyToIndex(width, 0, y):], cnv.pixels[xyToIndex(cnv.width, x1, y1+y):xyToIndex(cnv.width, x2+1, y1+y)])
+	}
+	cnv.width, cnv.height, cnv.pixels = width, height, pixels
+	return nil
+}
+
+// Extend extends the canvas by left, top, right, and bottom pixels
+// on the respective sides. The new pixels are in the background color.
+//
+// Errors
+//
+// common.ErrExtentNegative:
+// Will be returned if left < 0, top < 0, right < 0, or bottom < 0.
+//
+func (cnv *Buffer) Extend(left, top, right, bottom int) error {
+	if left < 0 || top < 0 || right < 0 || bottom < 0 {
+		return common.ErrExtentNegative
+	}
+	width, height := cnv.width+left+right, cnv.height+top+bottom
+	pixels := make([]bytecolor.Color, width*height)
+	fill(pixels, cnv.backgroundColor)
+	for y := 0; y < cnv.height; y++ {
+		copy(pixels[xyToIndex(width, left, top+y):], cnv.pixels[xyToIndex(cnv.width, 0, y):xyToIndex(cnv.width, 0, y+1)])
+	}
+	cnv.width, cnv.height, cnv.pixels = width, height, pixels
+	return nil
+}
+
+// Scale scales the canvas to width x height.
+// Each pixel takes the color of the nearest pixel of the original canvas.
+//
+// Errors
+//
+// common.ErrWidthOrHeightNotPositive:
+// Will be returned if width <= 0, or height <= 0.
+//
+func (cnv *Buffer) Scale(width, height int) error {
+	if width <= 0 || height <= 0 {
+		return common.ErrWidthOrHeightNotPositive
+	}
+	pixels := make([]bytecolor.Color, width*height)
+	for y := 0; y < height; y++ {
+		sy := nearest(y, height, cnv.height)
+		for x := 0; x < width; x++ {
+			pixels[xyToIndex(width, x, y)] = cnv.at(nearest(x, width, cnv.width), sy)
+		}
+	}
+	cnv.width, cnv.height, cnv.pixels = width, height, pixels
+	return nil
+}
+
+// transformed returns the pixels of the width x height rectangle
+// with top-left corner (x0, y0) transformed by t,
+// with the width and height of the transformed rectangle.
+func (cnv *Buffer) transformed(x0, y0, width, height int, t canvas.Transform) ([]bytecolor.Color, int, int) {
+	tw, th := transformedSize(t, width, height)
+	pixels := make([]bytecolor.Color, tw*th)
+	for y := 0; y < th; y++ {
+		for x := 0; x < tw; x++ {
+			sx, sy := sourcePoint(t, width, height, x, y)
+			pixels[xyToIndex(tw, x, y)] = cnv.at(x0+sx, y0+sy)
+		}
+	}
+	return pixels, tw, th
+}
+
+// Transform flips or rotates the whole canvas.
+// The width and height are swapped by canvas.TransformRotate90
+// and canvas.TransformRotate270.
+//
+// Errors
+//
+// common.ErrTransformNotSupported:
+// Will be returned if t is not supported by the canvas.
+//
+func (cnv *Buffer) Transform(t canvas.Transform) error {
+	if !isTransformSupported(t) {
+		return common.ErrTransformNotSupported
+	}
+	cnv.pixels, cnv.width, cnv.height = cnv.transformed(0, 0, cnv.width, cnv.height, t)
+	return nil
+}
+
+// TransformRect flips or rotates the rectangle with corners (x1, y1)
+// and (x2, y2). The top-left corner of the rectangle is kept. If the width
+// and height are swapped, the pixels of the rectangle not covered by the
+// transformed one are painted with the background color.
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x1, y1) or (x2, y2) is outside the canvas,
+// or any part of the transformed rectangle is outside the canvas.
+//
+// common.ErrTransformNotSupported:
+// Will be returned if t is not supported by the canvas.
+//
+func (cnv *Buffer) TransformRect(x1, y1, x2, y2 int, t canvas.Transform) error {
+	if !isPointInsideCanvas(cnv.width, cnv.height, x1, y1) || !isPointInsideCanvas(cnv.width, cnv.height, x2, y2) {
+		return common.ErrPointOutsideCanvas
+	}
+	if !isTransformSupported(t) {
+		return common.ErrTransformNotSupported
+	}
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+	width, height := x2-x1+1, y2-y1+1
+	tw, th := transformedSize(t, width, height)
+	if !isPointInsideCanvas(cnv.width, cnv.height, x1+tw-1, y1+th-1) {
+		return common.ErrPointOutsideCanvas
+	}
+	pixels, _, _ := cnv.transformed(x1, y1, width, height, t)
+	if tw != width {
+		for y := y1; y <= y2; y++ {
+			fill(cnv.pixels[xyToIndex(cnv.width, x1, y):xyToIndex(cnv.width, x2+1, y)], cnv.backgroundColor)
+		}
+	}
+	for y := 0; y < th; y++ {
+		copy(cnv.pixels[xyToIndex(cnv.width, x1, y1+y):], pixels[xyToIndex(tw, 0, y):xyToIndex(tw, 0, y+1)])
+	}
 	return nil
 }
@@ -4,11 +4,40 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/asukakenji/drawing-challenge/canvas"
 	"github.com/asukakenji/drawing-challenge/color"
 	"github.com/asukakenji/drawing-challenge/color/bytecolor"
 	"github.com/asukakenji/drawing-challenge/common"
 )
 
+// newRowsBuffer returns a new Buffer with a ' ' background and a 'x'
+// foreground, whose pixels are given by rows, one string per row.
+func newRowsBuffer(rows []string) *Buffer {
+	cnv, err := NewBuffer(len(rows[0]), len(rows), bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+	for j, row := range rows {
+		for i := range row {
+			cnv.set(i, j, bytecolor.Color(row[i]))
+		}
+	}
+	return cnv
+}
+
+// rows returns the pixels of cnv as a slice of strings, one string per row.
+func rows(cnv *Buffer) []string {
+	result := make([]string, cnv.height)
+	for j := range result {
+		row := make([]byte, cnv.width)
+		for i := range row {
+			row[i] = byte(cnv.at(i, j))
+		}
+		result[j] = string(row)
+	}
+	return result
+}
+
 func TestNewBuffer(t *testing.T) {
 	// Positive Cases
 	casesPos := []struct {
@@ -87,6 +116,35 @@ func TestBuffer_Dimensions(t *testing.T) {
 	}
 }
 
+func TestBuffer_Clone(t *testing.T) {
+	cnv, err := NewBuffer(3, 1, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.DrawLine(0, 0, 1, 0)
+	if err != nil {
+		panic(err)
+	}
+
+	clone := cnv.Clone().(*Buffer)
+	err = cnv.DrawLine(1, 0, 2, 0)
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.SetForegroundColor(bytecolor.Color('o'))
+	if err != nil {
+		panic(err)
+	}
+
+	pixels := []bytecolor.Color{'x', 'x', ' '}
+	if !reflect.DeepEqual(clone.Pixels(), pixels) {
+		t.Errorf("Expected: %#v, Got: %#v", pixels, clone.Pixels())
+	}
+	if c := clone.ForegroundColor(); c != bytecolor.Color('x') {
+		t.Errorf("Expected: %#v, Got: %#v", bytecolor.Color('x'), c)
+	}
+}
+
 // This type is created for testing purpose only
 type dummyColor byte
 
@@ -317,6 +375,150 @@ func TestBuffer_DrawLine(t *testing.T) {
 	}
 }
 
+func TestBuffer_SetForegroundColor(t *testing.T) {
+	cnv, err := NewBuffer(3, 1, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+
+	c := cnv.ForegroundColor()
+	if c != bytecolor.Color('x') {
+		t.Errorf("Expected: %#v, Got: %#v", bytecolor.Color('x'), c)
+	}
+
+	err = cnv.SetForegroundColor(bytecolor.Color('R'))
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	c = cnv.ForegroundColor()
+	if c != bytecolor.Color('R') {
+		t.Errorf("Expected: %#v, Got: %#v", bytecolor.Color('R'), c)
+	}
+	err = cnv.DrawLine(0, 0, 2, 0)
+	if err != nil {
+		panic(err)
+	}
+	pixels := []bytecolor.Color{'R', 'R', 'R'}
+	if !reflect.DeepEqual(cnv.Pixels(), pixels) {
+		t.Errorf("Expected: %#v, Got: %#v", pixels, cnv.Pixels())
+	}
+
+	err = cnv.SetForegroundColor(dummyColor('G'))
+	if err != common.ErrColorTypeNotSupported {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrColorTypeNotSupported, err)
+	}
+	err = cnv.SetForegroundColor(nil)
+	if err != common.ErrColorTypeNotSupported {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrColorTypeNotSupported, err)
+	}
+}
+
+func TestBuffer_SetLineMode(t *testing.T) {
+	cnv, err := NewBuffer(2, 3, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+
+	cases := []struct {
+		mode canvas.LineMode
+		err  error
+	}{
+		{canvas.LineModeHorizontalOrVertical, nil},
+		{canvas.LineModeAnyAngle, nil},
+		{canvas.LineMode(-1), common.ErrLineModeNotSupported},
+		{canvas.LineMode(2), common.ErrLineModeNotSupported},
+	}
+	for _, c := range cases {
+		err = cnv.SetLineMode(c.mode)
+		if err != c.err {
+			t.Errorf("Case: %d, Expected: %#v, Got: %#v", c.mode, c.err, err)
+		}
+	}
+}
+
+func TestBuffer_DrawLine_AnyAngle(t *testing.T) {
+	// Positive Cases
+	casesPos := []struct {
+		w       int
+		h       int
+		bgColor bytecolor.Color
+		fgColor bytecolor.Color
+		x1      int
+		y1      int
+		x2      int
+		y2      int
+		pixels  []bytecolor.Color
+	}{
+		{5, 5, bytecolor.Color(' '), bytecolor.Color('x'), 0, 0, 4, 4, []bytecolor.Color{
+			'x', ' ', ' ', ' ', ' ',
+			' ', 'x', ' ', ' ', ' ',
+			' ', ' ', 'x', ' ', ' ',
+			' ', ' ', ' ', 'x', ' ',
+			' ', ' ', ' ', ' ', 'x',
+		}},
+		{5, 5, bytecolor.Color(' '), bytecolor.Color('x'), 4, 0, 0, 4, []bytecolor.Color{
+			' ', ' ', ' ', ' ', 'x',
+			' ', ' ', ' ', 'x', ' ',
+			' ', ' ', 'x', ' ', ' ',
+			' ', 'x', ' ', ' ', ' ',
+			'x', ' ', ' ', ' ', ' ',
+		}},
+		{7, 3, bytecolor.Color(' '), bytecolor.Color('x'), 0, 0, 6, 2, []bytecolor.Color{
+			'x', 'x', ' ', ' ', ' ', ' ', ' ',
+			' ', ' ', 'x', 'x', 'x', ' ', ' ',
+			' ', ' ', ' ', ' ', ' ', 'x', 'x',
+		}},
+		{7, 3, bytecolor.Color(' '), bytecolor.Color('x'), 6, 2, 0, 0, []bytecolor.Color{
+			'x', 'x', ' ', ' ', ' ', ' ', ' ',
+			' ', ' ', 'x', 'x', 'x', ' ', ' ',
+			' ', ' ', ' ', ' ', ' ', 'x', 'x',
+		}}, // (points in reverse order)
+		{3, 5, bytecolor.Color(' '), bytecolor.Color('x'), 0, 0, 2, 4, []bytecolor.Color{
+			'x', ' ', ' ',
+			' ', 'x', ' ',
+			' ', 'x', ' ',
+			' ', ' ', 'x',
+			' ', ' ', 'x',
+		}},
+		{3, 3, bytecolor.Color(' '), bytecolor.Color('x'), 0, 1, 2, 1, []bytecolor.Color{
+			' ', ' ', ' ',
+			'x', 'x', 'x',
+			' ', ' ', ' ',
+		}}, // (horizontal lines are still accepted)
+	}
+	for _, c := range casesPos {
+		cnv, err := NewBuffer(c.w, c.h, c.bgColor, c.fgColor)
+		if err != nil {
+			t.Errorf("Case: (%d, %d, %#v, %#v, %d, %d, %d, %d), NewBuffer returned err != nil: %#v", c.w, c.h, c.bgColor, c.fgColor, c.x1, c.y1, c.x2, c.y2, err)
+		}
+		err = cnv.SetLineMode(canvas.LineModeAnyAngle)
+		if err != nil {
+			t.Errorf("Case: (%d, %d, %#v, %#v, %d, %d, %d, %d), SetLineMode returned err != nil: %#v", c.w, c.h, c.bgColor, c.fgColor, c.x1, c.y1, c.x2, c.y2, err)
+		}
+		err = cnv.DrawLine(c.x1, c.y1, c.x2, c.y2)
+		if err != nil {
+			t.Errorf("Case: (%d, %d, %#v, %#v, %d, %d, %d, %d), Expected: err == nil, Got: %#v", c.w, c.h, c.bgColor, c.fgColor, c.x1, c.y1, c.x2, c.y2, err)
+		}
+		if !reflect.DeepEqual(cnv.Pixels(), c.pixels) {
+			t.Errorf("Case: (%d, %d, %#v, %#v, %d, %d, %d, %d), Expected: %#v, Got: %#v", c.w, c.h, c.bgColor, c.fgColor, c.x1, c.y1, c.x2, c.y2, c.pixels, cnv.Pixels())
+		}
+	}
+
+	// Negative Cases
+	cnv, err := NewBuffer(2, 3, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.SetLineMode(canvas.LineModeAnyAngle)
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.DrawLine(0, 0, 2, 3)
+	if err != common.ErrPointOutsideCanvas {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrPointOutsideCanvas, err)
+	}
+}
+
 func TestBuffer_DrawRect(t *testing.T) {
 	// Positive Cases
 	casesPos := []struct {
@@ -390,6 +592,480 @@ func TestBuffer_DrawRect(t *testing.T) {
 	}
 }
 
+func TestBuffer_FillRect(t *testing.T) {
+	// Positive Cases
+	casesPos := []struct {
+		x1     int
+		y1     int
+		x2     int
+		y2     int
+		stroke color.Color
+		fill   color.Color
+		pixels []bytecolor.Color
+	}{
+		{0, 0, 4, 3, nil, bytecolor.Color('o'), []bytecolor.Color{
+			'x', 'x', 'x', 'x', 'x', ' ',
+			'x', 'o', 'o', 'o', 'x', ' ',
+			'x', 'o', 'o', 'o', 'x', ' ',
+			'x', 'x', 'x', 'x', 'x', ' ',
+		}},
+		{5, 3, 2, 1, bytecolor.Color('#'), bytecolor.Color('o'), []bytecolor.Color{
+			' ', ' ', ' ', ' ', ' ', ' ',
+			' ', ' ', '#', '#', '#', '#',
+			' ', ' ', '#', 'o', 'o', '#',
+			' ', ' ', '#', '#', '#', '#',
+		}},
+		{1, 1, 2, 2, nil, bytecolor.Color('o'), []bytecolor.Color{
+			' ', ' ', ' ', ' ', ' ', ' ',
+			' ', 'x', 'x', ' ', ' ', ' ',
+			' ', 'x', 'x', ' ', ' ', ' ',
+			' ', ' ', ' ', ' ', ' ', ' ',
+		}}, // (no interior)
+	}
+	for _, c := range casesPos {
+		cnv, err := NewBuffer(6, 4, bytecolor.Color(' '), bytecolor.Color('x'))
+		if err != nil {
+			panic(err)
+		}
+		err = cnv.FillRect(c.x1, c.y1, c.x2, c.y2, c.stroke, c.fill)
+		if err != nil {
+			t.Errorf("Case: (%d, %d, %d, %d, %#v, %#v), Expected: err == nil, Got: %#v", c.x1, c.y1, c.x2, c.y2, c.stroke, c.fill, err)
+		}
+		if !reflect.DeepEqual(cnv.Pixels(), c.pixels) {
+			t.Errorf("Case: (%d, %d, %d, %d, %#v, %#v), Expected: %#v, Got: %#v", c.x1, c.y1, c.x2, c.y2, c.stroke, c.fill, c.pixels, cnv.Pixels())
+		}
+	}
+
+	// Negative Cases
+	casesNeg := []struct {
+		x1     int
+		y1     int
+		x2     int
+		y2     int
+		stroke color.Color
+		fill   color.Color
+		err    error
+	}{
+		{-1, 0, 4, 3, nil, bytecolor.Color('o'), common.ErrPointOutsideCanvas},
+		{0, 0, 6, 3, nil, bytecolor.Color('o'), common.ErrPointOutsideCanvas},
+		{0, 0, 4, 3, dummyColor('#'), bytecolor.Color('o'), common.ErrColorTypeNotSupported},
+		{0, 0, 4, 3, nil, dummyColor('o'), common.ErrColorTypeNotSupported},
+		{0, 0, 4, 3, nil, nil, common.ErrColorTypeNotSupported},
+	}
+	for _, c := range casesNeg {
+		cnv, err := NewBuffer(6, 4, bytecolor.Color(' '), bytecolor.Color('x'))
+		if err != nil {
+			panic(err)
+		}
+		err = cnv.FillRect(c.x1, c.y1, c.x2, c.y2, c.stroke, c.fill)
+		if err != c.err {
+			t.Errorf("Case: (%d, %d, %d, %d, %#v, %#v), Expected: %#v, Got: %#v", c.x1, c.y1, c.x2, c.y2, c.stroke, c.fill, c.err, err)
+		}
+	}
+}
+
+func TestBuffer_DrawCircle(t *testing.T) {
+	// Positive Cases
+	casesPos := []struct {
+		w       int
+		h       int
+		bgColor bytecolor.Color
+		fgColor bytecolor.Color
+		xc      int
+		yc      int
+		r       int
+		filled  bool
+		pixels  []bytecolor.Color
+	}{
+		{3, 3, bytecolor.Color(' '), bytecolor.Color('x'), 1, 1, 0, false, []bytecolor.Color{
+			' ', ' ', ' ',
+			' ', 'x', ' ',
+			' ', ' ', ' ',
+		}},
+		{3, 3, bytecolor.Color(' '), bytecolor.Color('x'), 1, 1, 1, false, []bytecolor.Color{
+			' ', 'x', ' ',
+			'x', ' ', 'x',
+			' ', 'x', ' ',
+		}},
+		{3, 3, bytecolor.Color(' '), bytecolor.Color('x'), 1, 1, 1, true, []bytecolor.Color{
+			' ', 'x', ' ',
+			'x', 'x', 'x',
+			' ', 'x', ' ',
+		}},
+		{7, 7, bytecolor.Color(' '), bytecolor.Color('x'), 3, 3, 3, false, []bytecolor.Color{
+			' ', ' ', 'x', 'x', 'x', ' ', ' ',
+			' ', 'x', ' ', ' ', ' ', 'x', ' ',
+			'x', ' ', ' ', ' ', ' ', ' ', 'x',
+			'x', ' ', ' ', ' ', ' ', ' ', 'x',
+			'x', ' ', ' ', ' ', ' ', ' ', 'x',
+			' ', 'x', ' ', ' ', ' ', 'x', ' ',
+			' ', ' ', 'x', 'x', 'x', ' ', ' ',
+		}},
+		{7, 7, bytecolor.Color(' '), bytecolor.Color('x'), 3, 3, 3, true, []bytecolor.Color{
+			' ', ' ', 'x', 'x', 'x', ' ', ' ',
+			' ', 'x', 'x', 'x', 'x', 'x', ' ',
+			'x', 'x', 'x', 'x', 'x', 'x', 'x',
+			'x', 'x', 'x', 'x', 'x', 'x', 'x',
+			'x', 'x', 'x', 'x', 'x', 'x', 'x',
+			' ', 'x', 'x', 'x', 'x', 'x', ' ',
+			' ', ' ', 'x', 'x', 'x', ' ', ' ',
+		}},
+	}
+	for _, c := range casesPos {
+		cnv, err := NewBuffer(c.w, c.h, c.bgColor, c.fgColor)
+		if err != nil {
+			t.Errorf("Case: (%d, %d, %#v, %#v, %d, %d, %d, %t), NewBuffer returned err != nil: %#v", c.w, c.h, c.bgColor, c.fgColor, c.xc, c.yc, c.r, c.filled, err)
+		}
+		err = cnv.DrawCircle(c.xc, c.yc, c.r, c.filled)
+		if err != nil {
+			t.Errorf("Case: (%d, %d, %#v, %#v, %d, %d, %d, %t), Expected: err == nil, Got: %#v", c.w, c.h, c.bgColor, c.fgColor, c.xc, c.yc, c.r, c.filled, err)
+		}
+		if !reflect.DeepEqual(cnv.Pixels(), c.pixels) {
+			t.Errorf("Case: (%d, %d, %#v, %#v, %d, %d, %d, %t), Expected: %#v, Got: %#v", c.w, c.h, c.bgColor, c.fgColor, c.xc, c.yc, c.r, c.filled, c.pixels, cnv.Pixels())
+		}
+	}
+
+	// Negative Cases
+	casesNeg := []struct {
+		w       int
+		h       int
+		bgColor bytecolor.Color
+		fgColor bytecolor.Color
+		xc      int
+		yc      int
+		r       int
+		err     error
+	}{
+		{3, 3, bytecolor.Color(' '), bytecolor.Color('x'), 1, 1, -1, common.ErrRadiusNegative},
+		{3, 3, bytecolor.Color(' '), bytecolor.Color('x'), 1, 1, 2, common.ErrPointOutsideCanvas},
+		{3, 3, bytecolor.Color(' '), bytecolor.Color('x'), 0, 1, 1, common.ErrPointOutsideCanvas},
+		{3, 3, bytecolor.Color(' '), bytecolor.Color('x'), 1, 2, 1, common.ErrPointOutsideCanvas},
+	}
+	for _, c := range casesNeg {
+		cnv, err := NewBuffer(c.w, c.h, c.bgColor, c.fgColor)
+		if err != nil {
+			t.Errorf("Case: (%d, %d, %#v, %#v, %d, %d, %d), NewBuffer returned err != nil: %#v", c.w, c.h, c.bgColor, c.fgColor, c.xc, c.yc, c.r, err)
+		}
+		err = cnv.DrawCircle(c.xc, c.yc, c.r, false)
+		if err != c.err {
+			t.Errorf("Case: (%d, %d, %#v, %#v, %d, %d, %d), Expected: %#v, Got: %#v", c.w, c.h, c.bgColor, c.fgColor, c.xc, c.yc, c.r, c.err, err)
+		}
+	}
+}
+
+func TestBuffer_DrawEllipse(t *testing.T) {
+	// Positive Cases
+	casesPos := []struct {
+		w       int
+		h       int
+		bgColor bytecolor.Color
+		fgColor bytecolor.Color
+		xc      int
+		yc      int
+		rx      int
+		ry      int
+		filled  bool
+		pixels  []bytecolor.Color
+	}{
+		{9, 5, bytecolor.Color(' '), bytecolor.Color('x'), 4, 2, 4, 2, false, []bytecolor.Color{
+			' ', ' ', 'x', 'x', 'x', 'x', 'x', ' ', ' ',
+			' ', 'x', ' ', ' ', ' ', ' ', ' ', 'x', ' ',
+			'x', ' ', ' ', ' ', ' ', ' ', ' ', ' ', 'x',
+			' ', 'x', ' ', ' ', ' ', ' ', ' ', 'x', ' ',
+			' ', ' ', 'x', 'x', 'x', 'x', 'x', ' ', ' ',
+		}},
+		{9, 5, bytecolor.Color(' '), bytecolor.Color('x'), 4, 2, 4, 2, true, []bytecolor.Color{
+			' ', ' ', 'x', 'x', 'x', 'x', 'x', ' ', ' ',
+			' ', 'x', 'x', 'x', 'x', 'x', 'x', 'x', ' ',
+			'x', 'x', 'x', 'x', 'x', 'x', 'x', 'x', 'x',
+			' ', 'x', 'x', 'x', 'x', 'x', 'x', 'x', ' ',
+			' ', ' ', 'x', 'x', 'x', 'x', 'x', ' ', ' ',
+		}},
+		{5, 3, bytecolor.Color(' '), bytecolor.Color('x'), 2, 1, 2, 0, false, []bytecolor.Color{
+			' ', ' ', ' ', ' ', ' ',
+			'x', 'x', 'x', 'x', 'x',
+			' ', ' ', ' ', ' ', ' ',
+		}}, // (degenerate ellipse)
+		{3, 5, bytecolor.Color(' '), bytecolor.Color('x'), 1, 2, 0, 2, true, []bytecolor.Color{
+			' ', 'x', ' ',
+			' ', 'x', ' ',
+			' ', 'x', ' ',
+			' ', 'x', ' ',
+			' ', 'x', ' ',
+		}}, // (degenerate ellipse)
+	}
+	for _, c := range casesPos {
+		cnv, err := NewBuffer(c.w, c.h, c.bgColor, c.fgColor)
+		if err != nil {
+			t.Errorf("Case: (%d, %d, %#v, %#v, %d, %d, %d, %d, %t), NewBuffer returned err != nil: %#v", c.w, c.h, c.bgColor, c.fgColor, c.xc, c.yc, c.rx, c.ry, c.filled, err)
+		}
+		err = cnv.DrawEllipse(c.xc, c.yc, c.rx, c.ry, c.filled)
+		if err != nil {
+			t.Errorf("Case: (%d, %d, %#v, %#v, %d, %d, %d, %d, %t), Expected: err == nil, Got: %#v", c.w, c.h, c.bgColor, c.fgColor, c.xc, c.yc, c.rx, c.ry, c.filled, err)
+		}
+		if !reflect.DeepEqual(cnv.Pixels(), c.pixels) {
+			t.Errorf("Case: (%d, %d, %#v, %#v, %d, %d, %d, %d, %t), Expected: %#v, Got: %#v", c.w, c.h, c.bgColor, c.fgColor, c.xc, c.yc, c.rx, c.ry, c.filled, c.pixels, cnv.Pixels())
+		}
+	}
+
+	// Negative Cases
+	casesNeg := []struct {
+		w       int
+		h       int
+		bgColor bytecolor.Color
+		fgColor bytecolor.Color
+		xc      int
+		yc      int
+		rx      int
+		ry      int
+		err     error
+	}{
+		{9, 5, bytecolor.Color(' '), bytecolor.Color('x'), 4, 2, -1, 2, common.ErrRadiusNegative},
+		{9, 5, bytecolor.Color(' '), bytecolor.Color('x'), 4, 2, 4, -1, common.ErrRadiusNegative},
+		{9, 5, bytecolor.Color(' '), bytecolor.Color('x'), 4, 2, 5, 2, common.ErrPointOutsideCanvas},
+		{9, 5, bytecolor.Color(' '), bytecolor.Color('x'), 4, 2, 4, 3, common.ErrPointOutsideCanvas},
+	}
+	for _, c := range casesNeg {
+		cnv, err := NewBuffer(c.w, c.h, c.bgColor, c.fgColor)
+		if err != nil {
+			t.Errorf("Case: (%d, %d, %#v, %#v, %d, %d, %d, %d), NewBuffer returned err != nil: %#v", c.w, c.h, c.bgColor, c.fgColor, c.xc, c.yc, c.rx, c.ry, err)
+		}
+		err = cnv.DrawEllipse(c.xc, c.yc, c.rx, c.ry, false)
+		if err != c.err {
+			t.Errorf("Case: (%d, %d, %#v, %#v, %d, %d, %d, %d), Expected: %#v, Got: %#v", c.w, c.h, c.bgColor, c.fgColor, c.xc, c.yc, c.rx, c.ry, c.err, err)
+		}
+	}
+}
+
+func TestBuffer_DrawPolyline(t *testing.T) {
+	cnv, err := NewBuffer(5, 4, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+
+	// Positive Cases
+	err = cnv.DrawPolyline([]canvas.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 3}, {X: 1, Y: 1}})
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	pixels := []bytecolor.Color{
+		'x', 'x', 'x', 'x', 'x',
+		' ', 'x', ' ', ' ', 'x',
+		' ', ' ', 'x', 'x', 'x',
+		' ', ' ', ' ', ' ', 'x',
+	}
+	if !reflect.DeepEqual(cnv.Pixels(), pixels) {
+		t.Errorf("Expected: %#v, Got: %#v", pixels, cnv.Pixels())
+	}
+
+	// Negative Cases
+	casesNeg := []struct {
+		points []canvas.Point
+		err    error
+	}{
+		{nil, common.ErrTooFewPoints},
+		{[]canvas.Point{{X: 0, Y: 0}}, common.ErrTooFewPoints},
+		{[]canvas.Point{{X: 0, Y: 0}, {X: 5, Y: 0}}, common.ErrPointOutsideCanvas},
+		{[]canvas.Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: -1}}, common.ErrPointOutsideCanvas},
+	}
+	for _, c := range casesNeg {
+		err = cnv.DrawPolyline(c.points)
+		if err != c.err {
+			t.Errorf("Case: %#v, Expected: %#v, Got: %#v", c.points, c.err, err)
+		}
+	}
+}
+
+func TestBuffer_DrawPolygon(t *testing.T) {
+	// A pentagram, whose center is inside according to the non-zero rule,
+	// but outside according to the even-odd rule
+	pentagram := []canvas.Point{{X: 4, Y: 0}, {X: 7, Y: 8}, {X: 0, Y: 3}, {X: 8, Y: 3}, {X: 1, Y: 8}}
+
+	// Positive Cases
+	casesPos := []struct {
+		points []canvas.Point
+		filled bool
+		rule   canvas.FillRule
+		pixels []bytecolor.Color
+	}{
+		{[]canvas.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 0, Y: 4}}, false, canvas.FillRuleEvenOdd, []bytecolor.Color{
+			'x', 'x', 'x', 'x', 'x', ' ', ' ', ' ', ' ',
+			'x', ' ', ' ', 'x', ' ', ' ', ' ', ' ', ' ',
+			'x', ' ', 'x', ' ', ' ', ' ', ' ', ' ', ' ',
+			'x', 'x', ' ', ' ', ' ', ' ', ' ', ' ', ' ',
+			'x', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ',
+			' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ',
+			' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ',
+			' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ',
+			' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ',
+		}},
+		{[]canvas.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 0, Y: 4}}, true, canvas.FillRuleEvenOdd, []bytecolor.Color{
+			'x', 'x', 'x', 'x', 'x', ' ', ' ', ' ', ' ',
+			'x', 'x', 'x', 'x', ' ', ' ', ' ', ' ', ' ',
+			'x', 'x', 'x', ' ', ' ', ' ', ' ', ' ', ' ',
+			'x', 'x', ' ', ' ', ' ', ' ', ' ', ' ', ' ',
+			'x', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ',
+			' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ',
+			' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ',
+			' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ',
+			' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ',
+		}},
+		{pentagram, true, canvas.FillRuleEvenOdd, nil},
+		{pentagram, true, canvas.FillRuleNonZero, nil},
+	}
+	for i, c := range casesPos {
+		cnv, err := NewBuffer(9, 9, bytecolor.Color(' '), bytecolor.Color('x'))
+		if err != nil {
+			panic(err)
+		}
+		err = cnv.DrawPolygon(c.points, c.filled, c.rule)
+		if err != nil {
+			t.Errorf("Case #%d: Expected: err == nil, Got: %#v", i, err)
+		}
+		if c.pixels != nil && !reflect.DeepEqual(cnv.Pixels(), c.pixels) {
+			t.Errorf("Case #%d: Expected: %#v, Got: %#v", i, c.pixels, cnv.Pixels())
+		}
+		// The center of the pentagram
+		if c.pixels == nil {
+			center := cnv.at(4, 4)
+			expected := bytecolor.Color(' ')
+			if c.rule == canvas.FillRuleNonZero {
+				expected = bytecolor.Color('x')
+			}
+			if center != expected {
+				t.Errorf("Case #%d: Expected: %#v, Got: %#v", i, expected, center)
+			}
+		}
+	}
+
+	// Negative Cases
+	casesNeg := []struct {
+		points []canvas.Point
+		rule   canvas.FillRule
+		err    error
+	}{
+		{[]canvas.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}, canvas.FillRuleEvenOdd, common.ErrTooFewPoints},
+		{[]canvas.Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 9, Y: 0}}, canvas.FillRuleEvenOdd, common.ErrPointOutsideCanvas},
+		{[]canvas.Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}}, canvas.FillRule(2), common.ErrFillRuleNotSupported},
+	}
+	for _, c := range casesNeg {
+		cnv, err := NewBuffer(9, 9, bytecolor.Color(' '), bytecolor.Color('x'))
+		if err != nil {
+			panic(err)
+		}
+		err = cnv.DrawPolygon(c.points, true, c.rule)
+		if err != c.err {
+			t.Errorf("Case: %#v, Expected: %#v, Got: %#v", c.points, c.err, err)
+		}
+	}
+}
+
+func TestBuffer_SetTextMode(t *testing.T) {
+	cnv, err := NewBuffer(2, 3, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+
+	cases := []struct {
+		mode canvas.TextMode
+		err  error
+	}{
+		{canvas.TextModeCharacter, nil},
+		{canvas.TextModeBitmap, nil},
+		{canvas.TextMode(-1), common.ErrTextModeNotSupported},
+		{canvas.TextMode(2), common.ErrTextModeNotSupported},
+	}
+	for _, c := range cases {
+		err = cnv.SetTextMode(c.mode)
+		if err != c.err {
+			t.Errorf("Case: %d, Expected: %#v, Got: %#v", c.mode, c.err, err)
+		}
+	}
+}
+
+func TestBuffer_DrawText(t *testing.T) {
+	// Positive Cases
+	casesPos := []struct {
+		w      int
+		h      int
+		mode   canvas.TextMode
+		x      int
+		y      int
+		text   string
+		pixels []bytecolor.Color
+	}{
+		{6, 2, canvas.TextModeCharacter, 1, 1, "Hi!", []bytecolor.Color{
+			' ', ' ', ' ', ' ', ' ', ' ',
+			' ', 'H', 'i', '!', ' ', ' ',
+		}},
+		{6, 2, canvas.TextModeCharacter, 0, 0, "caf\u00e9\t", []bytecolor.Color{
+			'c', 'a', 'f', '?', '?', ' ',
+			' ', ' ', ' ', ' ', ' ', ' ',
+		}},
+		{6, 2, canvas.TextModeCharacter, 5, 1, "", []bytecolor.Color{
+			' ', ' ', ' ', ' ', ' ', ' ',
+			' ', ' ', ' ', ' ', ' ', ' ',
+		}},
+		{6, 7, canvas.TextModeBitmap, 1, 0, "T", []bytecolor.Color{
+			' ', 'x', 'x', 'x', 'x', 'x',
+			' ', ' ', ' ', 'x', ' ', ' ',
+			' ', ' ', ' ', 'x', ' ', ' ',
+			' ', ' ', ' ', 'x', ' ', ' ',
+			' ', ' ', ' ', 'x', ' ', ' ',
+			' ', ' ', ' ', 'x', ' ', ' ',
+			' ', ' ', ' ', 'x', ' ', ' ',
+		}},
+	}
+	for _, c := range casesPos {
+		cnv, err := NewBuffer(c.w, c.h, bytecolor.Color(' '), bytecolor.Color('x'))
+		if err != nil {
+			panic(err)
+		}
+		err = cnv.SetTextMode(c.mode)
+		if err != nil {
+			panic(err)
+		}
+		err = cnv.DrawText(c.x, c.y, c.text)
+		if err != nil {
+			t.Errorf("Case: (%d, %d, %d, %d, %d, %q), Expected: err == nil, Got: %#v", c.w, c.h, c.mode, c.x, c.y, c.text, err)
+		}
+		if !reflect.DeepEqual(cnv.Pixels(), c.pixels) {
+			t.Errorf("Case: (%d, %d, %d, %d, %d, %q), Expected: %#v, Got: %#v", c.w, c.h, c.mode, c.x, c.y, c.text, c.pixels, cnv.Pixels())
+		}
+	}
+
+	// Negative Cases
+	casesNeg := []struct {
+		w    int
+		h    int
+		mode canvas.TextMode
+		x    int
+		y    int
+		text string
+		err  error
+	}{
+		{6, 2, canvas.TextModeCharacter, -1, 0, "Hi", common.ErrPointOutsideCanvas},
+		{6, 2, canvas.TextModeCharacter, 0, 2, "Hi", common.ErrPointOutsideCanvas},
+		{6, 2, canvas.TextModeCharacter, 5, 0, "Hi", common.ErrPointOutsideCanvas},
+		{6, 7, canvas.TextModeBitmap, 1, 0, "Hi", common.ErrPointOutsideCanvas},
+		{6, 7, canvas.TextModeBitmap, 0, 1, "H", common.ErrPointOutsideCanvas},
+	}
+	for _, c := range casesNeg {
+		cnv, err := NewBuffer(c.w, c.h, bytecolor.Color(' '), bytecolor.Color('x'))
+		if err != nil {
+			panic(err)
+		}
+		err = cnv.SetTextMode(c.mode)
+		if err != nil {
+			panic(err)
+		}
+		err = cnv.DrawText(c.x, c.y, c.text)
+		if err != c.err {
+			t.Errorf("Case: (%d, %d, %d, %d, %d, %q), Expected: %#v, Got: %#v", c.w, c.h, c.mode, c.x, c.y, c.text, c.err, err)
+		}
+	}
+}
+
 func TestBuffer_BucketFill(t *testing.T) {
 	cnv, err := NewBuffer(20, 4, bytecolor.Color(' '), bytecolor.Color('x'))
 	if err != nil {
@@ -476,3 +1152,258 @@ func TestBuffer_BucketFill(t *testing.T) {
 		}
 	}
 }
+
+func TestBuffer_FloodFill(t *testing.T) {
+	grid := []string{
+		" x  x",
+		"x  x ",
+		"  x  ",
+	}
+
+	// Positive Cases
+	casesPos := []struct {
+		x    int
+		y    int
+		opts canvas.FillOptions
+		rows []string
+	}{
+		{0, 0, canvas.FillOptions{}, []string{"ox  x", "x  x ", "  x  "}},
+		{2, 0, canvas.FillOptions{}, []string{" xoox", "xoox ", "oox  "}},
+		{2, 0, canvas.FillOptions{Diagonal: true}, []string{"oxoox", "xooxo", "ooxoo"}},
+		{0, 0, canvas.FillOptions{Diagonal: true}, []string{"oxoox", "xooxo", "ooxoo"}},
+		{1, 0, canvas.FillOptions{Global: true}, []string{" o  o", "o  o ", "  o  "}},
+		{4, 2, canvas.FillOptions{Global: true, Diagonal: true}, []string{"oxoox", "xooxo", "ooxoo"}},
+	}
+	for _, c := range casesPos {
+		cnv := newRowsBuffer(grid)
+		err := cnv.FloodFill(c.x, c.y, bytecolor.Color('o'), c.opts)
+		if err != nil {
+			t.Errorf("Case: (%d, %d, %#v), Expected: err == nil, Got: %#v", c.x, c.y, c.opts, err)
+		}
+		if !reflect.DeepEqual(rows(cnv), c.rows) {
+			t.Errorf("Case: (%d, %d, %#v), Expected: %#v, Got: %#v", c.x, c.y, c.opts, c.rows, rows(cnv))
+		}
+	}
+
+	// Negative Cases
+	casesNeg := []struct {
+		x    int
+		y    int
+		c    color.Color
+		opts canvas.FillOptions
+		err  error
+	}{
+		{5, 0, bytecolor.Color('o'), canvas.FillOptions{Global: true}, common.ErrPointOutsideCanvas},
+		{0, 0, dummyColor('o'), canvas.FillOptions{Diagonal: true}, common.ErrColorTypeNotSupported},
+		{0, 0, bytecolor.Color('o'), canvas.FillOptions{Tolerance: -1}, common.ErrToleranceNegative},
+		{0, 0, bytecolor.Color('o'), canvas.FillOptions{Tolerance: 1}, common.ErrToleranceNotSupported},
+	}
+	for _, c := range casesNeg {
+		cnv := newRowsBuffer(grid)
+		err := cnv.FloodFill(c.x, c.y, c.c, c.opts)
+		if err != c.err {
+			t.Errorf("Case: (%d, %d, %#v, %#v), Expected: %#v, Got: %#v", c.x, c.y, c.c, c.opts, c.err, err)
+		}
+		if !reflect.DeepEqual(rows(cnv), grid) {
+			t.Errorf("Case: (%d, %d, %#v, %#v), Expected: %#v, Got: %#v", c.x, c.y, c.c, c.opts, grid, rows(cnv))
+		}
+	}
+}
+
+func TestBuffer_Crop(t *testing.T) {
+	grid := []string{"abcd", "efgh", "ijkl"}
+
+	// Positive Cases
+	casesPos := []struct {
+		x1, y1, x2, y2 int
+		rows           []string
+	}{
+		{0, 0, 3, 2, grid},
+		{1, 0, 2, 1, []string{"bc", "fg"}},
+		{3, 2, 2, 0, []string{"cd", "gh", "kl"}},
+		{0, 1, 0, 1, []string{"e"}},
+	}
+	for _, c := range casesPos {
+		cnv := newRowsBuffer(grid)
+		err := cnv.Crop(c.x1, c.y1, c.x2, c.y2)
+		if err != nil {
+			t.Errorf("Case: (%d, %d, %d, %d), Expected: err == nil, Got: %#v", c.x1, c.y1, c.x2, c.y2, err)
+		}
+		if !reflect.DeepEqual(rows(cnv), c.rows) {
+			t.Errorf("Case: (%d, %d, %d, %d), Expected: %#v, Got: %#v", c.x1, c.y1, c.x2, c.y2, c.rows, rows(cnv))
+		}
+	}
+
+	// Negative Cases
+	cnv := newRowsBuffer(grid)
+	for _, p := range [][4]int{{-1, 0, 1, 1}, {0, 0, 4, 1}, {0, 0, 1, 3}} {
+		err := cnv.Crop(p[0], p[1], p[2], p[3])
+		if err != common.ErrPointOutsideCanvas {
+			t.Errorf("Case: %v, Expected: %#v, Got: %#v", p, common.ErrPointOutsideCanvas, err)
+		}
+	}
+	if !reflect.DeepEqual(rows(cnv), grid) {
+		t.Errorf("Expected: %#v, Got: %#v", grid, rows(cnv))
+	}
+}
+
+func TestBuffer_Extend(t *testing.T) {
+	grid := []string{"ab", "cd"}
+
+	// Positive Cases
+	casesPos := []struct {
+		left, top, right, bottom int
+		rows                     []string
+	}{
+		{0, 0, 0, 0, grid},
+		{1, 0, 0, 0, []string{" ab", " cd"}},
+		{0, 1, 2, 0, []string{"    ", "ab  ", "cd  "}},
+		{1, 1, 1, 1, []string{"    ", " ab ", " cd ", "    "}},
+	}
+	for _, c := range casesPos {
+		cnv := newRowsBuffer(grid)
+		err := cnv.Extend(c.left, c.top, c.right, c.bottom)
+		if err != nil {
+			t.Errorf("Case: (%d, %d, %d, %d), Expected: err == nil, Got: %#v", c.left, c.top, c.right, c.bottom, err)
+		}
+		if !reflect.DeepEqual(rows(cnv), c.rows) {
+			t.Errorf("Case: (%d, %d, %d, %d), Expected: %#v, Got: %#v", c.left, c.top, c.right, c.bottom, c.rows, rows(cnv))
+		}
+	}
+
+	// Negative Cases
+	cnv := newRowsBuffer(grid)
+	err := cnv.Extend(1, 1, -1, 1)
+	if err != common.ErrExtentNegative {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrExtentNegative, err)
+	}
+	if !reflect.DeepEqual(rows(cnv), grid) {
+		t.Errorf("Expected: %#v, Got: %#v", grid, rows(cnv))
+	}
+}
+
+func TestBuffer_Scale(t *testing.T) {
+	grid := []string{"abcd", "efgh"}
+
+	// Positive Cases
+	casesPos := []struct {
+		width, height int
+		rows          []string
+	}{
+		{4, 2, grid},
+		{8, 2, []string{"aabbccdd", "eeffgghh"}},
+		{4, 4, []string{"abcd", "abcd", "efgh", "efgh"}},
+		{2, 1, []string{"fh"}},
+		{6, 3, []string{"abbcdd", "effghh", "effghh"}},
+		{1, 1, []string{"g"}},
+	}
+	for _, c := range casesPos {
+		cnv := newRowsBuffer(grid)
+		err := cnv.Scale(c.width, c.height)
+		if err != nil {
+			t.Errorf("Case: (%d, %d), Expected: err == nil, Got: %#v", c.width, c.height, err)
+		}
+		if !reflect.DeepEqual(rows(cnv), c.rows) {
+			t.Errorf("Case: (%d, %d), Expected: %#v, Got: %#v", c.width, c.height, c.rows, rows(cnv))
+		}
+	}
+
+	// Negative Cases
+	cnv := newRowsBuffer(grid)
+	for _, size := range [][2]int{{0, 1}, {1, 0}, {-1, -1}} {
+		err := cnv.Scale(size[0], size[1])
+		if err != common.ErrWidthOrHeightNotPositive {
+			t.Errorf("Case: %v, Expected: %#v, Got: %#v", size, common.ErrWidthOrHeightNotPositive, err)
+		}
+	}
+	if !reflect.DeepEqual(rows(cnv), grid) {
+		t.Errorf("Expected: %#v, Got: %#v", grid, rows(cnv))
+	}
+}
+
+func TestBuffer_Transform(t *testing.T) {
+	grid := []string{"abc", "def"}
+
+	// Positive Cases
+	casesPos := []struct {
+		t    canvas.Transform
+		rows []string
+	}{
+		{canvas.TransformFlipHorizontal, []string{"cba", "fed"}},
+		{canvas.TransformFlipVertical, []string{"def", "abc"}},
+		{canvas.TransformRotate90, []string{"da", "eb", "fc"}},
+		{canvas.TransformRotate180, []string{"fed", "cba"}},
+		{canvas.TransformRotate270, []string{"cf", "be", "ad"}},
+	}
+	for _, c := range casesPos {
+		cnv := newRowsBuffer(grid)
+		err := cnv.Transform(c.t)
+		if err != nil {
+			t.Errorf("Case: %d, Expected: err == nil, Got: %#v", c.t, err)
+		}
+		if !reflect.DeepEqual(rows(cnv), c.rows) {
+			t.Errorf("Case: %d, Expected: %#v, Got: %#v", c.t, c.rows, rows(cnv))
+		}
+	}
+
+	// Negative Cases
+	cnv := newRowsBuffer(grid)
+	err := cnv.Transform(canvas.Transform(-1))
+	if err != common.ErrTransformNotSupported {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrTransformNotSupported, err)
+	}
+	if !reflect.DeepEqual(rows(cnv), grid) {
+		t.Errorf("Expected: %#v, Got: %#v", grid, rows(cnv))
+	}
+}
+
+func TestBuffer_TransformRect(t *testing.T) {
+	grid := []string{"abcd", "efgh", "ijkl"}
+
+	// Positive Cases
+	casesPos := []struct {
+		x1, y1, x2, y2 int
+		t              canvas.Transform
+		rows           []string
+	}{
+		{1, 0, 2, 1, canvas.TransformFlipHorizontal, []string{"acbd", "egfh", "ijkl"}},
+		{3, 1, 0, 0, canvas.TransformFlipVertical, []string{"efgh", "abcd", "ijkl"}},
+		{0, 0, 2, 1, canvas.TransformRotate90, []string{"ea d", "fb h", "gckl"}},
+		{1, 1, 2, 2, canvas.TransformRotate180, []string{"abcd", "ekjh", "igfl"}},
+		{0, 1, 1, 2, canvas.TransformRotate270, []string{"abcd", "fjgh", "eikl"}},
+		{3, 0, 3, 0, canvas.TransformRotate90, grid},
+	}
+	for _, c := range casesPos {
+		cnv := newRowsBuffer(grid)
+		err := cnv.TransformRect(c.x1, c.y1, c.x2, c.y2, c.t)
+		if err != nil {
+			t.Errorf("Case: (%d, %d, %d, %d, %d), Expected: err == nil, Got: %#v", c.x1, c.y1, c.x2, c.y2, c.t, err)
+		}
+		if !reflect.DeepEqual(rows(cnv), c.rows) {
+			t.Errorf("Case: (%d, %d, %d, %d, %d), Expected: %#v, Got: %#v", c.x1, c.y1, c.x2, c.y2, c.t, c.rows, rows(cnv))
+		}
+	}
+
+	// Negative Cases
+	casesNeg := []struct {
+		x1, y1, x2, y2 int
+		t              canvas.Transform
+		err            error
+	}{
+		{-1, 0, 1, 1, canvas.TransformFlipHorizontal, common.ErrPointOutsideCanvas},
+		{0, 0, 1, 3, canvas.TransformFlipVertical, common.ErrPointOutsideCanvas},
+		{3, 0, 3, 2, canvas.TransformRotate270, common.ErrPointOutsideCanvas},
+		{1, 2, 3, 2, canvas.TransformRotate90, common.ErrPointOutsideCanvas},
+		{0, 0, 1, 1, canvas.Transform(-1), common.ErrTransformNotSupported},
+	}
+	for _, c := range casesNeg {
+		cnv := newRowsBuffer(grid)
+		err := cnv.TransformRect(c.x1, c.y1, c.x2, c.y2, c.t)
+		if err != c.err {
+			t.Errorf("Case: (%d, %d, %d, %d, %d), Expected: %#v, Got: %#v", c.x1, c.y1, c.x2, c.y2, c.t, c.err, err)
+		}
+		if !reflect.DeepEqual(rows(cnv), grid) {
+			t.Errorf("Case: (%d, %d, %d, %d, %d), Expected: %#v, Got: %#v", c.x1, c.y1, c.x2, c.y2, c.t, grid, rows(cnv))
+		}
+	}
+}
@@ -1,13 +1,9 @@
 package bytecolor
 
-import "github.com/asukakenji/drawing-challenge/color/bytecolor"
-
-// point represents a point in the coordinate system.
-// The coordinate system is zero-based.
-type point struct {
-	x int
-	y int
-}
+import (
+	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+)
 
 // xyToIndex translates a 2D coordinate into a 1D index.
 func xyToIndex(width, x, y int) int {
@@ -19,6 +15,13 @@ func isPointInsideCanvas(width, height, x, y int) bool {
 	return 0 <= x && x < width && 0 <= y && y < height
 }
 
+// nearest translates the coordinate i in a row or column of n pixels
+// into that of the nearest pixel in a row or column of m pixels.
+// The centers of the pixels are compared.
+func nearest(i, n, m int) int {
+	return (2*i + 1) * m / (2 * n)
+}
+
 // fill fills b with bc
 // See the bytes.Repeat: https://golang.org/src/bytes/bytes.go
 func fill(b []bytecolor.Color, bc bytecolor.Color) {
@@ -30,35 +33,41 @@ func fill(b []bytecolor.Color, bc bytecolor.Color) {
 	}
 }
 
-// boolBuffer is a helper type for the bucket fill algorithm.
-type boolBuffer struct {
-	width  int
-	height int
-	values []bool
-}
-
-// newBoolBuffer returns a new boolBuffer.
-func newBoolBuffer(width, height int) *boolBuffer {
-	return &boolBuffer{
-		width:  width,
-		height: height,
-		values: make([]bool, width*height),
+// isTransformSupported returns whether t is supported by Buffer.
+func isTransformSupported(t canvas.Transform) bool {
+	switch t {
+	case canvas.TransformFlipHorizontal, canvas.TransformFlipVertical,
+		canvas.TransformRotate90, canvas.TransformRotate180, canvas.TransformRotate270:
+		return true
+	default:
+		return false
 	}
 }
 
-// At returns whether the pixel at (x, y) is processed.
-// It returns true for any point outside the canvas
-// to prevent it from really being processed.
-func (bb *boolBuffer) At(x, y int) bool {
-	index := xyToIndex(bb.width, x, y)
-	if index < 0 || index >= len(bb.values) {
-		return true
+// transformedSize returns the width and height of
+// a width x height rectangle transformed by t.
+func transformedSize(t canvas.Transform, width, height int) (int, int) {
+	if t == canvas.TransformRotate90 || t == canvas.TransformRotate270 {
+		return height, width
 	}
-	return bb.values[index]
+	return width, height
 }
 
-// Set sets the pixel at (x, y) as already processed.
-func (bb *boolBuffer) Set(x, y int) {
-	index := xyToIndex(bb.width, x, y)
-	bb.values[index] = true
+// sourcePoint returns the point in a width x height rectangle,
+// which is moved to (x, y) when the rectangle is transformed by t.
+func sourcePoint(t canvas.Transform, width, height, x, y int) (int, int) {
+	switch t {
+	case canvas.TransformFlipHorizontal:
+		return width - 1 - x, y
+	case canvas.TransformFlipVertical:
+		return x, height - 1 - y
+	case canvas.TransformRotate90:
+		return y, height - 1 - x
+	case canvas.TransformRotate180:
+		return width - 1 - x, height - 1 - y
+	case canvas.TransformRotate270:
+		return width - 1 - y, x
+	default:
+		return x, y
+	}
 }
@@ -1,16 +1,131 @@
-// Package canvas defines the Canvas interface and
-// the BufferBasedCanvas interface.
+// Package canvas defines the Canvas interface,
+// the BufferBasedCanvas interface, the ResizableCanvas interface,
+// the TransformableCanvas interface, and the types used by their methods.
 package canvas
 
 import "github.com/asukakenji/drawing-challenge/color"
 
+// LineMode specifies the kinds of lines accepted by Canvas.DrawLine.
+type LineMode int
+
+const (
+	// LineModeHorizontalOrVertical accepts horizontal and vertical lines only.
+	// This is the original behavior of the project.
+	LineModeHorizontalOrVertical LineMode = iota
+
+	// LineModeAnyAngle accepts lines of any angle.
+	// Lines which are neither horizontal nor vertical are rasterized
+	// using Bresenham's line algorithm.
+	LineModeAnyAngle
+)
+
+// TextMode specifies how Canvas.DrawText renders text.
+type TextMode int
+
+const (
+	// TextModeCharacter renders each character of the text as one pixel,
+	// whose color is the character itself.
+	// It is suitable for canvases whose colors are characters.
+	TextModeCharacter TextMode = iota
+
+	// TextModeBitmap renders each character of the text as a 5x7 glyph
+	// of the built-in bitmap font, using the foreground color.
+	TextModeBitmap
+)
+
+// Point represents a point in the coordinate system.
+type Point struct {
+	X int
+	Y int
+}
+
+// FillRule specifies how the interior of a polygon is determined.
+type FillRule int
+
+const (
+	// FillRuleEvenOdd paints a pixel if a ray from it crosses
+	// the edges of the polygon an odd number of times.
+	FillRuleEvenOdd FillRule = iota
+
+	// FillRuleNonZero paints a pixel if the winding number
+	// of the polygon around it is not zero.
+	FillRuleNonZero
+)
+
+// Transform specifies how TransformableCanvas moves the pixels.
+// The rotations are clockwise, as seen on the screen.
+type Transform int
+
+const (
+	// TransformFlipHorizontal swaps the left and right.
+	TransformFlipHorizontal Transform = iota
+
+	// TransformFlipVertical swaps the top and bottom.
+	TransformFlipVertical
+
+	// TransformRotate90 rotates the pixels by 90 degrees.
+	TransformRotate90
+
+	// TransformRotate180 rotates the pixels by 180 degrees.
+	TransformRotate180
+
+	// TransformRotate270 rotates the pixels by 270 degrees.
+	TransformRotate270
+)
+
+// FillOptions specifies how Canvas.FloodFill determines the area to be filled.
+// The zero value specifies the behavior of Canvas.BucketFill.
+type FillOptions struct {
+	// Diagonal specifies whether the area spreads to the diagonal neighbors
+	// (8-connectivity), in addition to the horizontal and vertical ones
+	// (4-connectivity).
+	Diagonal bool
+
+	// Global specifies whether every pixel in the canvas matching the color
+	// at (x, y) is filled, whether it connects to (x, y) or not.
+	Global bool
+
+	// Tolerance is the maximum difference of each color component
+	// between a matching pixel and the pixel at (x, y).
+	// It is 0 for the exact match.
+	// Only the canvases whose colors have components support a positive tolerance.
+	Tolerance int
+}
+
 // Canvas is a finite rectangular grid which allows several drawing operations.
 // The coordinate system is zero-based.
 type Canvas interface {
 	// Dimensions returns the width and height.
 	Dimensions() (int, int)
 
-	// DrawLine draws a horizontal or vertical line.
+	// Clone returns a deep copy of the canvas,
+	// which is not affected by the subsequent changes of the canvas.
+	Clone() Canvas
+
+	// ForegroundColor returns the foreground color,
+	// which is used to draw lines and shapes.
+	ForegroundColor() color.Color
+
+	// SetForegroundColor sets the foreground color,
+	// which is used to draw lines and shapes.
+	//
+	// Errors
+	//
+	// common.ErrColorTypeNotSupported:
+	// Will be returned if c is not supported by the canvas.
+	//
+	SetForegroundColor(c color.Color) error
+
+	// SetLineMode sets the line mode used by DrawLine.
+	//
+	// Errors
+	//
+	// common.ErrLineModeNotSupported:
+	// Will be returned if mode is not supported by the canvas.
+	//
+	SetLineMode(mode LineMode) error
+
+	// DrawLine draws a line from (x1, y1) to (x2, y2).
 	//
 	// Errors
 	//
@@ -18,7 +133,8 @@ type Canvas interface {
 	// Will be returned if (x1, y1) or (x2, y2) is outside the canvas.
 	//
 	// common.ErrLineNotHorizontalOrVertical:
-	// Will be returned if the line is not horizontal or vertical.
+	// Will be returned if the line mode is LineModeHorizontalOrVertical,
+	// and the line is not horizontal or vertical.
 	//
 	DrawLine(x1, y1, x2, y2 int) error
 
@@ -31,6 +147,95 @@ type Canvas interface {
 	//
 	DrawRect(x1, y1, x2, y2 int) error
 
+	// FillRect draws a rectangle with stroke,
+	// and paints the interior of the rectangle with fill.
+	// The foreground color is used if stroke is nil.
+	//
+	// Errors
+	//
+	// common.ErrPointOutsideCanvas:
+	// Will be returned if (x1, y1) or (x2, y2) is outside the canvas.
+	//
+	// common.ErrColorTypeNotSupported:
+	// Will be returned if stroke or fill is not supported by the canvas.
+	//
+	FillRect(x1, y1, x2, y2 int, stroke, fill color.Color) error
+
+	// DrawCircle draws a circle centered at (xc, yc) with radius r.
+	// The interior of the circle is also painted if filled is true.
+	//
+	// Errors
+	//
+	// common.ErrRadiusNegative:
+	// Will be returned if r < 0.
+	//
+	// common.ErrPointOutsideCanvas:
+	// Will be returned if any part of the circle is outside the canvas.
+	//
+	DrawCircle(xc, yc, r int, filled bool) error
+
+	// DrawEllipse draws an axis-aligned ellipse centered at (xc, yc)
+	// with horizontal radius rx and vertical radius ry.
+	// The interior of the ellipse is also painted if filled is true.
+	//
+	// Errors
+	//
+	// common.ErrRadiusNegative:
+	// Will be returned if rx < 0, or ry < 0.
+	//
+	// common.ErrPointOutsideCanvas:
+	// Will be returned if any part of the ellipse is outside the canvas.
+	//
+	DrawEllipse(xc, yc, rx, ry int, filled bool) error
+
+	// DrawPolyline draws connected line segments through points.
+	// The line mode does not apply to polylines.
+	//
+	// Errors
+	//
+	// common.ErrTooFewPoints:
+	// Will be returned if len(points) < 2.
+	//
+	// common.ErrPointOutsideCanvas:
+	// Will be returned if any of points is outside the canvas.
+	//
+	DrawPolyline(points []Point) error
+
+	// DrawPolygon draws a closed polygon with vertices points.
+	// The interior of the polygon is also painted according to rule
+	// if filled is true. The line mode does not apply to polygons.
+	//
+	// Errors
+	//
+	// common.ErrTooFewPoints:
+	// Will be returned if len(points) < 3.
+	//
+	// common.ErrPointOutsideCanvas:
+	// Will be returned if any of points is outside the canvas.
+	//
+	// common.ErrFillRuleNotSupported:
+	// Will be returned if rule is not supported by the canvas.
+	//
+	DrawPolygon(points []Point, filled bool, rule FillRule) error
+
+	// SetTextMode sets the text mode used by DrawText.
+	//
+	// Errors
+	//
+	// common.ErrTextModeNotSupported:
+	// Will be returned if mode is not supported by the canvas.
+	//
+	SetTextMode(mode TextMode) error
+
+	// DrawText draws text. (x, y) is the top-left corner of the text.
+	//
+	// Errors
+	//
+	// common.ErrPointOutsideCanvas:
+	// Will be returned if any part of the text is outside the canvas.
+	//
+	DrawText(x, y int, text string) error
+
 	// BucketFill fills the area enclosing (x, y). The pixels connecting to
 	// (x, y) having the same color as that at (x, y) are replaced by c.
 	//
@@ -43,6 +248,25 @@ type Canvas interface {
 	// Will be returned if c is not supported by the canvas.
 	//
 	BucketFill(x, y int, c color.Color) error
+
+	// FloodFill fills the area enclosing (x, y) like BucketFill,
+	// but the area is determined according to opts.
+	//
+	// Errors
+	//
+	// common.ErrPointOutsideCanvas:
+	// Will be returned if (x, y) is outside the canvas.
+	//
+	// common.ErrColorTypeNotSupported:
+	// Will be returned if c is not supported by the canvas.
+	//
+	// common.ErrToleranceNegative:
+	// Will be returned if opts.Tolerance < 0.
+	//
+	// common.ErrToleranceNotSupported:
+	// Will be returned if opts.Tolerance > 0, but it is not supported by the canvas.
+	//
+	FloodFill(x, y int, c color.Color, opts FillOptions) error
 }
 
 // BufferBasedCanvas is a Canvas based on a buffer of color.Color.
@@ -71,3 +295,72 @@ type BufferBasedCanvas interface {
 	//
 	Set(x, y int, c color.Color) error
 }
+
+// ResizableCanvas is a Canvas which could be resized, keeping its content.
+type ResizableCanvas interface {
+	// Canvas is a super-interface of ResizableCanvas.
+	Canvas
+
+	// Crop crops the canvas to the rectangle with corners (x1, y1) and (x2, y2).
+	// The pixels on the edges of the rectangle are kept.
+	//
+	// Errors
+	//
+	// common.ErrPointOutsideCanvas:
+	// Will be returned if (x1, y1) or (x2, y2) is outside the canvas.
+	//
+	Crop(x1, y1, x2, y2 int) error
+
+	// Extend extends the canvas by left, top, right, and bottom pixels
+	// on the respective sides. The new pixels are in the background color.
+	//
+	// Errors
+	//
+	// common.ErrExtentNegative:
+	// Will be returned if left < 0, top < 0, right < 0, or bottom < 0.
+	//
+	Extend(left, top, right, bottom int) error
+
+	// Scale scales the canvas to width x height.
+	// Each pixel takes the color of the nearest pixel of the original canvas.
+	//
+	// Errors
+	//
+	// common.ErrWidthOrHeightNotPositive:
+	// Will be returned if width <= 0, or height <= 0.
+	//
+	Scale(width, height int) error
+}
+
+// TransformableCanvas is a Canvas which could be flipped and rotated.
+type TransformableCanvas interface {
+	// Canvas is a super-interface of TransformableCanvas.
+	Canvas
+
+	// Transform flips or rotates the whole canvas.
+	// The width and height are swapped by TransformRotate90
+	// and TransformRotate270.
+	//
+	// Errors
+	//
+	// common.ErrTransformNotSupported:
+	// Will be returned if t is not supported by the canvas.
+	//
+	Transform(t Transform) error
+
+	// TransformRect flips or rotates the rectangle with corners (x1, y1)
+	// and (x2, y2). The top-left corner of the rectangle is kept. If the width
+	// and height are swapped, the pixels of the rectangle not covered by the
+	// transformed one are painted with the background color.
+	//
+	// Errors
+	//
+	// common.ErrPointOutsideCanvas:
+	// Will be returned if (x1, y1) or (x2, y2) is outside the canvas,
+	// or any part of the transformed rectangle is outside the canvas.
+	//
+	// common.ErrTransformNotSupported:
+	// Will be returned if t is not supported by the canvas.
+	//
+	TransformRect(x1, y1, x2, y2 int, t Transform) error
+}
@@ -0,0 +1,70 @@
+package font
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBounds(t *testing.T) {
+	cases := []struct {
+		text   string
+		width  int
+		height int
+	}{
+		{"", 0, 0},
+		{"A", 5, 7},
+		{"AB", 11, 7},
+		{"\u00e9t\u00e9", 17, 7},
+	}
+	for _, c := range cases {
+		width, height := Bounds(c.text)
+		if width != c.width || height != c.height {
+			t.Errorf("Case: %q, Expected: (%d, %d), Got: (%d, %d)", c.text, c.width, c.height, width, height)
+		}
+	}
+}
+
+// render renders text into a slice of strings, one string per row.
+func render(text string) []string {
+	width, height := Bounds(text)
+	rows := make([][]byte, height)
+	for j := range rows {
+		rows[j] = make([]byte, width)
+		for i := range rows[j] {
+			rows[j][i] = ' '
+		}
+	}
+	Draw(0, 0, text, func(x, y int) {
+		rows[y][x] = '#'
+	})
+	result := make([]string, height)
+	for j, row := range rows {
+		result[j] = string(row)
+	}
+	return result
+}
+
+func TestDraw(t *testing.T) {
+	cases := []struct {
+		text string
+		rows []string
+	}{
+		{"Hi", []string{
+			"#   #   #  ",
+			"#   #      ",
+			"#   #  ##  ",
+			"#####   #  ",
+			"#   #   #  ",
+			"#   #   #  ",
+			"#   #  ### ",
+		}},
+		{"\u00e9", render("?")},
+		{"\t", render("?")},
+	}
+	for _, c := range cases {
+		rows := render(c.text)
+		if !reflect.DeepEqual(rows, c.rows) {
+			t.Errorf("Case: %q, Expected: %q, Got: %q", c.text, c.rows, rows)
+		}
+	}
+}
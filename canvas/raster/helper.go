@@ -0,0 +1,35 @@
+package raster
+
+// edgeCrossing is a helper type for the polygon fill algorithm.
+// It represents the crossing of a row and an edge of the polygon.
+type edgeCrossing struct {
+	x       float64
+	winding int
+}
+
+// edgeCrossingsByX implements sort.Interface to sort edge crossings by x.
+type edgeCrossingsByX []edgeCrossing
+
+func (ecs edgeCrossingsByX) Len() int           { return len(ecs) }
+func (ecs edgeCrossingsByX) Less(i, j int) bool { return ecs[i].x < ecs[j].x }
+func (ecs edgeCrossingsByX) Swap(i, j int)      { ecs[i], ecs[j] = ecs[j], ecs[i] }
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// sign returns -1, 0, or +1 depending on the sign of n.
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
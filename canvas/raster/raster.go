@@ -0,0 +1,236 @@
+// Package raster defines the rasterization algorithms,
+// which are used by canvases to draw lines and shapes.
+//
+// The algorithms do not access the pixels directly.
+// Instead, they call the given plot function for each pixel to be painted.
+// No boundary checks are performed.
+package raster
+
+import (
+	"math"
+	"sort"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+)
+
+// Line calls plot for each pixel of the line from (x1, y1) to (x2, y2).
+//
+// The line is rasterized using Bresenham's line algorithm.
+// See https://en.wikipedia.org/wiki/Bresenham%27s_line_algorithm
+func Line(x1, y1, x2, y2 int, plot func(x, y int)) {
+	dx, sx := abs(x2-x1), sign(x2-x1)
+	dy, sy := -abs(y2-y1), sign(y2-y1)
+	e := dx + dy
+	for {
+		plot(x1, y1)
+		if x1 == x2 && y1 == y2 {
+			break
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x1 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y1 += sy
+		}
+	}
+}
+
+// Ellipse calls plot for each pixel of the axis-aligned ellipse centered at
+// (xc, yc) with horizontal radius rx and vertical radius ry.
+// The pixels of the interior are also plotted if filled is true.
+// rx and ry must not be negative.
+//
+// The algorithm is described in "A Fast Bresenham Type Algorithm For Drawing
+// Ellipses" by John Kennedy. Only integer arithmetic is involved.
+func Ellipse(xc, yc, rx, ry int, filled bool, plot func(x, y int)) {
+	// The algorithm does not terminate for degenerate ellipses
+	if rx == 0 || ry == 0 {
+		Line(xc-rx, yc-ry, xc+rx, yc+ry, plot)
+		return
+	}
+	plot4 := func(x, y int) {
+		if filled {
+			Line(xc-x, yc+y, xc+x, yc+y, plot)
+			Line(xc-x, yc-y, xc+x, yc-y, plot)
+			return
+		}
+		plot(xc+x, yc+y)
+		plot(xc-x, yc+y)
+		plot(xc-x, yc-y)
+		plot(xc+x, yc-y)
+	}
+	twoASquare := 2 * rx * rx
+	twoBSquare := 2 * ry * ry
+
+	// 1st set of points, where the slope of the tangent is less than -1
+	x, y := rx, 0
+	xChange, yChange := ry*ry*(1-2*rx), rx*rx
+	e := 0
+	stoppingX, stoppingY := twoBSquare*rx, 0
+	for stoppingX >= stoppingY {
+		plot4(x, y)
+		y++
+		stoppingY += twoASquare
+		e += yChange
+		yChange += twoASquare
+		if 2*e+xChange > 0 {
+			x--
+			stoppingX -= twoBSquare
+			e += xChange
+			xChange += twoBSquare
+		}
+	}
+
+	// 2nd set of points, where the slope of the tangent is greater than -1
+	x, y = 0, ry
+	xChange, yChange = ry*ry, rx*rx*(1-2*ry)
+	e = 0
+	stoppingX, stoppingY = 0, twoASquare*ry
+	for stoppingX <= stoppingY {
+		plot4(x, y)
+		x++
+		stoppingX += twoBSquare
+		e += xChange
+		xChange += twoBSquare
+		if 2*e+yChange > 0 {
+			y--
+			stoppingY -= twoASquare
+			e += yChange
+			yChange += twoASquare
+		}
+	}
+}
+
+// FillPolygon calls plot for each pixel of the interior of the polygon with
+// vertices points, which is determined according to rule.
+// points must not be empty.
+//
+// The interior is rasterized using the scanline algorithm. For each row, the
+// crossings of the row and the edges are sorted from left to right. The spans
+// between adjacent crossings are then plotted according to rule. An edge covers
+// the rows in [min(y1, y2), max(y1, y2)), so that a vertex shared by two edges
+// is not counted twice.
+func FillPolygon(points []canvas.Point, rule canvas.FillRule, plot func(x, y int)) {
+	minY, maxY := points[0].Y, points[0].Y
+	for _, p := range points[1:] {
+		if p.Y < minY {
+			minY = p.Y
+		}
+		if p.Y > maxY {
+			maxY = p.Y
+		}
+	}
+	crossings := make([]edgeCrossing, 0, len(points))
+	for y := minY; y <= maxY; y++ {
+		crossings = crossings[:0]
+		for i, p1 := range points {
+			p2 := points[(i+1)%len(points)]
+			var winding int
+			switch {
+			case p1.Y <= y && y < p2.Y:
+				winding = 1
+			case p2.Y <= y && y < p1.Y:
+				winding = -1
+			default:
+				continue
+			}
+			x := float64(p1.X) + float64(y-p1.Y)*float64(p2.X-p1.X)/float64(p2.Y-p1.Y)
+			crossings = append(crossings, edgeCrossing{x, winding})
+		}
+		sort.Sort(edgeCrossingsByX(crossings))
+		w := 0
+		for i := 0; i+1 < len(crossings); i++ {
+			w += crossings[i].winding
+			if (rule == canvas.FillRuleEvenOdd && i%2 == 0) || (rule == canvas.FillRuleNonZero && w != 0) {
+				x1 := int(math.Ceil(crossings[i].x))
+				x2 := int(math.Floor(crossings[i+1].x))
+				for x := x1; x <= x2; x++ {
+					plot(x, y)
+				}
+			}
+		}
+	}
+}
+
+// fillSpan is a span of a row waiting to be scanned by FloodFill.
+// The pixels in [x1, x2] of the row y-dy are already filled.
+type fillSpan struct {
+	x1 int
+	x2 int
+	y  int
+	dy int
+}
+
+// FloodFill calls fill for each span of the region connecting to (x, y),
+// which consists of the pixels for which inside returns true.
+// The pixels connect through the diagonal neighbors if diagonal is true,
+// or through the horizontal and vertical ones only otherwise. fill is called
+// with the leftmost and rightmost pixels of the span, and the row of it.
+// inside must return false for the pixels already filled,
+// otherwise FloodFill never returns.
+//
+// Unlike the other algorithms, the region is bounded by the canvas,
+// whose size is given by width and height. inside and fill are never
+// called for the pixels outside the canvas.
+//
+// The region is rasterized using the span filling algorithm described in
+// "A Seed Fill Algorithm" by Paul Heckbert. Each span is scanned once
+// from the row above or below it, and only the spans waiting to be scanned
+// are stored, so that there is no allocation for each pixel.
+// See https://en.wikipedia.org/wiki/Flood_fill#Span_filling
+//
+// For the diagonal neighbors, each span is scanned with one more pixel
+// on both sides, which are the pixels diagonally adjacent to it.
+// The spans to be scanned back are still determined by the span
+// before the widening, so that they are widened in the same way.
+func FloodFill(width, height, x, y int, diagonal bool, inside func(x, y int) bool, fill func(x1, x2, y int)) {
+	isInside := func(x, y int) bool {
+		return 0 <= x && x < width && inside(x, y)
+	}
+	if y < 0 || y >= height || !isInside(x, y) {
+		return
+	}
+	stack := []fillSpan{{x, x, y, 1}, {x, x, y - 1, -1}}
+	for len(stack) != 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		x1, x2, y, dy := s.x1, s.x2, s.y, s.dy
+		if y < 0 || y >= height {
+			continue
+		}
+		if diagonal {
+			x1, x2 = x1-1, x2+1
+		}
+		// Extend the first span to the left
+		x := x1
+		if isInside(x, y) {
+			for isInside(x-1, y) {
+				x--
+			}
+			if x < s.x1 {
+				stack = append(stack, fillSpan{x, s.x1 - 1, y - dy, -dy})
+			}
+		}
+		// Fill the spans overlapping [x1, x2]
+		for x1 <= x2 {
+			for isInside(x1, y) {
+				x1++
+			}
+			if x1 > x {
+				fill(x, x1-1, y)
+				stack = append(stack, fillSpan{x, x1 - 1, y + dy, dy})
+			}
+			if x1-1 > s.x2 {
+				stack = append(stack, fillSpan{s.x2 + 1, x1 - 1, y - dy, -dy})
+			}
+			x1++
+			for x1 < x2 && !isInside(x1, y) {
+				x1++
+			}
+			x = x1
+		}
+	}
+}
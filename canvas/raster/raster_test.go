@@ -0,0 +1,162 @@
+package raster
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+)
+
+// render calls draw with a plot function painting a width x height grid,
+// and returns the grid as a slice of strings, one string per row.
+func render(width, height int, draw func(plot func(x, y int))) []string {
+	rows := make([][]byte, height)
+	for j := range rows {
+		rows[j] = make([]byte, width)
+		for i := range rows[j] {
+			rows[j][i] = '.'
+		}
+	}
+	draw(func(x, y int) {
+		rows[y][x] = '#'
+	})
+	result := make([]string, height)
+	for j, row := range rows {
+		result[j] = string(row)
+	}
+	return result
+}
+
+func TestLine(t *testing.T) {
+	cases := []struct {
+		x1, y1, x2, y2 int
+		rows           []string
+	}{
+		{0, 1, 3, 1, []string{"....", "####", "...."}},
+		{3, 1, 0, 1, []string{"....", "####", "...."}},
+		{2, 0, 2, 2, []string{"..#.", "..#.", "..#."}},
+		{0, 0, 2, 2, []string{"#...", ".#..", "..#."}},
+		{3, 0, 0, 2, []string{"...#", ".##.", "#..."}},
+		{1, 1, 1, 1, []string{"....", ".#..", "...."}},
+	}
+	for _, c := range cases {
+		rows := render(4, 3, func(plot func(x, y int)) {
+			Line(c.x1, c.y1, c.x2, c.y2, plot)
+		})
+		if !reflect.DeepEqual(rows, c.rows) {
+			t.Errorf("Case: (%d, %d, %d, %d), Expected: %#v, Got: %#v", c.x1, c.y1, c.x2, c.y2, c.rows, rows)
+		}
+	}
+}
+
+func TestEllipse(t *testing.T) {
+	cases := []struct {
+		xc, yc, rx, ry int
+		filled         bool
+		rows           []string
+	}{
+		{2, 2, 2, 2, false, []string{".###.", "#...#", "#...#", "#...#", ".###."}},
+		{2, 2, 2, 2, true, []string{".###.", "#####", "#####", "#####", ".###."}},
+		{2, 2, 2, 1, false, []string{".....", ".###.", "#...#", ".###.", "....."}},
+		{2, 2, 2, 0, true, []string{".....", ".....", "#####", ".....", "....."}},
+		{2, 2, 0, 0, false, []string{".....", ".....", "..#..", ".....", "....."}},
+	}
+	for _, c := range cases {
+		rows := render(5, 5, func(plot func(x, y int)) {
+			Ellipse(c.xc, c.yc, c.rx, c.ry, c.filled, plot)
+		})
+		if !reflect.DeepEqual(rows, c.rows) {
+			t.Errorf("Case: (%d, %d, %d, %d, %t), Expected: %#v, Got: %#v", c.xc, c.yc, c.rx, c.ry, c.filled, c.rows, rows)
+		}
+	}
+}
+
+func TestFillPolygon(t *testing.T) {
+	// A pentagram, whose center is inside by the non-zero rule only
+	pentagram := []canvas.Point{{X: 3, Y: 0}, {X: 5, Y: 6}, {X: 0, Y: 2}, {X: 6, Y: 2}, {X: 1, Y: 6}}
+	cases := []struct {
+		points []canvas.Point
+		rule   canvas.FillRule
+		rows   []string
+	}{
+		{
+			[]canvas.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 2}, {X: 0, Y: 2}},
+			canvas.FillRuleEvenOdd,
+			[]string{"#####..", "#####..", ".......", ".......", ".......", ".......", "......."},
+		},
+		{
+			pentagram,
+			canvas.FillRuleEvenOdd,
+			[]string{"...#...", "...#...", "###.###", "..#.#..", "..#.#..", "..#.#..", "......."},
+		},
+		{
+			pentagram,
+			canvas.FillRuleNonZero,
+			[]string{"...#...", "...#...", "#######", "..###..", "..###..", "..#.#..", "......."},
+		},
+	}
+	for _, c := range cases {
+		rows := render(7, 7, func(plot func(x, y int)) {
+			FillPolygon(c.points, c.rule, plot)
+		})
+		if !reflect.DeepEqual(rows, c.rows) {
+			t.Errorf("Case: (%v, %d), Expected: %#v, Got: %#v", c.points, c.rule, c.rows, rows)
+		}
+	}
+}
+
+func TestFloodFill(t *testing.T) {
+	cases := []struct {
+		grid     []string
+		x, y     int
+		diagonal bool
+		rows     []string
+	}{
+		{[]string{"....", "....", "...."}, 1, 1, false, []string{"####", "####", "####"}},
+		{[]string{"..o.", "ooo.", "...."}, 0, 0, false, []string{"##o.", "ooo.", "...."}},
+		{[]string{"..o.", "ooo.", "...."}, 3, 0, false, []string{"..o#", "ooo#", "####"}},
+		{[]string{".o.o.", ".o.o.", "...o."}, 4, 0, false, []string{".o.o#", ".o.o#", "...o#"}},
+		{[]string{".o...", ".o.o.", "...o."}, 4, 2, false, []string{"#o###", "#o#o#", "###o#"}},
+		{[]string{"o.o", ".o.", "o.o"}, 1, 0, false, []string{"o#o", ".o.", "o.o"}},
+		{[]string{"....", ".oo.", "...."}, 1, 1, false, []string{"....", ".oo.", "...."}},
+		{[]string{"o.o", ".o.", "o.o"}, 1, 0, true, []string{"o#o", "#o#", "o#o"}},
+		{[]string{".o..", "o.o.", ".o.o", "..o."}, 1, 1, true, []string{"#o##", "o#o#", "#o#o", "##o#"}},
+		{[]string{"..o.", "ooo.", "...."}, 0, 0, true, []string{"##o.", "ooo.", "...."}},
+		{[]string{".o..o", "o..o.", "..o.."}, 2, 0, true, []string{"#o##o", "o##o#", "##o##"}},
+	}
+	for _, c := range cases {
+		width, height := len(c.grid[0]), len(c.grid)
+		rows := render(width, height, func(plot func(x, y int)) {
+			filled := make(map[[2]int]bool)
+			inside := func(x, y int) bool {
+				if x < 0 || x >= width || y < 0 || y >= height {
+					t.Errorf("Case: %v, Expected: (%d, %d) inside the canvas", c.grid, x, y)
+					return false
+				}
+				return c.grid[y][x] == '.' && !filled[[2]int{x, y}]
+			}
+			FloodFill(width, height, c.x, c.y, c.diagonal, inside, func(x1, x2, y int) {
+				for x := x1; x <= x2; x++ {
+					if !inside(x, y) {
+						t.Errorf("Case: %v, Expected: (%d, %d) not filled twice", c.grid, x, y)
+					}
+					filled[[2]int{x, y}] = true
+					plot(x, y)
+				}
+			})
+		})
+		// The walls are copied for comparison
+		for j, row := range c.grid {
+			b := []byte(rows[j])
+			for i := range row {
+				if row[i] == 'o' {
+					b[i] = 'o'
+				}
+			}
+			rows[j] = string(b)
+		}
+		if !reflect.DeepEqual(rows, c.rows) {
+			t.Errorf("Case: %v (%d, %d, %t), Expected: %#v, Got: %#v", c.grid, c.x, c.y, c.diagonal, c.rows, rows)
+		}
+	}
+}
@@ -0,0 +1,574 @@
+// Package rgba defines the Buffer type,
+// which implements the canvas.BufferBasedCanvas interface
+// and the canvas.ResizableCanvas interface.
+package rgba
+
+import (
+	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/canvas/font"
+	"github.com/asukakenji/drawing-challenge/canvas/raster"
+	"github.com/asukakenji/drawing-challenge/color"
+	"github.com/asukakenji/drawing-challenge/color/rgba"
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+// Buffer is a canvas based on a buffer of rgba.Color.
+// It implements the canvas.BufferBasedCanvas interface
+// and the canvas.ResizableCanvas interface.
+type Buffer struct {
+	width           int
+	height          int
+	backgroundColor rgba.Color
+	foregroundColor rgba.Color
+	lineMode        canvas.LineMode
+	pixels          []rgba.Color
+}
+
+// Ensure that Buffer implements the canvas.BufferBasedCanvas interface
+// and the canvas.ResizableCanvas interface.
+var (
+	_ canvas.BufferBasedCanvas = &Buffer{}
+	_ canvas.ResizableCanvas   = &Buffer{}
+)
+
+// NewBuffer returns a new Buffer.
+// The line mode of the new Buffer is canvas.LineModeHorizontalOrVertical.
+// The text mode is always canvas.TextModeBitmap.
+//
+// Errors
+//
+// common.ErrWidthOrHeightNotPositive:
+// Will be returned if width <= 0, or height <= 0.
+//
+func NewBuffer(width, height int, bgColor, fgColor rgba.Color) (*Buffer, error) {
+	if width <= 0 || height <= 0 {
+		return nil, common.ErrWidthOrHeightNotPositive
+	}
+	pixels := make([]rgba.Color, width*height)
+	fill(pixels, bgColor)
+	return &Buffer{
+		width:           width,
+		height:          height,
+		backgroundColor: bgColor,
+		foregroundColor: fgColor,
+		pixels:          pixels,
+	}, nil
+}
+
+// Dimensions returns the width and height.
+func (cnv *Buffer) Dimensions() (width, height int) {
+	return cnv.width, cnv.height
+}
+
+// Clone returns a deep copy of the canvas,
+// which is not affected by the subsequent changes of the canvas.
+func (cnv *Buffer) Clone() canvas.Canvas {
+	clone := *cnv
+	clone.pixels = make([]rgba.Color, len(cnv.pixels))
+	copy(clone.pixels, cnv.pixels)
+	return &clone
+}
+
+// Pixels returns the underlying pixel buffer.
+func (cnv *Buffer) Pixels() []rgba.Color {
+	return cnv.pixels
+}
+
+// at is the same as At, but without boundary checks.
+func (cnv *Buffer) at(x, y int) rgba.Color {
+	index := xyToIndex(cnv.width, x, y)
+	return cnv.pixels[index]
+}
+
+// At returns the color of the pixel at (x, y).
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x, y) is outside the canvas.
+//
+func (cnv *Buffer) At(x, y int) (color.Color, error) {
+	if !isPointInsideCanvas(cnv.width, cnv.height, x, y) {
+		return cnv.backgroundColor, common.ErrPointOutsideCanvas
+	}
+	return cnv.at(x, y), nil
+}
+
+// set is the same as Set, but without boundary checks.
+func (cnv *Buffer) set(x, y int, rc rgba.Color) {
+	index := xyToIndex(cnv.width, x, y)
+	cnv.pixels[index] = rc
+}
+
+// Set sets the color of the pixel at (x, y).
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x, y) is outside the canvas.
+//
+// common.ErrColorTypeNotSupported:
+// Will be returned if c is not supported by the canvas.
+//
+func (cnv *Buffer) Set(x, y int, c color.Color) error {
+	if !isPointInsideCanvas(cnv.width, cnv.height, x, y) {
+		return common.ErrPointOutsideCanvas
+	}
+	rc, ok := c.(rgba.Color)
+	if !ok {
+		return common.ErrColorTypeNotSupported
+	}
+	cnv.set(x, y, rc)
+	return nil
+}
+
+// ForegroundColor returns the foreground color,
+// which is used to draw lines, shapes, and text.
+func (cnv *Buffer) ForegroundColor() color.Color {
+	return cnv.foregroundColor
+}
+
+// SetForegroundColor sets the foreground color,
+// which is used to draw lines, shapes, and text.
+//
+// Errors
+//
+// common.ErrColorTypeNotSupported:
+// Will be returned if c is not supported by the canvas.
+//
+func (cnv *Buffer) SetForegroundColor(c color.Color) error {
+	rc, ok := c.(rgba.Color)
+	if !ok {
+		return common.ErrColorTypeNotSupported
+	}
+	cnv.foregroundColor = rc
+	return nil
+}
+
+// SetLineMode sets the line mode used by DrawLine.
+//
+// Errors
+//
+// common.ErrLineModeNotSupported:
+// Will be returned if mode is not supported by the canvas.
+//
+func (cnv *Buffer) SetLineMode(mode canvas.LineMode) error {
+	switch mode {
+	case canvas.LineModeHorizontalOrVertical, canvas.LineModeAnyAngle:
+		cnv.lineMode = mode
+		return nil
+	default:
+		return common.ErrLineModeNotSupported
+	}
+}
+
+// plotFunc returns a function which paints the pixel at (x, y) with rc,
+// without boundary checks.
+func (cnv *Buffer) plotFunc(rc rgba.Color) func(x, y int) {
+	return func(x, y int) {
+		cnv.set(x, y, rc)
+	}
+}
+
+// DrawLine draws a line from (x1, y1) to (x2, y2).
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x1, y1) or (x2, y2) is outside the canvas.
+//
+// common.ErrLineNotHorizontalOrVertical:
+// Will be returned if the line mode is canvas.LineModeHorizontalOrVertical,
+// and the line is not horizontal or vertical.
+//
+func (cnv *Buffer) DrawLine(x1, y1, x2, y2 int) error {
+	if !isPointInsideCanvas(cnv.width, cnv.height, x1, y1) || !isPointInsideCanvas(cnv.width, cnv.height, x2, y2) {
+		return common.ErrPointOutsideCanvas
+	}
+	// Check whether (x1, y1) and (x2, y2) are horizontally or vertically aligned
+	if cnv.lineMode == canvas.LineModeHorizontalOrVertical && x1 != x2 && y1 != y2 {
+		return common.ErrLineNotHorizontalOrVertical
+	}
+	raster.Line(x1, y1, x2, y2, cnv.plotFunc(cnv.foregroundColor))
+	return nil
+}
+
+// drawRect is the same as DrawRect, but without boundary checks,
+// and the rectangle is drawn with rc.
+func (cnv *Buffer) drawRect(x1, y1, x2, y2 int, rc rgba.Color) {
+	plot := cnv.plotFunc(rc)
+	raster.Line(x1, y1, x2, y1, plot)
+	raster.Line(x1, y2, x2, y2, plot)
+	raster.Line(x1, y1, x1, y2, plot)
+	raster.Line(x2, y1, x2, y2, plot)
+}
+
+// DrawRect draws a rectangle.
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x1, y1) or (x2, y2) is outside the canvas.
+//
+func (cnv *Buffer) DrawRect(x1, y1, x2, y2 int) error {
+	if !isPointInsideCanvas(cnv.width, cnv.height, x1, y1) || !isPointInsideCanvas(cnv.width, cnv.height, x2, y2) {
+		return common.ErrPointOutsideCanvas
+	}
+	cnv.drawRect(x1, y1, x2, y2, cnv.foregroundColor)
+	return nil
+}
+
+// fillRect paints the interior of the rectangle with rc,
+// without boundary checks.
+func (cnv *Buffer) fillRect(x1, y1, x2, y2 int, rc rgba.Color) {
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+	for y := y1 + 1; y < y2; y++ {
+		for x := x1 + 1; x < x2; x++ {
+			cnv.set(x, y, rc)
+		}
+	}
+}
+
+// FillRect draws a rectangle with stroke,
+// and paints the interior of the rectangle with fill.
+// The foreground color is used if stroke is nil.
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x1, y1) or (x2, y2) is outside the canvas.
+//
+// common.ErrColorTypeNotSupported:
+// Will be returned if stroke or fill is not supported by the canvas.
+//
+func (cnv *Buffer) FillRect(x1, y1, x2, y2 int, stroke, fill color.Color) error {
+	if !isPointInsideCanvas(cnv.width, cnv.height, x1, y1) || !isPointInsideCanvas(cnv.width, cnv.height, x2, y2) {
+		return common.ErrPointOutsideCanvas
+	}
+	strokeRC := cnv.foregroundColor
+	if stroke != nil {
+		rc, ok := stroke.(rgba.Color)
+		if !ok {
+			return common.ErrColorTypeNotSupported
+		}
+		strokeRC = rc
+	}
+	fillRC, ok := fill.(rgba.Color)
+	if !ok {
+		return common.ErrColorTypeNotSupported
+	}
+	cnv.fillRect(x1, y1, x2, y2, fillRC)
+	cnv.drawRect(x1, y1, x2, y2, strokeRC)
+	return nil
+}
+
+// DrawCircle draws a circle centered at (xc, yc) with radius r.
+// The interior of the circle is also painted if filled is true.
+//
+// Errors
+//
+// common.ErrRadiusNegative:
+// Will be returned if r < 0.
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if any part of the circle is outside the canvas.
+//
+func (cnv *Buffer) DrawCircle(xc, yc, r int, filled bool) error {
+	return cnv.DrawEllipse(xc, yc, r, r, filled)
+}
+
+// DrawEllipse draws an axis-aligned ellipse centered at (xc, yc)
+// with horizontal radius rx and vertical radius ry.
+// The interior of the ellipse is also painted if filled is true.
+//
+// Errors
+//
+// common.ErrRadiusNegative:
+// Will be returned if rx < 0, or ry < 0.
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if any part of the ellipse is outside the canvas.
+//
+func (cnv *Buffer) DrawEllipse(xc, yc, rx, ry int, filled bool) error {
+	if rx < 0 || ry < 0 {
+		return common.ErrRadiusNegative
+	}
+	if !isPointInsideCanvas(cnv.width, cnv.height, xc-rx, yc-ry) || !isPointInsideCanvas(cnv.width, cnv.height, xc+rx, yc+ry) {
+		return common.ErrPointOutsideCanvas
+	}
+	raster.Ellipse(xc, yc, rx, ry, filled, cnv.plotFunc(cnv.foregroundColor))
+	return nil
+}
+
+// arePointsInsideCanvas returns whether all of points are within the bounds.
+func (cnv *Buffer) arePointsInsideCanvas(points []canvas.Point) bool {
+	for _, p := range points {
+		if !isPointInsideCanvas(cnv.width, cnv.height, p.X, p.Y) {
+			return false
+		}
+	}
+	return true
+}
+
+// drawPolyline is the same as DrawPolyline, but without boundary checks.
+func (cnv *Buffer) drawPolyline(points []canvas.Point) {
+	plot := cnv.plotFunc(cnv.foregroundColor)
+	for i := 1; i < len(points); i++ {
+		raster.Line(points[i-1].X, points[i-1].Y, points[i].X, points[i].Y, plot)
+	}
+}
+
+// DrawPolyline draws connected line segments through points.
+// The line mode does not apply to polylines.
+//
+// Errors
+//
+// common.ErrTooFewPoints:
+// Will be returned if len(points) < 2.
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if any of points is outside the canvas.
+//
+func (cnv *Buffer) DrawPolyline(points []canvas.Point) error {
+	if len(points) < 2 {
+		return common.ErrTooFewPoints
+	}
+	if !cnv.arePointsInsideCanvas(points) {
+		return common.ErrPointOutsideCanvas
+	}
+	cnv.drawPolyline(points)
+	return nil
+}
+
+// DrawPolygon draws a closed polygon with vertices points.
+// The interior of the polygon is also painted according to rule
+// if filled is true. The line mode does not apply to polygons.
+//
+// Errors
+//
+// common.ErrTooFewPoints:
+// Will be returned if len(points) < 3.
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if any of points is outside the canvas.
+//
+// common.ErrFillRuleNotSupported:
+// Will be returned if rule is not supported by the canvas.
+//
+func (cnv *Buffer) DrawPolygon(points []canvas.Point, filled bool, rule canvas.FillRule) error {
+	if len(points) < 3 {
+		return common.ErrTooFewPoints
+	}
+	if !cnv.arePointsInsideCanvas(points) {
+		return common.ErrPointOutsideCanvas
+	}
+	if rule != canvas.FillRuleEvenOdd && rule != canvas.FillRuleNonZero {
+		return common.ErrFillRuleNotSupported
+	}
+	plot := cnv.plotFunc(cnv.foregroundColor)
+	if filled {
+		raster.FillPolygon(points, rule, plot)
+	}
+	cnv.drawPolyline(points)
+	last := len(points) - 1
+	raster.Line(points[last].X, points[last].Y, points[0].X, points[0].Y, plot)
+	return nil
+}
+
+// SetTextMode sets the text mode used by DrawText.
+// Only canvas.TextModeBitmap is supported,
+// since a pixel could not hold a character.
+//
+// Errors
+//
+// common.ErrTextModeNotSupported:
+// Will be returned if mode is not supported by the canvas.
+//
+func (cnv *Buffer) SetTextMode(mode canvas.TextMode) error {
+	if mode != canvas.TextModeBitmap {
+		return common.ErrTextModeNotSupported
+	}
+	return nil
+}
+
+// DrawText draws text with the built-in bitmap font,
+// using the foreground color. (x, y) is the top-left corner of the text.
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if any part of the text is outside the canvas.
+//
+func (cnv *Buffer) DrawText(x, y int, text string) error {
+	if !isPointInsideCanvas(cnv.width, cnv.height, x, y) {
+		return common.ErrPointOutsideCanvas
+	}
+	width, height := font.Bounds(text)
+	if width != 0 && !isPointInsideCanvas(cnv.width, cnv.height, x+width-1, y+height-1) {
+		return common.ErrPointOutsideCanvas
+	}
+	font.Draw(x, y, text, cnv.plotFunc(cnv.foregroundColor))
+	return nil
+}
+
+// floodFill is the same as FloodFill, but without boundary checks.
+// The pixels similar to colorToBeReplaced within tolerance are replaced by rc.
+// If tolerance is 0, rc must be different from colorToBeReplaced,
+// so that a filled pixel is never filled again.
+func (cnv *Buffer) floodFill(x, y int, rc, colorToBeReplaced rgba.Color, tolerance int, diagonal bool) {
+	// NOTE: A filled pixel may still be similar to colorToBeReplaced
+	// if tolerance is positive, so the filled pixels are recorded
+	var filled []bool
+	if tolerance > 0 {
+		filled = make([]bool, len(cnv.pixels))
+	}
+	raster.FloodFill(cnv.width, cnv.height, x, y, diagonal, func(x, y int) bool {
+		index := xyToIndex(cnv.width, x, y)
+		if filled != nil {
+			return !filled[index] && isSimilar(cnv.pixels[index], colorToBeReplaced, tolerance)
+		}
+		return cnv.pixels[index] == colorToBeReplaced
+	}, func(x1, x2, y int) {
+		for index := xyToIndex(cnv.width, x1, y); index <= xyToIndex(cnv.width, x2, y); index++ {
+			cnv.pixels[index] = rc
+			if filled != nil {
+				filled[index] = true
+			}
+		}
+	})
+}
+
+// BucketFill fills the area enclosing (x, y). The pixels connecting to
+// (x, y) having the same color as that at (x, y) are replaced by c.
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x, y) is outside the canvas.
+//
+// common.ErrColorTypeNotSupported:
+// Will be returned if c is not supported by the canvas.
+//
+func (cnv *Buffer) BucketFill(x, y int, c color.Color) error {
+	return cnv.FloodFill(x, y, c, canvas.FillOptions{})
+}
+
+// FloodFill fills the area enclosing (x, y) like BucketFill,
+// but the area is determined according to opts.
+// The difference of two colors is the maximum difference
+// of their red, green, blue, and alpha components.
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x, y) is outside the canvas.
+//
+// common.ErrColorTypeNotSupported:
+// Will be returned if c is not supported by the canvas.
+//
+// common.ErrToleranceNegative:
+// Will be returned if opts.Tolerance < 0.
+//
+func (cnv *Buffer) FloodFill(x, y int, c color.Color, opts canvas.FillOptions) error {
+	if !isPointInsideCanvas(cnv.width, cnv.height, x, y) {
+		return common.ErrPointOutsideCanvas
+	}
+	rc, ok := c.(rgba.Color)
+	if !ok {
+		return common.ErrColorTypeNotSupported
+	}
+	if opts.Tolerance < 0 {
+		return common.ErrToleranceNegative
+	}
+	colorToBeReplaced := cnv.at(x, y)
+	if rc == colorToBeReplaced && opts.Tolerance == 0 {
+		return nil
+	}
+	if opts.Global {
+		for i, pc := range cnv.pixels {
+			if isSimilar(pc, colorToBeReplaced, opts.Tolerance) {
+				cnv.pixels[i] = rc
+			}
+		}
+		return nil
+	}
+	cnv.floodFill(x, y, rc, colorToBeReplaced, opts.Tolerance, opts.Diagonal)
+	return nil
+}
+
+// Crop crops the canvas to the rectangle with corners (x1, y1) and (x2, y2).
+// The pixels on the edges of the rectangle are kept.
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x1, y1) or (x2, y2) is outside the canvas.
+//
+func (cnv *Buffer) Crop(x1, y1, x2, y2 int) error {
+	if !isPointInsideCanvas(cnv.width, cnv.height, x1, y1) || !isPointInsideCanvas(cnv.width, cnv.height, x2, y2) {
+		return common.ErrPointOutsideCanvas
+	}
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+	width, height := x2-x1+1, y2-y1+1
+	pixels := make([]rgba.Color, width*height)
+	for y := 0; y < height; y++ {
+		copy(pixels[xyToIndex(width, 0, y):], cnv.pixels[xyToIndex(cnv.width, x1, y1+y):xyToIndex(cnv.width, x2+1, y1+y)])
+	}
+	cnv.width, cnv.height, cnv.pixels = width, height, pixels
+	return nil
+}
+
+// Extend extends the canvas by left, top, right, and bottom pixels
+// on the respective sides. The new pixels are in the background color.
+//
+// Errors
+//
+// common.ErrExtentNegative:
+// Will be returned if left < 0, top < 0, right < 0, or bottom < 0.
+//
+func (cnv *Buffer) Extend(left, top, right, bottom int) error {
+	if left < 0 || top < 0 || right < 0 || bottom < 0 {
+		return common.ErrExtentNegative
+	}
+	width, height := cnv.width+left+right, cnv.height+top+bottom
+	pixels := make([]rgba.Color, width*height)
+	fill(pixels, cnv.backgroundColor)
+	for y := 0; y < cnv.height; y++ {
+		copy(pixels[xyToIndex(width, left, top+y):], cnv.pixels[xyToIndex(cnv.width, 0, y):xyToIndex(cnv.width, 0, y+1)])
+	}
+	cnv.width, cnv.height, cnv.pixels = width, height, pixels
+	return nil
+}
+
+// Scale scales the canvas to width x height.
+// Each pixel takes the color of the nearest pixel of the original canvas.
+//
+// Errors
+//
+// common.ErrWidthOrHeightNotPositive:
+// Will be returned if width <= 0, or height <= 0.
+//
+func (cnv *Buffer) Scale(width, height int) error {
+	if width <= 0 || height <= 0 {
+		return common.ErrWidthOrHeightNotPositive
+	}
+	pixels := make([]rgba.Color, width*height)
+	for y := 0; y < height; y++ {
+		sy := nearest(y, height, cnv.height)
+		for x := 0; x < width; x++ {
+			pixels[xyToIndex(width, x, y)] = cnv.at(nearest(x, width, cnv.width), sy)
+		}
+	}
+	cnv.width, cnv.height, cnv.pixels = width, height, pixels
+	return nil
+}
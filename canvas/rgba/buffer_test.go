@@ -0,0 +1,479 @@
+package rgba
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/color"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/color/rgba"
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+var (
+	white = rgba.Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	black = rgba.Color{R: 0x00, G: 0x00, B: 0x00, A: 0xff}
+	red   = rgba.Color{R: 0xff, G: 0x00, B: 0x00, A: 0xff}
+	blue  = rgba.Color{R: 0x00, G: 0x00, B: 0xff, A: 0xff}
+)
+
+// newTestBuffer returns a new width x height Buffer with a white background
+// and a black foreground, which accepts lines of any angle.
+func newTestBuffer(width, height int) *Buffer {
+	cnv, err := NewBuffer(width, height, white, black)
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.SetLineMode(canvas.LineModeAnyAngle)
+	if err != nil {
+		panic(err)
+	}
+	return cnv
+}
+
+// rows returns the pixels of cnv as a slice of strings, one string per row.
+// White, black, red, and blue pixels are represented by '.', 'x', 'R',
+// and 'B' respectively. Other pixels are represented by '?'.
+func rows(cnv *Buffer) []string {
+	width, height := cnv.Dimensions()
+	result := make([]string, height)
+	for j := range result {
+		row := make([]byte, width)
+		for i := range row {
+			switch cnv.at(i, j) {
+			case white:
+				row[i] = '.'
+			case black:
+				row[i] = 'x'
+			case red:
+				row[i] = 'R'
+			case blue:
+				row[i] = 'B'
+			default:
+				row[i] = '?'
+			}
+		}
+		result[j] = string(row)
+	}
+	return result
+}
+
+func TestNewBuffer(t *testing.T) {
+	cnv, err := NewBuffer(3, 2, white, black)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	expected := []string{"...", "..."}
+	if !reflect.DeepEqual(rows(cnv), expected) {
+		t.Errorf("Expected: %#v, Got: %#v", expected, rows(cnv))
+	}
+	if w, h := cnv.Dimensions(); w != 3 || h != 2 {
+		t.Errorf("Expected: (3, 2), Got: (%d, %d)", w, h)
+	}
+	if len(cnv.Pixels()) != 6 {
+		t.Errorf("Expected: 6, Got: %d", len(cnv.Pixels()))
+	}
+
+	casesNeg := []struct {
+		w int
+		h int
+	}{
+		{0, 1},
+		{1, 0},
+		{-1, 1},
+	}
+	for _, c := range casesNeg {
+		_, err := NewBuffer(c.w, c.h, white, black)
+		if err != common.ErrWidthOrHeightNotPositive {
+			t.Errorf("Case: (%d, %d), Expected: %#v, Got: %#v", c.w, c.h, common.ErrWidthOrHeightNotPositive, err)
+		}
+	}
+}
+
+func TestBuffer_Clone(t *testing.T) {
+	cnv := newTestBuffer(3, 1)
+	clone := cnv.Clone().(*Buffer)
+	err := cnv.DrawLine(0, 0, 2, 0)
+	if err != nil {
+		panic(err)
+	}
+	expected := []string{"..."}
+	if !reflect.DeepEqual(rows(clone), expected) {
+		t.Errorf("Expected: %#v, Got: %#v", expected, rows(clone))
+	}
+	err = clone.DrawLine(0, 0, 1, 0)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+}
+
+func TestBuffer_At_Set(t *testing.T) {
+	cnv := newTestBuffer(2, 2)
+
+	err := cnv.Set(1, 0, red)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	c, err := cnv.At(1, 0)
+	if err != nil || c != red {
+		t.Errorf("Expected: (%#v, nil), Got: (%#v, %#v)", red, c, err)
+	}
+
+	// Negative Cases
+	casesNeg := []struct {
+		x   int
+		y   int
+		c   color.Color
+		err error
+	}{
+		{-1, 0, red, common.ErrPointOutsideCanvas},
+		{0, 2, red, common.ErrPointOutsideCanvas},
+		{0, 0, bytecolor.Color('x'), common.ErrColorTypeNotSupported},
+	}
+	for _, c := range casesNeg {
+		err := cnv.Set(c.x, c.y, c.c)
+		if err != c.err {
+			t.Errorf("Case: (%d, %d, %#v), Expected: %#v, Got: %#v", c.x, c.y, c.c, c.err, err)
+		}
+	}
+	_, err = cnv.At(2, 0)
+	if err != common.ErrPointOutsideCanvas {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrPointOutsideCanvas, err)
+	}
+}
+
+func TestBuffer_SetForegroundColor(t *testing.T) {
+	cnv := newTestBuffer(2, 1)
+	err := cnv.SetForegroundColor(red)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	if cnv.ForegroundColor() != red {
+		t.Errorf("Expected: %#v, Got: %#v", red, cnv.ForegroundColor())
+	}
+	err = cnv.SetForegroundColor(bytecolor.Color('x'))
+	if err != common.ErrColorTypeNotSupported {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrColorTypeNotSupported, err)
+	}
+}
+
+func TestBuffer_DrawLine(t *testing.T) {
+	cnv := newTestBuffer(4, 3)
+	err := cnv.DrawLine(0, 0, 3, 2)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	expected := []string{"x...", ".xx.", "...x"}
+	if !reflect.DeepEqual(rows(cnv), expected) {
+		t.Errorf("Expected: %#v, Got: %#v", expected, rows(cnv))
+	}
+
+	err = cnv.DrawLine(0, 0, 4, 0)
+	if err != common.ErrPointOutsideCanvas {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrPointOutsideCanvas, err)
+	}
+	err = cnv.SetLineMode(canvas.LineModeHorizontalOrVertical)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	err = cnv.DrawLine(0, 0, 3, 2)
+	if err != common.ErrLineNotHorizontalOrVertical {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrLineNotHorizontalOrVertical, err)
+	}
+	err = cnv.SetLineMode(canvas.LineMode(-1))
+	if err != common.ErrLineModeNotSupported {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrLineModeNotSupported, err)
+	}
+}
+
+func TestBuffer_DrawRect_FillRect(t *testing.T) {
+	cnv := newTestBuffer(5, 4)
+	err := cnv.DrawRect(0, 0, 2, 2)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	err = cnv.FillRect(4, 3, 2, 1, nil, red)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	expected := []string{"xxx..", "x.xxx", "xxxRx", "..xxx"}
+	if !reflect.DeepEqual(rows(cnv), expected) {
+		t.Errorf("Expected: %#v, Got: %#v", expected, rows(cnv))
+	}
+	err = cnv.FillRect(0, 0, 4, 3, blue, white)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	expected = []string{"BBBBB", "B...B", "B...B", "BBBBB"}
+	if !reflect.DeepEqual(rows(cnv), expected) {
+		t.Errorf("Expected: %#v, Got: %#v", expected, rows(cnv))
+	}
+
+	casesNeg := []struct {
+		x1, y1, x2, y2 int
+		stroke, fill   color.Color
+		err            error
+	}{
+		{0, 0, 5, 3, nil, red, common.ErrPointOutsideCanvas},
+		{0, 0, 4, 3, bytecolor.Color('x'), red, common.ErrColorTypeNotSupported},
+		{0, 0, 4, 3, nil, bytecolor.Color('x'), common.ErrColorTypeNotSupported},
+	}
+	for _, c := range casesNeg {
+		err := cnv.FillRect(c.x1, c.y1, c.x2, c.y2, c.stroke, c.fill)
+		if err != c.err {
+			t.Errorf("Case: (%d, %d, %d, %d), Expected: %#v, Got: %#v", c.x1, c.y1, c.x2, c.y2, c.err, err)
+		}
+	}
+	err = cnv.DrawRect(0, 0, 0, 4)
+	if err != common.ErrPointOutsideCanvas {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrPointOutsideCanvas, err)
+	}
+}
+
+func TestBuffer_DrawEllipse(t *testing.T) {
+	cnv := newTestBuffer(5, 5)
+	err := cnv.DrawCircle(2, 2, 2, false)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	expected := []string{".xxx.", "x...x", "x...x", "x...x", ".xxx."}
+	if !reflect.DeepEqual(rows(cnv), expected) {
+		t.Errorf("Expected: %#v, Got: %#v", expected, rows(cnv))
+	}
+	err = cnv.SetForegroundColor(red)
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.DrawEllipse(2, 2, 1, 1, true)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	expected = []string{".xxx.", "x.R.x", "xRRRx", "x.R.x", ".xxx."}
+	if !reflect.DeepEqual(rows(cnv), expected) {
+		t.Errorf("Expected: %#v, Got: %#v", expected, rows(cnv))
+	}
+
+	err = cnv.DrawEllipse(2, 2, -1, 1, false)
+	if err != common.ErrRadiusNegative {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrRadiusNegative, err)
+	}
+	err = cnv.DrawCircle(2, 2, 3, false)
+	if err != common.ErrPointOutsideCanvas {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrPointOutsideCanvas, err)
+	}
+}
+
+func TestBuffer_DrawPolygon(t *testing.T) {
+	cnv := newTestBuffer(5, 4)
+	err := cnv.DrawPolyline([]canvas.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 3}})
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	err = cnv.SetForegroundColor(blue)
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.DrawPolygon([]canvas.Point{{X: 0, Y: 1}, {X: 3, Y: 1}, {X: 0, Y: 3}}, true, canvas.FillRuleNonZero)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	expected := []string{"xxxxx", "BBBBx", "BBB.x", "B...x"}
+	if !reflect.DeepEqual(rows(cnv), expected) {
+		t.Errorf("Expected: %#v, Got: %#v", expected, rows(cnv))
+	}
+
+	casesNeg := []struct {
+		points []canvas.Point
+		rule   canvas.FillRule
+		err    error
+	}{
+		{[]canvas.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}, canvas.FillRuleEvenOdd, common.ErrTooFewPoints},
+		{[]canvas.Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 5, Y: 0}}, canvas.FillRuleEvenOdd, common.ErrPointOutsideCanvas},
+		{[]canvas.Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 0}}, canvas.FillRule(-1), common.ErrFillRuleNotSupported},
+	}
+	for _, c := range casesNeg {
+		err := cnv.DrawPolygon(c.points, true, c.rule)
+		if err != c.err {
+			t.Errorf("Case: %v, Expected: %#v, Got: %#v", c.points, c.err, err)
+		}
+	}
+	err = cnv.DrawPolyline([]canvas.Point{{X: 0, Y: 0}})
+	if err != common.ErrTooFewPoints {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrTooFewPoints, err)
+	}
+	err = cnv.DrawPolyline([]canvas.Point{{X: 0, Y: 0}, {X: 0, Y: 4}})
+	if err != common.ErrPointOutsideCanvas {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrPointOutsideCanvas, err)
+	}
+}
+
+func TestBuffer_DrawText(t *testing.T) {
+	err := newTestBuffer(1, 1).SetTextMode(canvas.TextModeBitmap)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	err = newTestBuffer(1, 1).SetTextMode(canvas.TextModeCharacter)
+	if err != common.ErrTextModeNotSupported {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrTextModeNotSupported, err)
+	}
+
+	cnv := newTestBuffer(6, 8)
+	err = cnv.DrawText(1, 1, "L")
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	expected := []string{"......", ".x....", ".x....", ".x....", ".x....", ".x....", ".x....", ".xxxxx"}
+	if !reflect.DeepEqual(rows(cnv), expected) {
+		t.Errorf("Expected: %#v, Got: %#v", expected, rows(cnv))
+	}
+	err = cnv.DrawText(0, 0, "")
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+
+	casesNeg := []struct {
+		x, y int
+		text string
+	}{
+		{6, 0, ""},
+		{2, 1, "L"},
+		{0, 0, "LL"},
+	}
+	for _, c := range casesNeg {
+		err := cnv.DrawText(c.x, c.y, c.text)
+		if err != common.ErrPointOutsideCanvas {
+			t.Errorf("Case: (%d, %d, %q), Expected: %#v, Got: %#v", c.x, c.y, c.text, common.ErrPointOutsideCanvas, err)
+		}
+	}
+}
+
+func TestBuffer_BucketFill(t *testing.T) {
+	cnv := newTestBuffer(5, 4)
+	err := cnv.DrawRect(1, 0, 3, 2)
+	if err != nil {
+		panic(err)
+	}
+
+	cases := []struct {
+		x, y int
+		c    rgba.Color
+		rows []string
+	}{
+		{0, 0, red, []string{"RxxxR", "Rx.xR", "RxxxR", "RRRRR"}},
+		{2, 1, blue, []string{"RxxxR", "RxBxR", "RxxxR", "RRRRR"}},
+		{1, 1, blue, []string{"RBBBR", "RBBBR", "RBBBR", "RRRRR"}},
+		{0, 3, red, []string{"RBBBR", "RBBBR", "RBBBR", "RRRRR"}},
+	}
+	for _, c := range cases {
+		err := cnv.BucketFill(c.x, c.y, c.c)
+		if err != nil {
+			t.Errorf("Case: (%d, %d, %#v), Expected: err == nil, Got: %#v", c.x, c.y, c.c, err)
+		}
+		if !reflect.DeepEqual(rows(cnv), c.rows) {
+			t.Errorf("Case: (%d, %d, %#v), Expected: %#v, Got: %#v", c.x, c.y, c.c, c.rows, rows(cnv))
+		}
+	}
+
+	err = cnv.BucketFill(5, 0, red)
+	if err != common.ErrPointOutsideCanvas {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrPointOutsideCanvas, err)
+	}
+	err = cnv.BucketFill(0, 0, bytecolor.Color('o'))
+	if err != common.ErrColorTypeNotSupported {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrColorTypeNotSupported, err)
+	}
+}
+
+func TestBuffer_FloodFill(t *testing.T) {
+	// A light gray, which differs from white by 15 in each component
+	gray := rgba.Color{R: 0xf0, G: 0xf0, B: 0xf0, A: 0xff}
+	newGridBuffer := func() *Buffer {
+		cnv := newTestBuffer(5, 2)
+		err := cnv.DrawLine(2, 0, 2, 1)
+		if err != nil {
+			panic(err)
+		}
+		cnv.set(1, 0, gray)
+		cnv.set(3, 1, gray)
+		return cnv
+	}
+
+	// Positive Cases
+	casesPos := []struct {
+		c    rgba.Color
+		opts canvas.FillOptions
+		rows []string
+	}{
+		{blue, canvas.FillOptions{}, []string{"B?x..", "BBx?."}},
+		{blue, canvas.FillOptions{Tolerance: 14}, []string{"B?x..", "BBx?."}},
+		{blue, canvas.FillOptions{Tolerance: 15}, []string{"BBx..", "BBx?."}},
+		{blue, canvas.FillOptions{Global: true}, []string{"B?xBB", "BBx?B"}},
+		{blue, canvas.FillOptions{Global: true, Tolerance: 15}, []string{"BBxBB", "BBxBB"}},
+		{white, canvas.FillOptions{}, []string{".?x..", "..x?."}},
+		{white, canvas.FillOptions{Tolerance: 15, Diagonal: true}, []string{"..x..", "..x?."}},
+	}
+	for _, c := range casesPos {
+		cnv := newGridBuffer()
+		err := cnv.FloodFill(0, 0, c.c, c.opts)
+		if err != nil {
+			t.Errorf("Case: (%#v, %#v), Expected: err == nil, Got: %#v", c.c, c.opts, err)
+		}
+		if !reflect.DeepEqual(rows(cnv), c.rows) {
+			t.Errorf("Case: (%#v, %#v), Expected: %#v, Got: %#v", c.c, c.opts, c.rows, rows(cnv))
+		}
+	}
+
+	// Negative Cases
+	cnv := newGridBuffer()
+	err := cnv.FloodFill(0, 2, red, canvas.FillOptions{Global: true})
+	if err != common.ErrPointOutsideCanvas {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrPointOutsideCanvas, err)
+	}
+	err = cnv.FloodFill(0, 0, bytecolor.Color('o'), canvas.FillOptions{Tolerance: 1})
+	if err != common.ErrColorTypeNotSupported {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrColorTypeNotSupported, err)
+	}
+	err = cnv.FloodFill(0, 0, red, canvas.FillOptions{Tolerance: -1})
+	if err != common.ErrToleranceNegative {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrToleranceNegative, err)
+	}
+}
+
+func TestBuffer_Crop_Extend_Scale(t *testing.T) {
+	cnv := newTestBuffer(3, 2)
+	cnv.set(0, 0, red)
+	cnv.set(2, 1, blue)
+
+	cases := []struct {
+		f    func() error
+		rows []string
+	}{
+		{func() error { return cnv.Extend(1, 1, 0, 0) }, []string{"....", ".R..", "...B"}},
+		{func() error { return cnv.Crop(3, 2, 1, 1) }, []string{"R..", "..B"}},
+		{func() error { return cnv.Scale(6, 2) }, []string{"RR....", "....BB"}},
+		{func() error { return cnv.Scale(3, 1) }, []string{"..B"}},
+	}
+	for i, c := range cases {
+		err := c.f()
+		if err != nil {
+			t.Errorf("Case #%d: Expected: err == nil, Got: %#v", i, err)
+		}
+		if !reflect.DeepEqual(rows(cnv), c.rows) {
+			t.Errorf("Case #%d: Expected: %#v, Got: %#v", i, c.rows, rows(cnv))
+		}
+	}
+
+	err := cnv.Crop(0, 0, 3, 0)
+	if err != common.ErrPointOutsideCanvas {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrPointOutsideCanvas, err)
+	}
+	err = cnv.Extend(-1, 0, 0, 0)
+	if err != common.ErrExtentNegative {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrExtentNegative, err)
+	}
+	err = cnv.Scale(3, 0)
+	if err != common.ErrWidthOrHeightNotPositive {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrWidthOrHeightNotPositive, err)
+	}
+}
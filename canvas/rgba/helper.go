@@ -0,0 +1,48 @@
+package rgba
+
+import "github.com/asukakenji/drawing-challenge/color/rgba"
+
+// xyToIndex translates a 2D coordinate into a 1D index.
+func xyToIndex(width, x, y int) int {
+	return y*width + x
+}
+
+// isPointInsideCanvas returns whether (x, y) is within the bounds.
+func isPointInsideCanvas(width, height, x, y int) bool {
+	return 0 <= x && x < width && 0 <= y && y < height
+}
+
+// nearest translates the coordinate i in a row or column of n pixels
+// into that of the nearest pixel in a row or column of m pixels.
+// The centers of the pixels are compared.
+func nearest(i, n, m int) int {
+	return (2*i + 1) * m / (2 * n)
+}
+
+// fill fills b with rc
+// See the bytes.Repeat: https://golang.org/src/bytes/bytes.go
+func fill(b []rgba.Color, rc rgba.Color) {
+	b[0] = rc
+	bp := 1
+	for bp < len(b) {
+		copy(b[bp:], b[:bp])
+		bp *= 2
+	}
+}
+
+// isSimilar returns whether the maximum difference of the components
+// of c1 and c2 is not greater than tolerance.
+func isSimilar(c1, c2 rgba.Color, tolerance int) bool {
+	return absDiff(c1.R, c2.R) <= tolerance &&
+		absDiff(c1.G, c2.G) <= tolerance &&
+		absDiff(c1.B, c2.B) <= tolerance &&
+		absDiff(c1.A, c2.A) <= tolerance
+}
+
+// absDiff returns the absolute difference of a and b.
+func absDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
@@ -1,7 +1,9 @@
 // Package bytecolor defines the ByteColor type,
 // which implements the color.Color interface,
-// and the Parser type,
-// which implements the color.Parser interface.
+// the Parser type,
+// which implements the color.Parser interface,
+// and the Palette type,
+// which maps byte colors to RGBA colors.
 package bytecolor
 
 import "github.com/asukakenji/drawing-challenge/color"
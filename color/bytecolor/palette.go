@@ -0,0 +1,56 @@
+package bytecolor
+
+import (
+	imagecolor "image/color"
+
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+// Palette maps Colors to RGBA colors.
+// It is used by renderers which output real colors.
+type Palette map[Color]imagecolor.RGBA
+
+// DefaultPalette returns a new Palette,
+// which maps ' ' to white, 'R' to red, 'G' to green, 'B' to blue,
+// 'C' to cyan, 'M' to magenta, 'Y' to yellow, 'W' to white,
+// and every other printable ASCII character to black.
+func DefaultPalette() Palette {
+	palette := make(Palette)
+	for c := Color(0x21); c < 0x7f; c++ {
+		palette[c] = imagecolor.RGBA{0x00, 0x00, 0x00, 0xff}
+	}
+	palette[' '] = imagecolor.RGBA{0xff, 0xff, 0xff, 0xff}
+	palette['R'] = imagecolor.RGBA{0xff, 0x00, 0x00, 0xff}
+	palette['G'] = imagecolor.RGBA{0x00, 0xff, 0x00, 0xff}
+	palette['B'] = imagecolor.RGBA{0x00, 0x00, 0xff, 0xff}
+	palette['C'] = imagecolor.RGBA{0x00, 0xff, 0xff, 0xff}
+	palette['M'] = imagecolor.RGBA{0xff, 0x00, 0xff, 0xff}
+	palette['Y'] = imagecolor.RGBA{0xff, 0xff, 0x00, 0xff}
+	palette['W'] = imagecolor.RGBA{0xff, 0xff, 0xff, 0xff}
+	return palette
+}
+
+// ToRGBA returns the RGBA color to which c is mapped.
+// c is mapped by the palette if it is a Color,
+// or converted directly if it implements the image/color.Color interface.
+//
+// Errors
+//
+// common.ErrColorNotSupported:
+// Will be returned if c is a Color not in the palette,
+// or if c is neither a Color nor an image/color.Color.
+//
+func (palette Palette) ToRGBA(c interface{}) (imagecolor.RGBA, error) {
+	switch c := c.(type) {
+	case Color:
+		rgba, ok := palette[c]
+		if !ok {
+			return imagecolor.RGBA{}, common.ErrColorNotSupported
+		}
+		return rgba, nil
+	case imagecolor.Color:
+		return imagecolor.RGBAModel.Convert(c).(imagecolor.RGBA), nil
+	default:
+		return imagecolor.RGBA{}, common.ErrColorNotSupported
+	}
+}
@@ -0,0 +1,68 @@
+package bytecolor
+
+import (
+	imagecolor "image/color"
+	"testing"
+
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+func TestDefaultPalette(t *testing.T) {
+	palette := DefaultPalette()
+	cases := []struct {
+		c    Color
+		rgba imagecolor.RGBA
+	}{
+		{Color(' '), imagecolor.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{Color('x'), imagecolor.RGBA{0x00, 0x00, 0x00, 0xff}},
+		{Color('R'), imagecolor.RGBA{0xff, 0x00, 0x00, 0xff}},
+		{Color('~'), imagecolor.RGBA{0x00, 0x00, 0x00, 0xff}},
+	}
+	for _, c := range cases {
+		rgba, ok := palette[c.c]
+		if !ok || rgba != c.rgba {
+			t.Errorf("Case: %#v, Expected: %#v, Got: %#v", c.c, c.rgba, rgba)
+		}
+	}
+	if _, ok := palette[Color('\n')]; ok {
+		t.Errorf("Case: %#v, Expected: not in palette", Color('\n'))
+	}
+}
+
+func TestPalette_ToRGBA(t *testing.T) {
+	palette := Palette{
+		Color('R'): imagecolor.RGBA{0xff, 0x00, 0x00, 0xff},
+	}
+
+	// Positive Cases
+	casesPos := []struct {
+		c    interface{}
+		rgba imagecolor.RGBA
+	}{
+		{Color('R'), imagecolor.RGBA{0xff, 0x00, 0x00, 0xff}},
+		{imagecolor.NRGBA{0xff, 0xff, 0xff, 0x80}, imagecolor.RGBA{0x80, 0x80, 0x80, 0x80}},
+		{imagecolor.Gray{0x40}, imagecolor.RGBA{0x40, 0x40, 0x40, 0xff}},
+	}
+	for _, c := range casesPos {
+		rgba, err := palette.ToRGBA(c.c)
+		if err != nil {
+			t.Errorf("Case: %#v, Expected: err == nil, Got: %#v", c.c, err)
+		}
+		if rgba != c.rgba {
+			t.Errorf("Case: %#v, Expected: %#v, Got: %#v", c.c, c.rgba, rgba)
+		}
+	}
+
+	// Negative Cases
+	casesNeg := []interface{}{
+		Color('G'),
+		dummyColor('R'),
+		nil,
+	}
+	for _, c := range casesNeg {
+		_, err := palette.ToRGBA(c)
+		if err != common.ErrColorNotSupported {
+			t.Errorf("Case: %#v, Expected: %#v, Got: %#v", c, common.ErrColorNotSupported, err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Package rgba defines the Color type,
+// which implements the color.Color interface,
+// and the Parser type,
+// which implements the color.Parser interface.
+package rgba
+
+import (
+	imagecolor "image/color"
+
+	"github.com/asukakenji/drawing-challenge/color"
+)
+
+// Color represents a color value using 32 bits,
+// 8 bits for each of the red, green, blue, and alpha components.
+// The components are not alpha-premultiplied.
+// It implements the color.Color interface,
+// and the image/color.Color interface.
+type Color struct {
+	R uint8
+	G uint8
+	B uint8
+	A uint8
+}
+
+// Ensure that Color implements the color.Color interface,
+// and the image/color.Color interface.
+var (
+	_ color.Color      = Color{}
+	_ imagecolor.Color = Color{}
+)
+
+// Equals returns whether this Color equals c.
+func (rc Color) Equals(c color.Color) bool {
+	rc2, ok := c.(Color)
+	if !ok {
+		return false
+	}
+	return rc == rc2
+}
+
+// RGBA returns the alpha-premultiplied red, green, blue and alpha values
+// for the color. It implements the image/color.Color interface.
+func (rc Color) RGBA() (r, g, b, a uint32) {
+	return imagecolor.NRGBA{R: rc.R, G: rc.G, B: rc.B, A: rc.A}.RGBA()
+}
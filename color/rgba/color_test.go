@@ -0,0 +1,50 @@
+package rgba
+
+import (
+	"testing"
+
+	"github.com/asukakenji/drawing-challenge/color"
+)
+
+// This type is created for testing purpose only
+type dummyColor byte
+
+func (c1 dummyColor) Equals(c2 color.Color) bool {
+	return true
+}
+
+func TestColor_Equals(t *testing.T) {
+	cases := []struct {
+		c1     Color
+		c2     color.Color
+		result bool
+	}{
+		{Color{0x12, 0x34, 0x56, 0xff}, Color{0x12, 0x34, 0x56, 0xff}, true},
+		{Color{0x12, 0x34, 0x56, 0xff}, Color{0x12, 0x34, 0x56, 0x00}, false},
+		{Color{0x12, 0x34, 0x56, 0xff}, Color{0x12, 0x34, 0x57, 0xff}, false},
+		{Color{0x00, 0x00, 0x00, 0x00}, dummyColor(0), false},
+	}
+	for _, c := range cases {
+		result := c.c1.Equals(c.c2)
+		if result != c.result {
+			t.Errorf("Case: (%#v, %#v), Expected: %t, Got: %t", c.c1, c.c2, c.result, result)
+		}
+	}
+}
+
+func TestColor_RGBA(t *testing.T) {
+	cases := []struct {
+		c          Color
+		r, g, b, a uint32
+	}{
+		{Color{0xff, 0x80, 0x00, 0xff}, 0xffff, 0x8080, 0x0000, 0xffff},
+		{Color{0xff, 0xff, 0xff, 0x80}, 0x8080, 0x8080, 0x8080, 0x8080},
+		{Color{0xff, 0xff, 0xff, 0x00}, 0x0000, 0x0000, 0x0000, 0x0000},
+	}
+	for _, c := range cases {
+		r, g, b, a := c.c.RGBA()
+		if r != c.r || g != c.g || b != c.b || a != c.a {
+			t.Errorf("Case: %#v, Expected: (%#x, %#x, %#x, %#x), Got: (%#x, %#x, %#x, %#x)", c.c, c.r, c.g, c.b, c.a, r, g, b, a)
+		}
+	}
+}
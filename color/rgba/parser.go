@@ -0,0 +1,94 @@
+package rgba
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/asukakenji/drawing-challenge/color"
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+// Parser parses a CSS-like color string to a Color.
+// It implements the color.Parser interface.
+//
+// The following formats are supported:
+// "#RRGGBB", "#RRGGBBAA", "rgb(r,g,b)", and CSS named colors (like "red").
+// The formats are case-insensitive.
+type Parser struct {
+	DefaultColor Color
+}
+
+// Ensure that Parser implements the color.Parser interface.
+var (
+	_ color.Parser = &Parser{}
+)
+
+// ParseColor parses s and returns a Color.
+//
+// Errors
+//
+// common.ErrInvalidColor:
+// Will be returned if the color is not recognized by this parser.
+//
+func (parser *Parser) ParseColor(s string) (color.Color, error) {
+	if s == "" {
+		return parser.DefaultColor, nil
+	}
+	lower := strings.ToLower(s)
+	if strings.HasPrefix(lower, "#") {
+		return parseHex(lower[1:])
+	}
+	if strings.HasPrefix(lower, "rgb(") && strings.HasSuffix(lower, ")") {
+		return parseRGB(lower[len("rgb(") : len(lower)-1])
+	}
+	if c, ok := namedColors[lower]; ok {
+		return c, nil
+	}
+	return Color{}, common.ErrInvalidColor
+}
+
+// parseHex parses hex, which is "RRGGBB" or "RRGGBBAA", and returns a Color.
+//
+// Errors
+//
+// common.ErrInvalidColor:
+// Will be returned if hex is not in the above formats.
+//
+func parseHex(hex string) (Color, error) {
+	if len(hex) != 6 && len(hex) != 8 {
+		return Color{}, common.ErrInvalidColor
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, common.ErrInvalidColor
+	}
+	if len(hex) == 6 {
+		n = n<<8 | 0xff
+	}
+	return Color{uint8(n >> 24), uint8(n >> 16), uint8(n >> 8), uint8(n)}, nil
+}
+
+// parseRGB parses args, which is "r,g,b", and returns an opaque Color.
+// Each component must be a decimal number between 0 and 255.
+// Spaces around the components are ignored.
+//
+// Errors
+//
+// common.ErrInvalidColor:
+// Will be returned if args is not in the above format.
+//
+func parseRGB(args string) (Color, error) {
+	components := strings.Split(args, ",")
+	if len(components) != 3 {
+		return Color{}, common.ErrInvalidColor
+	}
+	var values [3]uint8
+	for i, component := range components {
+		n, err := strconv.ParseUint(strings.TrimSpace(component), 10, 8)
+		if err != nil {
+			return Color{}, common.ErrInvalidColor
+		}
+		values[i] = uint8(n)
+	}
+	return Color{values[0], values[1], values[2], 0xff}, nil
+}
@@ -0,0 +1,62 @@
+package rgba
+
+import (
+	"testing"
+
+	"github.com/asukakenji/drawing-challenge/color"
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+func TestParser_ParseColor(t *testing.T) {
+	parser := &Parser{Color{0xff, 0xff, 0xff, 0xff}}
+
+	// Positive Cases
+	casesPos := []struct {
+		s     string
+		color color.Color
+	}{
+		{"", Color{0xff, 0xff, 0xff, 0xff}},
+		{"#123456", Color{0x12, 0x34, 0x56, 0xff}},
+		{"#abcDEF", Color{0xab, 0xcd, 0xef, 0xff}},
+		{"#12345678", Color{0x12, 0x34, 0x56, 0x78}},
+		{"rgb(1,2,3)", Color{1, 2, 3, 0xff}},
+		{"RGB( 255 , 0 , 128 )", Color{0xff, 0x00, 0x80, 0xff}},
+		{"red", Color{0xff, 0x00, 0x00, 0xff}},
+		{"CornflowerBlue", Color{0x64, 0x95, 0xed, 0xff}},
+		{"rebeccapurple", Color{0x66, 0x33, 0x99, 0xff}},
+		{"transparent", Color{0x00, 0x00, 0x00, 0x00}},
+	}
+	for _, c := range casesPos {
+		color, err := parser.ParseColor(c.s)
+		if err != nil {
+			t.Errorf("Case: %s, Expected: err == nil, Got: %#v", c.s, err)
+		}
+		if color != c.color {
+			t.Errorf("Case: %s, Expected: %#v, Got: %#v", c.s, c.color, color)
+		}
+	}
+
+	// Negative Cases
+	casesNeg := []string{
+		"#",
+		"#12345",
+		"#1234567",
+		"#123456789",
+		"#12345g",
+		"#+12345",
+		"rgb(1,2)",
+		"rgb(1,2,3,4)",
+		"rgb(1,2,256)",
+		"rgb(1,2,-3)",
+		"rgb(1,2,a)",
+		"rgb(1,2,3",
+		"x",
+		"notacolor",
+	}
+	for _, s := range casesNeg {
+		_, err := parser.ParseColor(s)
+		if err != common.ErrInvalidColor {
+			t.Errorf("Case: %s, Expected: %#v, Got: %#v", s, common.ErrInvalidColor, err)
+		}
+	}
+}
@@ -9,6 +9,12 @@ import (
 	"github.com/asukakenji/drawing-challenge/command"
 )
 
+// Point represents a point used by the commands.
+type Point struct {
+	X int
+	Y int
+}
+
 // EmptyCommand represents a "No-op" command.
 // It implements the Command interface.
 type EmptyCommand struct {
@@ -27,13 +33,92 @@ type NewCanvasCommand struct {
 // Command is a dummy method to mark the type as implementing the Command interface.
 func (cmd NewCanvasCommand) Command() {}
 
+// CropCanvasCommand represents the "crop canvas" command.
+// It implements the Command interface.
+//
+// The canvas is cropped to the rectangle with corners (X1, Y1) and (X2, Y2).
+type CropCanvasCommand struct {
+	X1 int
+	Y1 int
+	X2 int
+	Y2 int
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd CropCanvasCommand) Command() {}
+
+// ExtendCanvasCommand represents the "extend canvas" command.
+// It implements the Command interface.
+//
+// The canvas is extended by the number of pixels on each side.
+type ExtendCanvasCommand struct {
+	Left   int
+	Top    int
+	Right  int
+	Bottom int
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd ExtendCanvasCommand) Command() {}
+
+// ScaleCanvasCommand represents the "scale canvas" command.
+// It implements the Command interface.
+type ScaleCanvasCommand struct {
+	Width  int
+	Height int
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd ScaleCanvasCommand) Command() {}
+
+// FlipCommand represents the "flip" command.
+// It implements the Command interface.
+//
+// The rectangle with corners (X1, Y1) and (X2, Y2) is flipped,
+// or the whole canvas if Whole is true, in which case they are ignored.
+// The top and bottom are swapped if Vertical is true,
+// otherwise the left and right are swapped.
+type FlipCommand struct {
+	Vertical bool
+	Whole    bool
+	X1       int
+	Y1       int
+	X2       int
+	Y2       int
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd FlipCommand) Command() {}
+
+// RotateCommand represents the "rotate" command.
+// It implements the Command interface.
+//
+// The rectangle with corners (X1, Y1) and (X2, Y2) is rotated clockwise
+// by Degrees, which is 90, 180, or 270,
+// or the whole canvas if Whole is true, in which case they are ignored.
+type RotateCommand struct {
+	Degrees int
+	Whole   bool
+	X1      int
+	Y1      int
+	X2      int
+	Y2      int
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd RotateCommand) Command() {}
+
 // DrawLineCommand represents the "draw line" command.
 // It implements the Command interface.
+//
+// The line is drawn with C,
+// or the foreground color of the canvas if C is nil.
 type DrawLineCommand struct {
 	X1 int
 	Y1 int
 	X2 int
 	Y2 int
+	C  color.Color
 }
 
 // Command is a dummy method to mark the type as implementing the Command interface.
@@ -41,27 +126,179 @@ func (cmd DrawLineCommand) Command() {}
 
 // DrawRectCommand represents the "draw rect" command.
 // It implements the Command interface.
+//
+// The rectangle is drawn with C,
+// or the foreground color of the canvas if C is nil.
 type DrawRectCommand struct {
 	X1 int
 	Y1 int
 	X2 int
 	Y2 int
+	C  color.Color
 }
 
 // Command is a dummy method to mark the type as implementing the Command interface.
 func (cmd DrawRectCommand) Command() {}
 
+// FillRectCommand represents the "fill rect" command.
+// It implements the Command interface.
+//
+// The interior of the rectangle is painted with Fill.
+// The border of the rectangle is drawn with Stroke,
+// or the foreground color of the canvas if Stroke is nil.
+type FillRectCommand struct {
+	X1     int
+	Y1     int
+	X2     int
+	Y2     int
+	Fill   color.Color
+	Stroke color.Color
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd FillRectCommand) Command() {}
+
+// DrawCircleCommand represents the "draw circle" command.
+// It implements the Command interface.
+type DrawCircleCommand struct {
+	X      int
+	Y      int
+	R      int
+	Filled bool
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd DrawCircleCommand) Command() {}
+
+// DrawEllipseCommand represents the "draw ellipse" command.
+// It implements the Command interface.
+type DrawEllipseCommand struct {
+	X      int
+	Y      int
+	RX     int
+	RY     int
+	Filled bool
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd DrawEllipseCommand) Command() {}
+
+// DrawPolylineCommand represents the "draw polyline" command.
+// It implements the Command interface.
+type DrawPolylineCommand struct {
+	Points []Point
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd DrawPolylineCommand) Command() {}
+
+// DrawPolygonCommand represents the "draw polygon" command.
+// It implements the Command interface.
+//
+// The interior of the polygon is painted if Filled is true.
+// The non-zero rule is used to determine the interior if NonZero is true.
+// Otherwise, the even-odd rule is used.
+type DrawPolygonCommand struct {
+	Points  []Point
+	Filled  bool
+	NonZero bool
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd DrawPolygonCommand) Command() {}
+
+// DrawTextCommand represents the "draw text" command.
+// It implements the Command interface.
+type DrawTextCommand struct {
+	X    int
+	Y    int
+	Text string
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd DrawTextCommand) Command() {}
+
 // BucketFillCommand represents the "bucket fill" command.
 // It implements the Command interface.
+//
+// By default, the area is 4-connected and the pixels must have exactly the
+// color of (X, Y). Diagonal makes the area 8-connected, Global replaces all
+// pixels of that color on the canvas, and Tolerance is the maximum color
+// distance of a pixel to be filled.
 type BucketFillCommand struct {
-	X int
-	Y int
-	C color.Color
+	X         int
+	Y         int
+	C         color.Color
+	Diagonal  bool
+	Global    bool
+	Tolerance int
 }
 
 // Command is a dummy method to mark the type as implementing the Command interface.
 func (cmd BucketFillCommand) Command() {}
 
+// CopyCommand represents the "copy" command.
+// It implements the Command interface.
+//
+// The rectangle with corners (X1, Y1) and (X2, Y2) is copied to the clipboard.
+type CopyCommand struct {
+	X1 int
+	Y1 int
+	X2 int
+	Y2 int
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd CopyCommand) Command() {}
+
+// PasteCommand represents the "paste" command.
+// It implements the Command interface.
+//
+// The content of the clipboard is pasted with the top-left corner at (X, Y).
+// The pixels in the color Transparent are not pasted, unless it is nil.
+type PasteCommand struct {
+	X           int
+	Y           int
+	Transparent color.Color
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd PasteCommand) Command() {}
+
+// SetForegroundColorCommand represents the "set foreground color" command.
+// It implements the Command interface.
+type SetForegroundColorCommand struct {
+	C color.Color
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd SetForegroundColorCommand) Command() {}
+
+// UndoCommand represents the "undo" command.
+// It implements the Command interface.
+type UndoCommand struct {
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd UndoCommand) Command() {}
+
+// RedoCommand represents the "redo" command.
+// It implements the Command interface.
+type RedoCommand struct {
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd RedoCommand) Command() {}
+
+// SaveCommand represents the "save" command.
+// It implements the Command interface.
+type SaveCommand struct {
+	FileName string
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd SaveCommand) Command() {}
+
 // QuitCommand represents the "quit" command.
 // It implements the Command interface.
 type QuitCommand struct {
@@ -70,12 +307,66 @@ type QuitCommand struct {
 // Command is a dummy method to mark the type as implementing the Command interface.
 func (cmd QuitCommand) Command() {}
 
+// StartMacroCommand represents the "start recording macro" command.
+// It implements the Command interface.
+//
+// The commands hereafter are recorded to the macro called Name,
+// until EndMacroCommand.
+type StartMacroCommand struct {
+	Name string
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd StartMacroCommand) Command() {}
+
+// EndMacroCommand represents the "end recording macro" command.
+// It implements the Command interface.
+type EndMacroCommand struct {
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd EndMacroCommand) Command() {}
+
+// ReplayMacroCommand represents the "replay macro" command.
+// It implements the Command interface.
+//
+// The commands recorded to the macro called Name are replayed,
+// with their coordinates translated by (DX, DY).
+type ReplayMacroCommand struct {
+	Name string
+	DX   int
+	DY   int
+}
+
+// Command is a dummy method to mark the type as implementing the Command interface.
+func (cmd ReplayMacroCommand) Command() {}
+
 // Ensure that the command types implement the command.Command interface.
 var (
 	_ command.Command = EmptyCommand{}
 	_ command.Command = NewCanvasCommand{}
+	_ command.Command = CropCanvasCommand{}
+	_ command.Command = ExtendCanvasCommand{}
+	_ command.Command = ScaleCanvasCommand{}
+	_ command.Command = FlipCommand{}
+	_ command.Command = RotateCommand{}
 	_ command.Command = DrawLineCommand{}
 	_ command.Command = DrawRectCommand{}
+	_ command.Command = FillRectCommand{}
+	_ command.Command = DrawCircleCommand{}
+	_ command.Command = DrawEllipseCommand{}
+	_ command.Command = DrawPolylineCommand{}
+	_ command.Command = DrawPolygonCommand{}
+	_ command.Command = DrawTextCommand{}
 	_ command.Command = BucketFillCommand{}
+	_ command.Command = CopyCommand{}
+	_ command.Command = PasteCommand{}
+	_ command.Command = SetForegroundColorCommand{}
+	_ command.Command = UndoCommand{}
+	_ command.Command = RedoCommand{}
+	_ command.Command = SaveCommand{}
 	_ command.Command = QuitCommand{}
+	_ command.Command = StartMacroCommand{}
+	_ command.Command = EndMacroCommand{}
+	_ command.Command = ReplayMacroCommand{}
 )
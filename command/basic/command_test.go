@@ -14,10 +14,30 @@ func TestCommand(t *testing.T) {
 	}{
 		{EmptyCommand{}},
 		{NewCanvasCommand{}},
+		{CropCanvasCommand{}},
+		{ExtendCanvasCommand{}},
+		{ScaleCanvasCommand{}},
+		{FlipCommand{}},
+		{RotateCommand{}},
 		{DrawLineCommand{}},
 		{DrawRectCommand{}},
+		{FillRectCommand{}},
+		{DrawCircleCommand{}},
+		{DrawEllipseCommand{}},
+		{DrawPolylineCommand{}},
+		{DrawPolygonCommand{}},
+		{DrawTextCommand{}},
 		{BucketFillCommand{}},
+		{CopyCommand{}},
+		{PasteCommand{}},
+		{SetForegroundColorCommand{}},
+		{UndoCommand{}},
+		{RedoCommand{}},
+		{SaveCommand{}},
 		{QuitCommand{}},
+		{StartMacroCommand{}},
+		{EndMacroCommand{}},
+		{ReplayMacroCommand{}},
 	}
 	for _, c := range cases {
 		c.cmd.Command()
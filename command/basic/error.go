@@ -0,0 +1,63 @@
+package basic
+
+// ParseError is the error returned by Parser when a command could not be parsed.
+// It records where the error occurs in the parsed string,
+// and wraps one of the errors defined in package common,
+// so that it could still be tested through Err, like:
+//
+//     perr, ok := err.(*ParseError)
+//     if ok && perr.Err == common.ErrInvalidNumber {
+//         ...
+//     }
+//
+type ParseError struct {
+	// Command is the command, like "L".
+	Command string
+
+	// Index is the zero-based index of the argument causing the error,
+	// or -1 if the error is caused by the command as a whole,
+	// like common.ErrUnknownCommand and common.ErrInvalidArgumentCount.
+	Index int
+
+	// Offset is the byte offset of Token in the parsed string.
+	Offset int
+
+	// Token is the argument causing the error,
+	// or the command if Index is -1.
+	Token string
+
+	// Err is the wrapped error.
+	Err error
+}
+
+// Error returns the message of the wrapped error.
+func (err *ParseError) Error() string {
+	return err.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (err *ParseError) Unwrap() error {
+	return err.Err
+}
+
+// newParseError returns a new ParseError caused by the argument at index
+// of tokens, or by the command if index is -1. tokens are those of s.
+// If the argument is omitted, Token is empty,
+// and Offset is the end of the last token.
+func newParseError(s string, tokens []Token, index int, err error) *ParseError {
+	perr := &ParseError{
+		Index: index,
+		Err:   err,
+	}
+	if len(tokens) != 0 {
+		perr.Command = tokens[0].Text
+	}
+	if index+1 < len(tokens) {
+		t := tokens[index+1]
+		perr.Offset = t.Start
+		perr.Token = s[t.Start:t.End]
+	} else if len(tokens) != 0 {
+		perr.Offset = tokens[len(tokens)-1].End
+	}
+	return perr
+}
@@ -0,0 +1,167 @@
+package basic
+
+import (
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+// Token is a word in a command string.
+type Token struct {
+	// Text is the content of the token, unquoted if it is quoted.
+	Text string
+
+	// Start and End are the byte offsets of the token in the command string,
+	// including the quotes if it is quoted.
+	Start int
+	End   int
+
+	// Quoted is whether the token is quoted.
+	Quoted bool
+}
+
+// isSpaceAt returns whether s contains whitespace at offset i,
+// or i is the end of s.
+func isSpaceAt(s string, i int) bool {
+	if i >= len(s) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return unicode.IsSpace(r)
+}
+
+// tokenize splits s into tokens.
+//
+// Tokens are separated by any amount of whitespace, including "\t" and "\r".
+// A token starting with a double quote is a quoted string, which ends at the
+// next unescaped double quote. The escape sequences of Go string literals are
+// supported in a quoted string. A double quote not starting a terminated
+// quoted string, and followed by whitespace or the end of s, is a token by
+// itself, so that it could be used as a color.
+//
+// A "#" starts a comment extending to the end of s, if it is the first
+// non-whitespace character in s, or if it is followed by whitespace or the
+// end of s, unless expectsColor (if not nil) returns true for the tokens
+// before it. Otherwise, like "#ff0000", it is a part of a token.
+//
+// Errors
+//
+// common.ErrInvalidString:
+// Will be returned if a quoted string could not be unquoted,
+// or if it is not followed by whitespace.
+// The tokens before the invalid one are returned, followed by the invalid one,
+// which extends to the end of s.
+//
+func tokenize(s string, expectsColor func([]Token) bool) ([]Token, error) {
+	var tokens []Token
+	i := 0
+	for {
+		// Skip whitespace
+		for i < len(s) && isSpaceAt(s, i) {
+			_, size := utf8.DecodeRuneInString(s[i:])
+			i += size
+		}
+		if i >= len(s) {
+			return tokens, nil
+		}
+		// Skip comment
+		if s[i] == '#' && (len(tokens) == 0 || isSpaceAt(s, i+1)) {
+			if len(tokens) == 0 || expectsColor == nil || !expectsColor(tokens) {
+				return tokens, nil
+			}
+		}
+		start := i
+		if s[i] == '"' {
+			end := quotedEnd(s, i)
+			if end < 0 && isSpaceAt(s, i+1) {
+				tokens = append(tokens, Token{s[i : i+1], i, i + 1, false})
+				i++
+				continue
+			}
+			if end < 0 || !isSpaceAt(s, end) {
+				return append(tokens, Token{s[start:], start, len(s), true}), common.ErrInvalidString
+			}
+			text, err := strconv.Unquote(s[start:end])
+			if err != nil {
+				return append(tokens, Token{s[start:], start, len(s), true}), common.ErrInvalidString
+			}
+			tokens = append(tokens, Token{text, start, end, true})
+			i = end
+			continue
+		}
+		for i < len(s) && !isSpaceAt(s, i) {
+			_, size := utf8.DecodeRuneInString(s[i:])
+			i += size
+		}
+		tokens = append(tokens, Token{s[start:i], start, i, false})
+	}
+}
+
+// Tokenize splits s into tokens, in the same way as Parser.ParseCommand,
+// so that other parsers could recognize the quoted strings and the comments
+// of a command string before it is parsed.
+//
+// Errors
+//
+// common.ErrInvalidString:
+// Will be returned if a quoted string could not be unquoted,
+// or if it is not followed by whitespace.
+// The tokens before the invalid one are returned, followed by the invalid one,
+// which extends to the end of s.
+//
+func Tokenize(s string) ([]Token, error) {
+	return tokenize(s, expectsColor)
+}
+
+// quotedEnd returns the byte offset just after the closing double quote
+// of the quoted string starting at offset start of s,
+// or -1 if the quoted string is not terminated.
+func quotedEnd(s string, start int) int {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			// NOTE: The escaped character is skipped
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// texts returns the texts of tokens.
+func texts(tokens []Token) []string {
+	ss := make([]string, len(tokens))
+	for i, t := range tokens {
+		ss[i] = t.Text
+	}
+	return ss
+}
+
+// joinRest returns the text of tokens, which are the last tokens of s
+// and may contain whitespace between them.
+// A quoted token is unquoted if it is the only one,
+// while unquoted tokens are joined with the original whitespace between them.
+//
+// Errors
+//
+// common.ErrInvalidString:
+// Will be returned if a quoted token is not the only one.
+// The index of the token which should not be there is returned.
+//
+func joinRest(s string, tokens []Token) (string, int, error) {
+	if len(tokens) == 1 {
+		return tokens[0].Text, -1, nil
+	}
+	for i, t := range tokens {
+		if t.Quoted {
+			if i == 0 {
+				return "", 1, common.ErrInvalidString
+			}
+			return "", i, common.ErrInvalidString
+		}
+	}
+	return s[tokens[0].Start:tokens[len(tokens)-1].End], -1, nil
+}
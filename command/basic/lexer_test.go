@@ -0,0 +1,86 @@
+package basic
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+func TestTokenize(t *testing.T) {
+	// Positive Cases
+	casesPos := []struct {
+		s      string
+		tokens []Token
+	}{
+		{"", nil},
+		{" \t\r\n", nil},
+		{"# comment", nil},
+		{"  #comment", nil},
+		{"C 20 4", []Token{{"C", 0, 1, false}, {"20", 2, 4, false}, {"4", 5, 6, false}}},
+		{"\tC  20\t4\r", []Token{{"C", 1, 2, false}, {"20", 4, 6, false}, {"4", 7, 8, false}}},
+		{"FG #ff0000 # red", []Token{{"FG", 0, 2, false}, {"#ff0000", 3, 10, false}}},
+		{"FG #", []Token{{"FG", 0, 2, false}}},
+		{`T 1 1 "a \"b\"\tc"`, []Token{{"T", 0, 1, false}, {"1", 2, 3, false}, {"1", 4, 5, false}, {"a \"b\"\tc", 6, 18, true}}},
+		{`T 1 1 ""`, []Token{{"T", 0, 1, false}, {"1", 2, 3, false}, {"1", 4, 5, false}, {"", 6, 8, true}}},
+		{`T 1 1 "#" #`, []Token{{"T", 0, 1, false}, {"1", 2, 3, false}, {"1", 4, 5, false}, {"#", 6, 9, true}}},
+		{"T 1 1 日本 語", []Token{{"T", 0, 1, false}, {"1", 2, 3, false}, {"1", 4, 5, false}, {"日本", 6, 12, false}, {"語", 13, 16, false}}},
+		{"T 1 1 a\"b", []Token{{"T", 0, 1, false}, {"1", 2, 3, false}, {"1", 4, 5, false}, {"a\"b", 6, 9, false}}},
+		{`"`, []Token{{`"`, 0, 1, false}}},
+		{`FG " `, []Token{{"FG", 0, 2, false}, {`"`, 3, 4, false}}},
+		{`T 1 1 " "`, []Token{{"T", 0, 1, false}, {"1", 2, 3, false}, {"1", 4, 5, false}, {" ", 6, 9, true}}},
+	}
+	for _, c := range casesPos {
+		tokens, err := tokenize(c.s, nil)
+		if err != nil {
+			t.Errorf("Case: %q, Expected: err == nil, Got: %#v", c.s, err)
+		}
+		if !reflect.DeepEqual(tokens, c.tokens) {
+			t.Errorf("Case: %q, Expected: %#v, Got: %#v", c.s, c.tokens, tokens)
+		}
+	}
+
+	// Negative Cases
+	casesNeg := []struct {
+		s      string
+		tokens []Token
+	}{
+		{`T "unterminated \"`, []Token{{"T", 0, 1, false}, {`"unterminated \"`, 2, 18, true}}},
+		{`T "text"trailing x`, []Token{{"T", 0, 1, false}, {`"text"trailing x`, 2, 18, true}}},
+		{`T "\q"`, []Token{{"T", 0, 1, false}, {`"\q"`, 2, 6, true}}},
+	}
+	for _, c := range casesNeg {
+		tokens, err := tokenize(c.s, nil)
+		if err != common.ErrInvalidString {
+			t.Errorf("Case: %q, Expected: %#v, Got: %#v", c.s, common.ErrInvalidString, err)
+		}
+		if !reflect.DeepEqual(tokens, c.tokens) {
+			t.Errorf("Case: %q, Expected: %#v, Got: %#v", c.s, c.tokens, tokens)
+		}
+	}
+}
+
+func TestTokenize_ExpectsColor(t *testing.T) {
+	cases := []struct {
+		s      string
+		tokens []Token
+	}{
+		{"# comment", nil},
+		{"FG #", []Token{{"FG", 0, 2, false}, {"#", 3, 4, false}}},
+		{"FG # # comment", []Token{{"FG", 0, 2, false}, {"#", 3, 4, false}}},
+		{"B 1 2 #", []Token{{"B", 0, 1, false}, {"1", 2, 3, false}, {"2", 4, 5, false}, {"#", 6, 7, false}}},
+		{"B N8 1 2 #", []Token{{"B", 0, 1, false}, {"N8", 2, 4, false}, {"1", 5, 6, false}, {"2", 7, 8, false}, {"#", 9, 10, false}}},
+		{"B 1 # 2", []Token{{"B", 0, 1, false}, {"1", 2, 3, false}}},
+		{"FR 1 2 3 4 # # #", []Token{{"FR", 0, 2, false}, {"1", 3, 4, false}, {"2", 5, 6, false}, {"3", 7, 8, false}, {"4", 9, 10, false}, {"#", 11, 12, false}, {"#", 13, 14, false}}},
+		{"C 1 2 #", []Token{{"C", 0, 1, false}, {"1", 2, 3, false}, {"2", 4, 5, false}}},
+	}
+	for _, c := range cases {
+		tokens, err := tokenize(c.s, expectsColor)
+		if err != nil {
+			t.Errorf("Case: %q, Expected: err == nil, Got: %#v", c.s, err)
+		}
+		if !reflect.DeepEqual(tokens, c.tokens) {
+			t.Errorf("Case: %q, Expected: %#v, Got: %#v", c.s, c.tokens, tokens)
+		}
+	}
+}
@@ -15,9 +15,29 @@ import (
 // Commands supported by this parser:
 // EmptyCommand,
 // NewCanvasCommand,
+// CropCanvasCommand,
+// ExtendCanvasCommand,
+// ScaleCanvasCommand,
+// FlipCommand,
+// RotateCommand,
 // DrawLineCommand,
 // DrawRectCommand,
+// FillRectCommand,
+// DrawCircleCommand,
+// DrawEllipseCommand,
+// DrawPolylineCommand,
+// DrawPolygonCommand,
+// DrawTextCommand,
 // BucketFillCommand,
+// CopyCommand,
+// PasteCommand,
+// SetForegroundColorCommand,
+// UndoCommand,
+// RedoCommand,
+// SaveCommand,
+// StartMacroCommand,
+// EndMacroCommand,
+// ReplayMacroCommand,
 // QuitCommand.
 //
 type Parser struct {
@@ -45,8 +65,79 @@ func NewParser(parseColorFunc func(string) (color.Color, error)) (*Parser, error
 	}, nil
 }
 
+// parseNumbers parses each of args as a number.
+// The index of the argument which could not be parsed is returned on errors.
+//
+// Errors
+//
+// common.ErrInvalidNumber:
+// Will be returned if any of args could not be parsed as a valid number.
+//
+func parseNumbers(args []string) ([]int, int, error) {
+	ns := make([]int, len(args))
+	for i, arg := range args {
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, i, common.ErrInvalidNumber
+		}
+		ns[i] = n
+	}
+	return ns, -1, nil
+}
+
+// toPoints groups ns into pairs of coordinates.
+// len(ns) must be even.
+func toPoints(ns []int) []Point {
+	points := make([]Point, len(ns)/2)
+	for i := range points {
+		points[i] = Point{ns[2*i], ns[2*i+1]}
+	}
+	return points
+}
+
+// isFillMode returns whether arg is one of the fill modes
+// of the "bucket fill" command, like "N8" or "T=16".
+func isFillMode(arg string) bool {
+	return arg == "N4" || arg == "N8" || arg == "ALL" || strings.HasPrefix(arg, "T=")
+}
+
+// expectsColor returns whether the argument following tokens,
+// which are the first tokens of a command, could be a color.
+// It is used by tokenize to tell the color "#" from the start of a comment.
+func expectsColor(tokens []Token) bool {
+	args := texts(tokens[1:])
+	switch tokens[0].Text {
+	case "L", "R":
+		return len(args) == 4
+	case "FR":
+		return len(args) == 4 || len(args) == 5
+	case "B":
+		for len(args) > 0 && isFillMode(args[0]) {
+			args = args[1:]
+		}
+		return len(args) == 2
+	case "PA":
+		return len(args) == 2
+	case "FG":
+		return len(args) == 0
+	default:
+		return false
+	}
+}
+
 // ParseCommand parses the string s and returns a command.Command.
 //
+// The command and the arguments are separated by any amount of whitespace.
+// An argument could be quoted in double quotes, with the escape sequences of
+// Go string literals supported. A "#" starts a comment extending to the end
+// of s, if it is the first non-whitespace character in s, or if it is followed
+// by whitespace or the end of s where no color is expected. Where a color is
+// expected, like "B 1 1 #", it is the color instead. A string containing only whitespace and
+// comments is parsed to EmptyCommand. The text of the "draw text" command and
+// the file name of the "save" command are either a single quoted argument,
+// or the unquoted arguments with the original whitespace between them.
+// The errors returned are of type *ParseError, wrapping the errors below.
+//
 // Errors
 //
 // common.ErrUnknownCommand:
@@ -66,81 +157,230 @@ func NewParser(parseColorFunc func(string) (color.Color, error)) (*Parser, error
 // Will be returned when a color argument is expected,
 // but it could not be parsed as a valid color.
 //
+// common.ErrInvalidString:
+// Will be returned when a quoted string argument is expected,
+// but it could not be parsed as a valid string.
+//
 func (parser *Parser) ParseCommand(s string) (command.Command, error) {
-	if s == "" {
+	tokens, err := Tokenize(s)
+	fail := func(index int, err error) (command.Command, error) {
+		return nil, newParseError(s, tokens, index, err)
+	}
+	if err != nil {
+		return fail(len(tokens)-2, err)
+	}
+	if len(tokens) == 0 {
 		return EmptyCommand{}, nil
 	}
-	words := strings.Split(s, " ")
+	words := texts(tokens)
 	switch command, args := words[0], words[1:]; command {
 	case "C":
 		if len(args) != 2 {
-			return nil, common.ErrInvalidArgumentCount
+			return fail(-1, common.ErrInvalidArgumentCount)
 		}
-		w, err := strconv.Atoi(args[0])
+		ns, i, err := parseNumbers(args)
 		if err != nil {
-			return nil, common.ErrInvalidNumber
+			return fail(i, err)
 		}
-		h, err := strconv.Atoi(args[1])
+		return NewCanvasCommand{ns[0], ns[1]}, nil
+	case "CR", "EX":
+		if len(args) != 4 {
+			return fail(-1, common.ErrInvalidArgumentCount)
+		}
+		ns, i, err := parseNumbers(args)
 		if err != nil {
-			return nil, common.ErrInvalidNumber
+			return fail(i, err)
 		}
-		return NewCanvasCommand{w, h}, nil
-	case "L":
-		if len(args) != 4 {
-			return nil, common.ErrInvalidArgumentCount
+		if command == "CR" {
+			return CropCanvasCommand{ns[0], ns[1], ns[2], ns[3]}, nil
 		}
-		x1, err := strconv.Atoi(args[0])
+		return ExtendCanvasCommand{ns[0], ns[1], ns[2], ns[3]}, nil
+	case "SC":
+		if len(args) != 2 {
+			return fail(-1, common.ErrInvalidArgumentCount)
+		}
+		ns, i, err := parseNumbers(args)
 		if err != nil {
-			return nil, common.ErrInvalidNumber
+			return fail(i, err)
+		}
+		return ScaleCanvasCommand{ns[0], ns[1]}, nil
+	case "FH", "FV":
+		// The rectangle is optional
+		switch len(args) {
+		case 0, 4:
+			// OK
+		default:
+			return fail(-1, common.ErrInvalidArgumentCount)
 		}
-		y1, err := strconv.Atoi(args[1])
+		ns, i, err := parseNumbers(args)
 		if err != nil {
-			return nil, common.ErrInvalidNumber
+			return fail(i, err)
+		}
+		whole := len(ns) == 0
+		ns = append(ns, 0, 0, 0, 0)
+		return FlipCommand{command == "FV", whole, ns[0], ns[1], ns[2], ns[3]}, nil
+	case "RO":
+		// The rectangle is optional
+		switch len(args) {
+		case 1, 5:
+			// OK
+		default:
+			return fail(-1, common.ErrInvalidArgumentCount)
 		}
-		x2, err := strconv.Atoi(args[2])
+		ns, i, err := parseNumbers(args)
 		if err != nil {
-			return nil, common.ErrInvalidNumber
+			return fail(i, err)
+		}
+		switch ns[0] {
+		case 90, 180, 270:
+			// OK
+		default:
+			return fail(0, common.ErrInvalidAngle)
+		}
+		whole := len(ns) == 1
+		ns = append(ns, 0, 0, 0, 0)
+		return RotateCommand{ns[0], whole, ns[1], ns[2], ns[3], ns[4]}, nil
+	case "L", "R":
+		switch len(args) {
+		case 4, 5:
+			// OK
+		default:
+			return fail(-1, common.ErrInvalidArgumentCount)
 		}
-		y2, err := strconv.Atoi(args[3])
+		ns, i, err := parseNumbers(args[:4])
 		if err != nil {
-			return nil, common.ErrInvalidNumber
+			return fail(i, err)
+		}
+		var c color.Color
+		if len(args) == 5 {
+			c, err = parser.parseColorFunc(args[4])
+			if err != nil {
+				return fail(4, err)
+			}
 		}
-		return DrawLineCommand{x1, y1, x2, y2}, nil
-	case "R":
+		if command == "L" {
+			return DrawLineCommand{ns[0], ns[1], ns[2], ns[3], c}, nil
+		}
+		return DrawRectCommand{ns[0], ns[1], ns[2], ns[3], c}, nil
+	case "FR":
+		switch len(args) {
+		case 4, 5, 6:
+			// OK
+		default:
+			return fail(-1, common.ErrInvalidArgumentCount)
+		}
+		ns, i, err := parseNumbers(args[:4])
+		if err != nil {
+			return fail(i, err)
+		}
+		var fillString string
+		if len(args) >= 5 {
+			fillString = args[4]
+		}
+		fill, err := parser.parseColorFunc(fillString)
+		if err != nil {
+			return fail(4, err)
+		}
+		var stroke color.Color
+		if len(args) == 6 {
+			stroke, err = parser.parseColorFunc(args[5])
+			if err != nil {
+				return fail(5, err)
+			}
+		}
+		return FillRectCommand{ns[0], ns[1], ns[2], ns[3], fill, stroke}, nil
+	case "O", "FO":
+		if len(args) != 3 {
+			return fail(-1, common.ErrInvalidArgumentCount)
+		}
+		ns, i, err := parseNumbers(args)
+		if err != nil {
+			return fail(i, err)
+		}
+		return DrawCircleCommand{ns[0], ns[1], ns[2], command == "FO"}, nil
+	case "E", "FE":
 		if len(args) != 4 {
-			return nil, common.ErrInvalidArgumentCount
+			return fail(-1, common.ErrInvalidArgumentCount)
+		}
+		ns, i, err := parseNumbers(args)
+		if err != nil {
+			return fail(i, err)
+		}
+		return DrawEllipseCommand{ns[0], ns[1], ns[2], ns[3], command == "FE"}, nil
+	case "P":
+		if len(args) < 4 || len(args)%2 != 0 {
+			return fail(-1, common.ErrInvalidArgumentCount)
 		}
-		x1, err := strconv.Atoi(args[0])
+		ns, i, err := parseNumbers(args)
 		if err != nil {
-			return nil, common.ErrInvalidNumber
+			return fail(i, err)
+		}
+		return DrawPolylineCommand{toPoints(ns)}, nil
+	case "PG", "FP":
+		// The fill rule ("EO" for even-odd, "NZ" for non-zero) may precede the points
+		nonZero := false
+		skipped := 0
+		if len(args)%2 != 0 && (args[0] == "EO" || args[0] == "NZ") {
+			nonZero = args[0] == "NZ"
+			args = args[1:]
+			skipped = 1
 		}
-		y1, err := strconv.Atoi(args[1])
+		if len(args) < 6 || len(args)%2 != 0 {
+			return fail(-1, common.ErrInvalidArgumentCount)
+		}
+		ns, i, err := parseNumbers(args)
 		if err != nil {
-			return nil, common.ErrInvalidNumber
+			return fail(skipped+i, err)
+		}
+		return DrawPolygonCommand{toPoints(ns), command == "FP", nonZero}, nil
+	case "T":
+		// The text may contain whitespace, so the remaining tokens are joined
+		if len(args) < 3 {
+			return fail(-1, common.ErrInvalidArgumentCount)
 		}
-		x2, err := strconv.Atoi(args[2])
+		ns, i, err := parseNumbers(args[:2])
 		if err != nil {
-			return nil, common.ErrInvalidNumber
+			return fail(i, err)
 		}
-		y2, err := strconv.Atoi(args[3])
+		text, i, err := joinRest(s, tokens[3:])
 		if err != nil {
-			return nil, common.ErrInvalidNumber
+			return fail(2+i, err)
 		}
-		return DrawRectCommand{x1, y1, x2, y2}, nil
+		return DrawTextCommand{ns[0], ns[1], text}, nil
 	case "B":
+		// The fill modes ("N4", "N8", "ALL", "T=n") may precede the point
+		diagonal, global, tolerance := false, false, 0
+		skipped := 0
+	flags:
+		for len(args) > 0 {
+			switch {
+			case args[0] == "N4":
+				diagonal = false
+			case args[0] == "N8":
+				diagonal = true
+			case args[0] == "ALL":
+				global = true
+			case strings.HasPrefix(args[0], "T="):
+				n, err := strconv.Atoi(args[0][2:])
+				if err != nil {
+					return fail(skipped, common.ErrInvalidNumber)
+				}
+				tolerance = n
+			default:
+				break flags
+			}
+			args = args[1:]
+			skipped++
+		}
 		switch len(args) {
 		case 2, 3:
 			// OK
 		default:
-			return nil, common.ErrInvalidArgumentCount
+			return fail(-1, common.ErrInvalidArgumentCount)
 		}
-		x, err := strconv.Atoi(args[0])
+		ns, i, err := parseNumbers(args[:2])
 		if err != nil {
-			return nil, common.ErrInvalidNumber
-		}
-		y, err := strconv.Atoi(args[1])
-		if err != nil {
-			return nil, common.ErrInvalidNumber
+			return fail(skipped+i, err)
 		}
 		var colorString string
 		if len(args) == 3 {
@@ -148,12 +388,89 @@ func (parser *Parser) ParseCommand(s string) (command.Command, error) {
 		}
 		c, err := parser.parseColorFunc(colorString)
 		if err != nil {
-			return nil, err
+			return fail(skipped+2, err)
+		}
+		return BucketFillCommand{ns[0], ns[1], c, diagonal, global, tolerance}, nil
+	case "CP":
+		if len(args) != 4 {
+			return fail(-1, common.ErrInvalidArgumentCount)
+		}
+		ns, i, err := parseNumbers(args)
+		if err != nil {
+			return fail(i, err)
+		}
+		return CopyCommand{ns[0], ns[1], ns[2], ns[3]}, nil
+	case "PA":
+		// The transparent color is optional
+		switch len(args) {
+		case 2, 3:
+			// OK
+		default:
+			return fail(-1, common.ErrInvalidArgumentCount)
+		}
+		ns, i, err := parseNumbers(args[:2])
+		if err != nil {
+			return fail(i, err)
+		}
+		var transparent color.Color
+		if len(args) == 3 {
+			transparent, err = parser.parseColorFunc(args[2])
+			if err != nil {
+				return fail(2, err)
+			}
+		}
+		return PasteCommand{ns[0], ns[1], transparent}, nil
+	case "FG":
+		if len(args) != 1 {
+			return fail(-1, common.ErrInvalidArgumentCount)
+		}
+		c, err := parser.parseColorFunc(args[0])
+		if err != nil {
+			return fail(0, err)
+		}
+		return SetForegroundColorCommand{c}, nil
+	case "U":
+		return UndoCommand{}, nil
+	case "Y":
+		return RedoCommand{}, nil
+	case "S":
+		// The file name may contain whitespace, so the remaining tokens are joined
+		if len(args) < 1 {
+			return fail(-1, common.ErrInvalidArgumentCount)
+		}
+		fileName, i, err := joinRest(s, tokens[1:])
+		if err != nil {
+			return fail(i, err)
+		}
+		return SaveCommand{fileName}, nil
+	case "MR":
+		if len(args) != 1 {
+			return fail(-1, common.ErrInvalidArgumentCount)
+		}
+		return StartMacroCommand{args[0]}, nil
+	case "ME":
+		if len(args) != 0 {
+			return fail(-1, common.ErrInvalidArgumentCount)
+		}
+		return EndMacroCommand{}, nil
+	case "M":
+		switch len(args) {
+		case 1, 3:
+			// OK
+		default:
+			return fail(-1, common.ErrInvalidArgumentCount)
+		}
+		ns, i, err := parseNumbers(args[1:])
+		if err != nil {
+			return fail(1+i, err)
+		}
+		if len(ns) == 0 {
+			return ReplayMacroCommand{args[0], 0, 0}, nil
 		}
-		return BucketFillCommand{x, y, c}, nil
+		return ReplayMacroCommand{args[0], ns[0], ns[1]}, nil
 	case "Q":
 		return QuitCommand{}, nil
 	default:
-		return nil, common.ErrUnknownCommand
+		return fail(-1, common.ErrUnknownCommand)
 	}
 }
@@ -40,12 +40,78 @@ func TestBasicParser_ParseCommand(t *testing.T) {
 		command command.Command
 	}{
 		{"", EmptyCommand{}},
-		{"C 20 4", NewCanvasCommand{20, 4}},                          // Example 1
-		{"L 1 2 6 2", DrawLineCommand{1, 2, 6, 2}},                   // Example 2
-		{"L 6 3 6 4", DrawLineCommand{6, 3, 6, 4}},                   // Example 3
-		{"R 14 1 18 3", DrawRectCommand{14, 1, 18, 3}},               // Example 4
-		{"B 10 3 o", BucketFillCommand{10, 3, bytecolor.Color('o')}}, // Example 5
-		{"Q", QuitCommand{}},                                         // Example 6
+		{"C 20 4", NewCanvasCommand{20, 4}},                                           // Example 1
+		{"L 1 2 6 2", DrawLineCommand{1, 2, 6, 2, nil}},                               // Example 2
+		{"L 6 3 6 4", DrawLineCommand{6, 3, 6, 4, nil}},                               // Example 3
+		{"L 1 1 5 3", DrawLineCommand{1, 1, 5, 3, nil}},                               // Diagonal
+		{"R 14 1 18 3", DrawRectCommand{14, 1, 18, 3, nil}},                           // Example 4
+		{"B 10 3 o", BucketFillCommand{10, 3, bytecolor.Color('o'), false, false, 0}}, // Example 5
+		{"Q", QuitCommand{}},                                                          // Example 6
+		{"L 1 1 5 3 R", DrawLineCommand{1, 1, 5, 3, bytecolor.Color('R')}},
+		{"R 14 1 18 3 G", DrawRectCommand{14, 1, 18, 3, bytecolor.Color('G')}},
+		{"FG B", SetForegroundColorCommand{bytecolor.Color('B')}},
+		{"T 1 2 label", DrawTextCommand{1, 2, "label"}},
+		{"T 1 2 two words", DrawTextCommand{1, 2, "two words"}},
+		{`T 1 2 "quoted \"text\""`, DrawTextCommand{1, 2, `quoted "text"`}},
+		{`T 1 2 ""`, DrawTextCommand{1, 2, ""}},
+		{"U", UndoCommand{}},
+		{"Y", RedoCommand{}},
+		{"S screen1.png", SaveCommand{"screen1.png"}},
+		{"S my screen.png", SaveCommand{"my screen.png"}},
+		{`S "screen\t1.png"`, SaveCommand{"screen\t1.png"}},
+		{"FR 14 1 18 3", FillRectCommand{14, 1, 18, 3, bytecolor.Color(' '), nil}},
+		{"FR 14 1 18 3 o", FillRectCommand{14, 1, 18, 3, bytecolor.Color('o'), nil}},
+		{"FR 14 1 18 3 o x", FillRectCommand{14, 1, 18, 3, bytecolor.Color('o'), bytecolor.Color('x')}},
+		{"O 10 3 2", DrawCircleCommand{10, 3, 2, false}},
+		{"FO 10 3 2", DrawCircleCommand{10, 3, 2, true}},
+		{"E 10 3 4 2", DrawEllipseCommand{10, 3, 4, 2, false}},
+		{"FE 10 3 4 2", DrawEllipseCommand{10, 3, 4, 2, true}},
+		{"P 1 1 5 3", DrawPolylineCommand{[]Point{{1, 1}, {5, 3}}}},
+		{"P 1 1 5 3 1 4", DrawPolylineCommand{[]Point{{1, 1}, {5, 3}, {1, 4}}}},
+		{"PG 1 1 5 3 1 4", DrawPolygonCommand{[]Point{{1, 1}, {5, 3}, {1, 4}}, false, false}},
+		{"FP 1 1 5 3 1 4", DrawPolygonCommand{[]Point{{1, 1}, {5, 3}, {1, 4}}, true, false}},
+		{"FP EO 1 1 5 3 1 4", DrawPolygonCommand{[]Point{{1, 1}, {5, 3}, {1, 4}}, true, false}},
+		{"FP NZ 1 1 5 3 1 4", DrawPolygonCommand{[]Point{{1, 1}, {5, 3}, {1, 4}}, true, true}},
+		{"   ", EmptyCommand{}},
+		{"# comment", EmptyCommand{}},
+		{"C  20\t4\r", NewCanvasCommand{20, 4}},
+		{" L 1 2 6 2 ", DrawLineCommand{1, 2, 6, 2, nil}},
+		{"L 1 2 6 2 x # comment", DrawLineCommand{1, 2, 6, 2, bytecolor.Color('x')}},
+		{"L 1 2 6 2 #", DrawLineCommand{1, 2, 6, 2, bytecolor.Color('#')}},
+		{"B 1 2 #", BucketFillCommand{1, 2, bytecolor.Color('#'), false, false, 0}},
+		{"B 1 2 # # comment", BucketFillCommand{1, 2, bytecolor.Color('#'), false, false, 0}},
+		{"FR 1 2 3 4 # #", FillRectCommand{1, 2, 3, 4, bytecolor.Color('#'), bytecolor.Color('#')}},
+		{"FG #", SetForegroundColorCommand{bytecolor.Color('#')}},
+		{"FG \"", SetForegroundColorCommand{bytecolor.Color('"')}},
+		{"PA 5 6 #", PasteCommand{5, 6, bytecolor.Color('#')}},
+		{"L 1 2 6 2 \"#\"", DrawLineCommand{1, 2, 6, 2, bytecolor.Color('#')}},
+		{"C 20 4 # 5", NewCanvasCommand{20, 4}},
+		{"CR 2 1 19 3", CropCanvasCommand{2, 1, 19, 3}},
+		{"EX 1 0 2 3", ExtendCanvasCommand{1, 0, 2, 3}},
+		{"SC 40 8", ScaleCanvasCommand{40, 8}},
+		{"FH", FlipCommand{false, true, 0, 0, 0, 0}},
+		{"FV 1 2 3 4", FlipCommand{true, false, 1, 2, 3, 4}},
+		{"FV 0 0 0 0", FlipCommand{true, false, 0, 0, 0, 0}},
+		{"RO 270", RotateCommand{270, true, 0, 0, 0, 0}},
+		{"RO 90 1 2 3 4", RotateCommand{90, false, 1, 2, 3, 4}},
+		{"B 1 2 \"o\"", BucketFillCommand{1, 2, bytecolor.Color('o'), false, false, 0}},
+		{"B N8 1 2 o", BucketFillCommand{1, 2, bytecolor.Color('o'), true, false, 0}},
+		{"B N4 ALL 1 2", BucketFillCommand{1, 2, bytecolor.Color(' '), false, true, 0}},
+		{"B T=16 N8 ALL 1 2 o", BucketFillCommand{1, 2, bytecolor.Color('o'), true, true, 16}},
+		{"CP 1 2 3 4", CopyCommand{1, 2, 3, 4}},
+		{"PA 5 6", PasteCommand{5, 6, nil}},
+		{"PA 5 6 o", PasteCommand{5, 6, bytecolor.Color('o')}},
+		{"PA 5 6 \" \"", PasteCommand{5, 6, bytecolor.Color(' ')}},
+		{"T 1 2  two  words \r", DrawTextCommand{1, 2, "two  words"}},
+		{"T 1 2 \"# not a comment\" # comment", DrawTextCommand{1, 2, "# not a comment"}},
+		{"T\t1 2 \"tab\\there\"", DrawTextCommand{1, 2, "tab\there"}},
+		{"S my screen.png\r", SaveCommand{"my screen.png"}},
+		{"S \"my screen.png\"  ", SaveCommand{"my screen.png"}},
+		{"MR window", StartMacroCommand{"window"}},
+		{"ME", EndMacroCommand{}},
+		{"M window", ReplayMacroCommand{"window", 0, 0}},
+		{"M window 10 -2", ReplayMacroCommand{"window", 10, -2}},
+		{`M "my window" 10 2`, ReplayMacroCommand{"my window", 10, 2}},
 	}
 	for _, c := range casesPos {
 		command, err := commandParser.ParseCommand(c.s)
@@ -65,26 +131,151 @@ func TestBasicParser_ParseCommand(t *testing.T) {
 		{"C 1 2 3", common.ErrInvalidArgumentCount},
 		{"C a 2", common.ErrInvalidNumber},
 		{"C 1 b", common.ErrInvalidNumber},
-		{"L 1 2 3 4 5", common.ErrInvalidArgumentCount},
+		{"CR 1 2 3", common.ErrInvalidArgumentCount},
+		{"CR 1 2 3 d", common.ErrInvalidNumber},
+		{"EX 1 2 3 4 5", common.ErrInvalidArgumentCount},
+		{"EX a 2 3 4", common.ErrInvalidNumber},
+		{"SC 1", common.ErrInvalidArgumentCount},
+		{"SC 1 b", common.ErrInvalidNumber},
+		{"FH 1 2", common.ErrInvalidArgumentCount},
+		{"FV 1 2 3 d", common.ErrInvalidNumber},
+		{"RO", common.ErrInvalidArgumentCount},
+		{"RO 90 1 2 3", common.ErrInvalidArgumentCount},
+		{"RO x", common.ErrInvalidNumber},
+		{"RO 45", common.ErrInvalidAngle},
+		{"RO -90 1 1 2 2", common.ErrInvalidAngle},
+		{"L 1 2 3 4 5 6", common.ErrInvalidArgumentCount},
+		{"L 1 2 3 4 oo", common.ErrInvalidColor},
 		{"L a 2 3 4", common.ErrInvalidNumber},
 		{"L 1 b 3 4", common.ErrInvalidNumber},
 		{"L 1 2 c 4", common.ErrInvalidNumber},
 		{"L 1 2 3 d", common.ErrInvalidNumber},
-		{"R 1 2 3 4 5", common.ErrInvalidArgumentCount},
+		{"R 1 2 3 4 5 6", common.ErrInvalidArgumentCount},
+		{"R 1 2 3 4 oo", common.ErrInvalidColor},
 		{"R a 2 3 4", common.ErrInvalidNumber},
 		{"R 1 b 3 4", common.ErrInvalidNumber},
 		{"R 1 2 c 4", common.ErrInvalidNumber},
 		{"R 1 2 3 d", common.ErrInvalidNumber},
+		{"FR 1 2 3", common.ErrInvalidArgumentCount},
+		{"FR 1 2 3 4 o x y", common.ErrInvalidArgumentCount},
+		{"FR 1 2 3 d o", common.ErrInvalidNumber},
+		{"FR 1 2 3 4 oo", common.ErrInvalidColor},
+		{"FR 1 2 3 4 o xx", common.ErrInvalidColor},
+		{"O 1 2", common.ErrInvalidArgumentCount},
+		{"O a 2 3", common.ErrInvalidNumber},
+		{"FO 1 2 c", common.ErrInvalidNumber},
+		{"E 1 2 3", common.ErrInvalidArgumentCount},
+		{"E 1 2 3 d", common.ErrInvalidNumber},
+		{"FE a 2 3 4", common.ErrInvalidNumber},
+		{"P 1 2", common.ErrInvalidArgumentCount},
+		{"P 1 2 3", common.ErrInvalidArgumentCount},
+		{"P 1 2 3 d", common.ErrInvalidNumber},
+		{"PG 1 2 3 4", common.ErrInvalidArgumentCount},
+		{"PG NZ 1 2 3 4", common.ErrInvalidArgumentCount},
+		{"FP XX 1 2 3 4 5 6", common.ErrInvalidArgumentCount},
+		{"FP 1 2 3 4 5 f", common.ErrInvalidNumber},
+		{"T 1 2", common.ErrInvalidArgumentCount},
+		{"T a 2 text", common.ErrInvalidNumber},
+		{`T 1 2 "unterminated`, common.ErrInvalidString},
+		{`T 1 2 "text" trailing`, common.ErrInvalidString},
 		{"B 1 2 3 4", common.ErrInvalidArgumentCount},
 		{"B a 2 o", common.ErrInvalidNumber},
 		{"B 1 b o", common.ErrInvalidNumber},
 		{"B 1 2 oo", common.ErrInvalidColor},
+		{"B N8", common.ErrInvalidArgumentCount},
+		{"B T=x 1 2", common.ErrInvalidNumber},
+		{"B ALL 1 b", common.ErrInvalidNumber},
+		{"CP 1 2 3", common.ErrInvalidArgumentCount},
+		{"CP 1 2 3 x", common.ErrInvalidNumber},
+		{"PA 1", common.ErrInvalidArgumentCount},
+		{"PA 1 2 o o", common.ErrInvalidArgumentCount},
+		{"PA x 2", common.ErrInvalidNumber},
+		{"PA 1 2 oo", common.ErrInvalidColor},
+		{"S", common.ErrInvalidArgumentCount},
+		{"S ", common.ErrInvalidArgumentCount},
+		{`S "screen1.png`, common.ErrInvalidString},
+		{"FG", common.ErrInvalidArgumentCount},
+		{"FG o o", common.ErrInvalidArgumentCount},
+		{"FG oo", common.ErrInvalidColor},
 		{"X 20 4", common.ErrUnknownCommand},
+		{"C 20 4#", common.ErrInvalidNumber},
+		{`T 1 2 "text"trailing`, common.ErrInvalidString},
+		{`T 1 2 text "quoted"`, common.ErrInvalidString},
+		{`T 1 2 "bad \q escape"`, common.ErrInvalidString},
+		{`S "screen1.png" "screen2.png"`, common.ErrInvalidString},
+		{"L 1 2 6 2 # comment", common.ErrInvalidArgumentCount},
+		{"MR", common.ErrInvalidArgumentCount},
+		{"MR a b", common.ErrInvalidArgumentCount},
+		{"ME a", common.ErrInvalidArgumentCount},
+		{"M", common.ErrInvalidArgumentCount},
+		{"M window 10", common.ErrInvalidArgumentCount},
+		{"M window 10 a", common.ErrInvalidNumber},
 	}
 	for _, c := range casesNeg {
 		_, err := commandParser.ParseCommand(c.s)
-		if err != c.err {
+		if perr, ok := err.(*ParseError); !ok || perr.Err != c.err {
 			t.Errorf("Case: %s, Expected: %#v, Got: %#v", c.s, c.err, err)
 		}
 	}
 }
+
+func TestBasicParser_ParseCommand_ParseError(t *testing.T) {
+	colorParser := &bytecolor.Parser{
+		DefaultColor: bytecolor.Color(' '),
+	}
+	commandParser, err := NewParser(colorParser.ParseColor)
+	if err != nil {
+		panic(err)
+	}
+
+	cases := []struct {
+		s        string
+		expected ParseError
+	}{
+		{"X 20 4", ParseError{"X", -1, 0, "X", common.ErrUnknownCommand}},
+		{"C 1 2 3", ParseError{"C", -1, 0, "C", common.ErrInvalidArgumentCount}},
+		{"C a 2", ParseError{"C", 0, 2, "a", common.ErrInvalidNumber}},
+		{"C 1 bb", ParseError{"C", 1, 4, "bb", common.ErrInvalidNumber}},
+		{"L 10 20 30 4x", ParseError{"L", 3, 11, "4x", common.ErrInvalidNumber}},
+		{"L 1 2 3 4 oo", ParseError{"L", 4, 10, "oo", common.ErrInvalidColor}},
+		{"FR 1 2 3 4 o xx", ParseError{"FR", 5, 13, "xx", common.ErrInvalidColor}},
+		{"FP NZ 1 2 3 4 5 f", ParseError{"FP", 6, 16, "f", common.ErrInvalidNumber}},
+		{`T 1 2 "two words`, ParseError{"T", 2, 6, `"two words`, common.ErrInvalidString}},
+		{"B N8 T=1x 1 2", ParseError{"B", 1, 5, "T=1x", common.ErrInvalidNumber}},
+		{"B ALL 1 2 oo", ParseError{"B", 3, 10, "oo", common.ErrInvalidColor}},
+		{"FG oo", ParseError{"FG", 0, 3, "oo", common.ErrInvalidColor}},
+		{"RO 360 1 1 2 2", ParseError{"RO", 0, 3, "360", common.ErrInvalidAngle}},
+		{"M window 10 a", ParseError{"M", 2, 12, "a", common.ErrInvalidNumber}},
+		{"L 1 2 6 2 #0000ff", ParseError{"L", 4, 10, "#0000ff", common.ErrInvalidColor}},
+		{"C\t 1  bb\r", ParseError{"C", 1, 6, "bb", common.ErrInvalidNumber}},
+		{`T 1 2 text "quoted"`, ParseError{"T", 3, 11, `"quoted"`, common.ErrInvalidString}},
+		{`"unterminated`, ParseError{`"unterminated`, -1, 0, `"unterminated`, common.ErrInvalidString}},
+	}
+	for _, c := range cases {
+		_, err := commandParser.ParseCommand(c.s)
+		got, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("Case: %s, Expected: *ParseError, Got: %#v", c.s, err)
+			continue
+		}
+		if *got != c.expected {
+			t.Errorf("Case: %s, Expected: %#v, Got: %#v", c.s, c.expected, *got)
+		}
+		if got.Error() != c.expected.Err.Error() {
+			t.Errorf("Case: %s, Expected: %q, Got: %q", c.s, c.expected.Err.Error(), got.Error())
+		}
+		if c.s[got.Offset:got.Offset+len(got.Token)] != got.Token {
+			t.Errorf("Case: %s, Expected: %q at %d, Got: %q", c.s, got.Token, got.Offset, c.s[got.Offset:])
+		}
+	}
+}
+
+func TestNewParseError(t *testing.T) {
+	// The omitted argument is located at the end of the string
+	tokens, _ := tokenize("B 1 2  ", nil)
+	got := newParseError("B 1 2  ", tokens, 2, common.ErrInvalidColor)
+	expected := ParseError{"B", 2, 5, "", common.ErrInvalidColor}
+	if *got != expected {
+		t.Errorf("Expected: %#v, Got: %#v", expected, *got)
+	}
+}
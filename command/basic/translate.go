@@ -0,0 +1,83 @@
+package basic
+
+import (
+	"github.com/asukakenji/drawing-challenge/command"
+)
+
+// translatePoints returns a copy of points translated by (dx, dy).
+func translatePoints(points []Point, dx, dy int) []Point {
+	translated := make([]Point, len(points))
+	for i, p := range points {
+		translated[i] = Point{p.X + dx, p.Y + dy}
+	}
+	return translated
+}
+
+// Translate returns a copy of cmd with its coordinates translated by (dx, dy).
+// The commands without coordinates are returned unchanged.
+//
+// Commands with coordinates:
+// DrawLineCommand,
+// DrawRectCommand,
+// FillRectCommand,
+// DrawCircleCommand,
+// DrawEllipseCommand,
+// DrawPolylineCommand,
+// DrawPolygonCommand,
+// DrawTextCommand,
+// BucketFillCommand,
+// CopyCommand,
+// PasteCommand,
+// FlipCommand and RotateCommand with a rectangle.
+//
+func Translate(cmd command.Command, dx, dy int) command.Command {
+	switch cmd := cmd.(type) {
+	case DrawLineCommand:
+		cmd.X1, cmd.Y1, cmd.X2, cmd.Y2 = cmd.X1+dx, cmd.Y1+dy, cmd.X2+dx, cmd.Y2+dy
+		return cmd
+	case DrawRectCommand:
+		cmd.X1, cmd.Y1, cmd.X2, cmd.Y2 = cmd.X1+dx, cmd.Y1+dy, cmd.X2+dx, cmd.Y2+dy
+		return cmd
+	case FillRectCommand:
+		cmd.X1, cmd.Y1, cmd.X2, cmd.Y2 = cmd.X1+dx, cmd.Y1+dy, cmd.X2+dx, cmd.Y2+dy
+		return cmd
+	case DrawCircleCommand:
+		cmd.X, cmd.Y = cmd.X+dx, cmd.Y+dy
+		return cmd
+	case DrawEllipseCommand:
+		cmd.X, cmd.Y = cmd.X+dx, cmd.Y+dy
+		return cmd
+	case DrawPolylineCommand:
+		cmd.Points = translatePoints(cmd.Points, dx, dy)
+		return cmd
+	case DrawPolygonCommand:
+		cmd.Points = translatePoints(cmd.Points, dx, dy)
+		return cmd
+	case DrawTextCommand:
+		cmd.X, cmd.Y = cmd.X+dx, cmd.Y+dy
+		return cmd
+	case BucketFillCommand:
+		cmd.X, cmd.Y = cmd.X+dx, cmd.Y+dy
+		return cmd
+	case CopyCommand:
+		cmd.X1, cmd.Y1, cmd.X2, cmd.Y2 = cmd.X1+dx, cmd.Y1+dy, cmd.X2+dx, cmd.Y2+dy
+		return cmd
+	case PasteCommand:
+		cmd.X, cmd.Y = cmd.X+dx, cmd.Y+dy
+		return cmd
+	case FlipCommand:
+		if cmd.Whole {
+			return cmd
+		}
+		cmd.X1, cmd.Y1, cmd.X2, cmd.Y2 = cmd.X1+dx, cmd.Y1+dy, cmd.X2+dx, cmd.Y2+dy
+		return cmd
+	case RotateCommand:
+		if cmd.Whole {
+			return cmd
+		}
+		cmd.X1, cmd.Y1, cmd.X2, cmd.Y2 = cmd.X1+dx, cmd.Y1+dy, cmd.X2+dx, cmd.Y2+dy
+		return cmd
+	default:
+		return cmd
+	}
+}
@@ -0,0 +1,48 @@
+package basic
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/command"
+)
+
+func TestTranslate(t *testing.T) {
+	points := []Point{{1, 1}, {5, 3}, {1, 4}}
+	cases := []struct {
+		cmd      command.Command
+		expected command.Command
+	}{
+		{DrawLineCommand{1, 2, 6, 2, nil}, DrawLineCommand{11, 0, 16, 0, nil}},
+		{DrawRectCommand{14, 1, 18, 3, bytecolor.Color('R')}, DrawRectCommand{24, -1, 28, 1, bytecolor.Color('R')}},
+		{FillRectCommand{14, 1, 18, 3, bytecolor.Color('o'), nil}, FillRectCommand{24, -1, 28, 1, bytecolor.Color('o'), nil}},
+		{DrawCircleCommand{10, 3, 2, true}, DrawCircleCommand{20, 1, 2, true}},
+		{DrawEllipseCommand{10, 3, 4, 2, false}, DrawEllipseCommand{20, 1, 4, 2, false}},
+		{DrawPolylineCommand{points}, DrawPolylineCommand{[]Point{{11, -1}, {15, 1}, {11, 2}}}},
+		{DrawPolygonCommand{points, true, true}, DrawPolygonCommand{[]Point{{11, -1}, {15, 1}, {11, 2}}, true, true}},
+		{DrawTextCommand{1, 2, "label"}, DrawTextCommand{11, 0, "label"}},
+		{BucketFillCommand{10, 3, bytecolor.Color('o'), true, false, 8}, BucketFillCommand{20, 1, bytecolor.Color('o'), true, false, 8}},
+		{CopyCommand{1, 2, 3, 4}, CopyCommand{11, 0, 13, 2}},
+		{PasteCommand{5, 6, nil}, PasteCommand{15, 4, nil}},
+		{FlipCommand{true, false, 1, 2, 3, 4}, FlipCommand{true, false, 11, 0, 13, 2}},
+		{FlipCommand{false, false, 0, 0, 0, 0}, FlipCommand{false, false, 10, -2, 10, -2}},
+		{FlipCommand{false, true, 0, 0, 0, 0}, FlipCommand{false, true, 0, 0, 0, 0}},
+		{RotateCommand{90, false, 1, 2, 3, 4}, RotateCommand{90, false, 11, 0, 13, 2}},
+		{RotateCommand{180, true, 0, 0, 0, 0}, RotateCommand{180, true, 0, 0, 0, 0}},
+		{SetForegroundColorCommand{bytecolor.Color('G')}, SetForegroundColorCommand{bytecolor.Color('G')}},
+		{NewCanvasCommand{20, 4}, NewCanvasCommand{20, 4}},
+		{QuitCommand{}, QuitCommand{}},
+	}
+	for _, c := range cases {
+		got := Translate(c.cmd, 10, -2)
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("Case: %#v, Expected: %#v, Got: %#v", c.cmd, c.expected, got)
+		}
+	}
+
+	// The points are copied
+	if !reflect.DeepEqual(points, []Point{{1, 1}, {5, 3}, {1, 4}}) {
+		t.Errorf("Expected: the points unchanged, Got: %#v", points)
+	}
+}
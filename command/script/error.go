@@ -0,0 +1,79 @@
+package script
+
+import (
+	"unicode/utf8"
+)
+
+// Error is the error returned when a script could not be parsed or run.
+// It records where the error occurs in the script,
+// and wraps one of the errors defined in package common,
+// or the error returned by the command parser or the interpreter,
+// so that it could still be tested through Err, like:
+//
+//     serr, ok := err.(*Error)
+//     if ok && serr.Err == common.ErrUndefinedVariable {
+//         ...
+//     }
+//
+type Error struct {
+	// Line is the one-based line number of the statement causing the error.
+	Line int
+
+	// Column is the one-based column (in runes) of the token causing the error,
+	// so it is never 0 for a token, even at the beginning of the line.
+	// It is 0 only if the error is caused by the statement as a whole,
+	// like the errors returned by the interpreter.
+	Column int
+
+	// Err is the wrapped error.
+	Err error
+}
+
+// Error returns the message of the wrapped error.
+func (err *Error) Error() string {
+	return err.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (err *Error) Unwrap() error {
+	return err.Err
+}
+
+// wholeStatement is the offset passed to newError
+// if the error is not caused by a particular token.
+const wholeStatement = -1
+
+// failure is an error occurring at offset of a line.
+// It is converted to an Error when the line is known.
+type failure struct {
+	offset int
+	err    error
+}
+
+// Error returns the message of the wrapped error.
+func (f *failure) Error() string {
+	return f.err.Error()
+}
+
+// newError returns a new Error caused by the token at offset of line,
+// which is the content of the line at lineNumber.
+// If err is a *failure, its offset is used instead.
+// Otherwise, if offset is wholeStatement, the error is caused by the statement
+// as a whole, and the Column of the Error is 0.
+func newError(lineNumber int, line string, offset int, err error) *Error {
+	if f, ok := err.(*failure); ok {
+		offset, err = f.offset, f.err
+	}
+	column := 0
+	if offset > len(line) {
+		offset = len(line)
+	}
+	if offset >= 0 {
+		column = utf8.RuneCountInString(line[:offset]) + 1
+	}
+	return &Error{
+		Line:   lineNumber,
+		Column: column,
+		Err:    err,
+	}
+}
@@ -0,0 +1,351 @@
+package script
+
+import (
+	"strconv"
+
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+// Kinds of exprToken.
+const (
+	numberToken = iota
+	variableToken
+	nameToken
+	operatorToken
+)
+
+// exprToken is a token of an expression.
+type exprToken struct {
+	kind int
+
+	// text is the content of the token,
+	// without the leading "$" if it is a variable.
+	text string
+
+	// offset is the byte offset of the token in the line.
+	offset int
+}
+
+// operators are the operators recognized by lexExpr,
+// with the two-character operators before the one-character ones.
+var operators = []string{
+	"==", "!=", "<=", ">=", "&&", "||",
+	"+", "-", "*", "/", "%", "(", ")", "<", ">", "!", "=",
+}
+
+// isNameStart returns whether b could start a name.
+func isNameStart(b byte) bool {
+	return b == '_' || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
+
+// isNamePart returns whether b could be a part of a name.
+func isNamePart(b byte) bool {
+	return isNameStart(b) || ('0' <= b && b <= '9')
+}
+
+// isName returns whether s is a valid name of a variable or a procedure.
+func isName(s string) bool {
+	if s == "" || !isNameStart(s[0]) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !isNamePart(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// lexExpr splits s, which is located at offset base of a line, into tokens.
+// Spaces and tabs between the tokens are skipped.
+//
+// Errors
+//
+// common.ErrInvalidExpression:
+// Will be returned (wrapped in a *failure) if s contains a character
+// which could not start a token, or a "$" not followed by a name.
+//
+func lexExpr(s string, base int) ([]exprToken, error) {
+	var tokens []exprToken
+	i := 0
+Loop:
+	for i < len(s) {
+		b := s[i]
+		switch {
+		case b == ' ' || b == '\t':
+			i++
+		case '0' <= b && b <= '9':
+			start := i
+			for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+				i++
+			}
+			tokens = append(tokens, exprToken{numberToken, s[start:i], base + start})
+		case b == '$' || isNameStart(b):
+			start := i
+			if b == '$' {
+				i++
+				if i >= len(s) || !isNameStart(s[i]) {
+					return nil, &failure{base + start, common.ErrInvalidExpression}
+				}
+			}
+			for i < len(s) && isNamePart(s[i]) {
+				i++
+			}
+			if b == '$' {
+				tokens = append(tokens, exprToken{variableToken, s[start+1 : i], base + start})
+			} else {
+				tokens = append(tokens, exprToken{nameToken, s[start:i], base + start})
+			}
+		default:
+			for _, op := range operators {
+				if len(s)-i >= len(op) && s[i:i+len(op)] == op {
+					tokens = append(tokens, exprToken{operatorToken, op, base + i})
+					i += len(op)
+					continue Loop
+				}
+			}
+			return nil, &failure{base + i, common.ErrInvalidExpression}
+		}
+	}
+	return tokens, nil
+}
+
+// expr is a node of the syntax tree of an expression.
+type expr interface {
+	// eval evaluates the expression with the variables in sc.
+	eval(sc *scope) (int, error)
+}
+
+// numberExpr is an integer literal.
+type numberExpr struct {
+	value int
+}
+
+// variableExpr is a reference to a variable.
+type variableExpr struct {
+	name   string
+	offset int
+}
+
+// unaryExpr is a unary operation.
+type unaryExpr struct {
+	op string
+	x  expr
+}
+
+// binaryExpr is a binary operation.
+type binaryExpr struct {
+	op     string
+	x      expr
+	y      expr
+	offset int
+}
+
+func (e numberExpr) eval(sc *scope) (int, error) {
+	return e.value, nil
+}
+
+func (e variableExpr) eval(sc *scope) (int, error) {
+	value, ok := sc.lookup(e.name)
+	if !ok {
+		return 0, &failure{e.offset, common.ErrUndefinedVariable}
+	}
+	return value, nil
+}
+
+func (e unaryExpr) eval(sc *scope) (int, error) {
+	x, err := e.x.eval(sc)
+	if err != nil {
+		return 0, err
+	}
+	if e.op == "-" {
+		return -x, nil
+	}
+	return boolToInt(x == 0), nil
+}
+
+func (e binaryExpr) eval(sc *scope) (int, error) {
+	x, err := e.x.eval(sc)
+	if err != nil {
+		return 0, err
+	}
+	// NOTE: "&&" and "||" short-circuit like those in Go
+	switch {
+	case e.op == "&&" && x == 0:
+		return 0, nil
+	case e.op == "||" && x != 0:
+		return 1, nil
+	}
+	y, err := e.y.eval(sc)
+	if err != nil {
+		return 0, err
+	}
+	switch e.op {
+	case "&&", "||":
+		return boolToInt(y != 0), nil
+	case "==":
+		return boolToInt(x == y), nil
+	case "!=":
+		return boolToInt(x != y), nil
+	case "<":
+		return boolToInt(x < y), nil
+	case "<=":
+		return boolToInt(x <= y), nil
+	case ">":
+		return boolToInt(x > y), nil
+	case ">=":
+		return boolToInt(x >= y), nil
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "*":
+		return x * y, nil
+	default:
+		// "/" or "%"
+		if y == 0 {
+			return 0, &failure{e.offset, common.ErrDivisionByZero}
+		}
+		if e.op == "/" {
+			return x / y, nil
+		}
+		return x % y, nil
+	}
+}
+
+// boolToInt returns 1 if b is true, or 0 otherwise.
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// precedences are the precedences of the binary operators.
+// Operators with higher precedences bind tighter.
+var precedences = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+// exprParser parses a sequence of tokens into an expr.
+type exprParser struct {
+	tokens []exprToken
+
+	// end is the byte offset of the end of the tokens in the line,
+	// which is reported if the tokens end unexpectedly.
+	end int
+
+	pos int
+}
+
+// parseExpr parses tokens, which end at offset end of the line,
+// into a single expression.
+//
+// Errors
+//
+// common.ErrInvalidExpression:
+// Will be returned (wrapped in a *failure) if tokens do not form
+// exactly one expression.
+//
+// common.ErrInvalidNumber:
+// Will be returned (wrapped in a *failure) if an integer literal is out of range.
+//
+func parseExpr(tokens []exprToken, end int) (expr, error) {
+	p := &exprParser{tokens: tokens, end: end}
+	e, err := p.parseBinary(1)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.fail()
+	}
+	return e, nil
+}
+
+// fail returns the error caused by the current token,
+// or by the end of the tokens.
+func (p *exprParser) fail() error {
+	if p.pos < len(p.tokens) {
+		return &failure{p.tokens[p.pos].offset, common.ErrInvalidExpression}
+	}
+	return &failure{p.end, common.ErrInvalidExpression}
+}
+
+// peekOperator returns the current token if it is an operator,
+// or "" otherwise.
+func (p *exprParser) peekOperator() string {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == operatorToken {
+		return p.tokens[p.pos].text
+	}
+	return ""
+}
+
+// parseBinary parses the binary operations with precedences
+// not lower than minPrecedence.
+func (p *exprParser) parseBinary(minPrecedence int) (expr, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peekOperator()
+		precedence, ok := precedences[op]
+		if !ok || precedence < minPrecedence {
+			return x, nil
+		}
+		offset := p.tokens[p.pos].offset
+		p.pos++
+		y, err := p.parseBinary(precedence + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = binaryExpr{op, x, y, offset}
+	}
+}
+
+// parseUnary parses a unary operation, or an operand.
+func (p *exprParser) parseUnary() (expr, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, p.fail()
+	}
+	t := p.tokens[p.pos]
+	switch t.kind {
+	case numberToken:
+		value, err := strconv.Atoi(t.text)
+		if err != nil {
+			return nil, &failure{t.offset, common.ErrInvalidNumber}
+		}
+		p.pos++
+		return numberExpr{value}, nil
+	case variableToken:
+		p.pos++
+		return variableExpr{t.text, t.offset}, nil
+	case operatorToken:
+		switch t.text {
+		case "-", "!":
+			p.pos++
+			x, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			return unaryExpr{t.text, x}, nil
+		case "(":
+			p.pos++
+			x, err := p.parseBinary(1)
+			if err != nil {
+				return nil, err
+			}
+			if p.peekOperator() != ")" {
+				return nil, p.fail()
+			}
+			p.pos++
+			return x, nil
+		}
+	}
+	return nil, p.fail()
+}
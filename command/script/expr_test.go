@@ -0,0 +1,97 @@
+package script
+
+import (
+	"testing"
+
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+// evalString lexes, parses and evaluates s with the variables in sc.
+func evalString(s string, sc *scope) (int, error) {
+	tokens, err := lexExpr(s, 0)
+	if err != nil {
+		return 0, err
+	}
+	e, err := parseExpr(tokens, len(s))
+	if err != nil {
+		return 0, err
+	}
+	return e.eval(sc)
+}
+
+func TestEval(t *testing.T) {
+	sc := newScope(nil)
+	sc.vars["x"] = 5
+	sc.vars["y_2"] = -2
+
+	// Positive Cases
+	casesPos := []struct {
+		s        string
+		expected int
+	}{
+		{"42", 42},
+		{"$x", 5},
+		{"1+2*3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10-4-3", 3},
+		{"-$x", -5},
+		{"2*-3", -6},
+		{"$x*$y_2", -10},
+		{"7/2", 3},
+		{"-7%3", -1},
+		{"$x == 5", 1},
+		{"$x != 5", 0},
+		{"1 < 2 && 2 <= 1", 0},
+		{"1 > 2 || 2 >= 2", 1},
+		{"!0", 1},
+		{"!!7", 1},
+		{"1 + 1 == 2", 1},
+		{"$x == 5 || 1/0", 1},
+		{"$x != 5 && 1/0", 0},
+	}
+	for _, c := range casesPos {
+		got, err := evalString(c.s, sc)
+		if err != nil {
+			t.Errorf("Case: %q, Expected: err == nil, Got: %#v", c.s, err)
+		}
+		if got != c.expected {
+			t.Errorf("Case: %q, Expected: %d, Got: %d", c.s, c.expected, got)
+		}
+	}
+
+	// Negative Cases
+	casesNeg := []struct {
+		s      string
+		offset int
+		err    error
+	}{
+		{"", 0, common.ErrInvalidExpression},
+		{"1+", 2, common.ErrInvalidExpression},
+		{"(1", 2, common.ErrInvalidExpression},
+		{"1 2", 2, common.ErrInvalidExpression},
+		{"1)", 1, common.ErrInvalidExpression},
+		{"$", 0, common.ErrInvalidExpression},
+		{"$1", 0, common.ErrInvalidExpression},
+		{"1 @ 2", 2, common.ErrInvalidExpression},
+		{"x", 0, common.ErrInvalidExpression},
+		{"1 = 2", 2, common.ErrInvalidExpression},
+		{"99999999999999999999", 0, common.ErrInvalidNumber},
+		{"$x / (1 - 1)", 3, common.ErrDivisionByZero},
+		{"1 % 0", 2, common.ErrDivisionByZero},
+		{"1 + $z", 4, common.ErrUndefinedVariable},
+	}
+	for _, c := range casesNeg {
+		_, err := evalString(c.s, sc)
+		f, ok := err.(*failure)
+		if !ok {
+			t.Errorf("Case: %q, Expected: *failure, Got: %#v", c.s, err)
+			continue
+		}
+		if f.err != c.err {
+			t.Errorf("Case: %q, Expected: %#v, Got: %#v", c.s, c.err, f.err)
+		}
+		if f.offset != c.offset {
+			t.Errorf("Case: %q, Expected: %d, Got: %d", c.s, c.offset, f.offset)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package script
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+
+	"github.com/asukakenji/drawing-challenge/command"
+	"github.com/asukakenji/drawing-challenge/command/basic"
+	"github.com/asukakenji/drawing-challenge/common"
+	"github.com/asukakenji/drawing-challenge/interpreter"
+)
+
+// MaxCallDepth is the maximum number of nested procedure calls.
+const MaxCallDepth = 100
+
+// scope is a set of variables.
+type scope struct {
+	vars   map[string]int
+	parent *scope
+}
+
+// newScope returns a new scope inside parent.
+func newScope(parent *scope) *scope {
+	return &scope{
+		vars:   make(map[string]int),
+		parent: parent,
+	}
+}
+
+// lookup returns the value of the variable called name
+// in sc or its ancestors.
+func (sc *scope) lookup(name string) (int, bool) {
+	for ; sc != nil; sc = sc.parent {
+		if value, ok := sc.vars[name]; ok {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+// set assigns value to the variable called name in sc or its ancestors,
+// or defines it in sc if it is not defined.
+func (sc *scope) set(name string, value int) {
+	for s := sc; s != nil; s = s.parent {
+		if _, ok := s.vars[name]; ok {
+			s.vars[name] = value
+			return
+		}
+	}
+	sc.vars[name] = value
+}
+
+// quitter is implemented by the environments which could be quitted,
+// like simple.Environment.
+type quitter interface {
+	ShouldQuit() bool
+}
+
+// errQuit stops running the script when the environment should quit.
+var errQuit = errors.New("quit")
+
+// Runner expands a Program into commands,
+// parses them by a command parser, and interprets them by an interpreter.
+type Runner struct {
+	parser command.Parser
+	interp interpreter.Interpreter
+}
+
+// NewRunner returns a new Runner.
+//
+// Errors
+//
+// common.ErrNilPointer:
+// Will be returned if parser == nil or interp == nil.
+//
+func NewRunner(parser command.Parser, interp interpreter.Interpreter) (*Runner, error) {
+	if parser == nil {
+		return nil, common.ErrNilPointer
+	}
+	if interp == nil {
+		return nil, common.ErrNilPointer
+	}
+	return &Runner{
+		parser: parser,
+		interp: interp,
+	}, nil
+}
+
+// execution is the state of running a Program.
+type execution struct {
+	runner *Runner
+	prog   *Program
+	env    interface{}
+	report func(*Error) bool
+	global *scope
+	depth  int
+}
+
+// Run runs prog with the environment env, until the end of prog,
+// or until env should quit, if env has a ShouldQuit method.
+//
+// A failure of a command, which could not be parsed or interpreted,
+// is passed to report, which returns whether the remaining statements
+// should still be run. A failure of the script itself, like an undefined
+// variable, is also passed to report, but always stops prog. If report is nil,
+// prog is stopped at the first failure.
+//
+// Errors
+//
+// The failure stopping prog is returned, which is an *Error wrapping one of:
+//
+// common.ErrUndefinedVariable:
+// If an expression references a variable not defined.
+//
+// common.ErrUndefinedProcedure:
+// If a procedure not defined is called.
+//
+// common.ErrInvalidArgumentCount:
+// If a procedure is called with a wrong number of arguments.
+//
+// common.ErrDivisionByZero:
+// If an expression divides by zero.
+//
+// common.ErrStepZero:
+// If the step of a loop is zero.
+//
+// common.ErrRecursionTooDeep:
+// If the procedure calls are nested more than MaxCallDepth levels.
+//
+// Other errors:
+// If the command parser or the interpreter fails.
+//
+func (runner *Runner) Run(prog *Program, env interface{}, report func(*Error) bool) error {
+	ex := &execution{
+		runner: runner,
+		prog:   prog,
+		env:    env,
+		report: report,
+		global: newScope(nil),
+	}
+	err := ex.runBlock(prog.statements, ex.global)
+	if err == errQuit {
+		return nil
+	}
+	return err
+}
+
+// fail returns the error caused by the token at offset of the line
+// at lineNumber, which stops the script.
+func (ex *execution) fail(lineNumber, offset int, err error) error {
+	e := newError(lineNumber, ex.prog.lines[lineNumber-1], offset, err)
+	if ex.report != nil {
+		ex.report(e)
+	}
+	return e
+}
+
+// runBlock runs statements with the variables in sc.
+func (ex *execution) runBlock(statements []statement, sc *scope) error {
+	for _, stmt := range statements {
+		var err error
+		switch stmt := stmt.(type) {
+		case letStatement:
+			err = ex.runLet(stmt, sc)
+		case forStatement:
+			err = ex.runFor(stmt, sc)
+		case ifStatement:
+			err = ex.runIf(stmt, sc)
+		case callStatement:
+			err = ex.runCall(stmt, sc)
+		case commandStatement:
+			err = ex.runCommand(stmt, sc)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runLet assigns the value of the expression of stmt to its variable in sc.
+func (ex *execution) runLet(stmt letStatement, sc *scope) error {
+	value, err := stmt.value.eval(sc)
+	if err != nil {
+		return ex.fail(stmt.line, wholeStatement, err)
+	}
+	sc.set(stmt.name, value)
+	return nil
+}
+
+// runFor runs the block of stmt for each value of its variable,
+// with the bounds and the step evaluated once before the loop.
+func (ex *execution) runFor(stmt forStatement, sc *scope) error {
+	from, err := stmt.from.eval(sc)
+	if err != nil {
+		return ex.fail(stmt.line, wholeStatement, err)
+	}
+	to, err := stmt.to.eval(sc)
+	if err != nil {
+		return ex.fail(stmt.line, wholeStatement, err)
+	}
+	step := 1
+	if stmt.step != nil {
+		step, err = stmt.step.eval(sc)
+		if err != nil {
+			return ex.fail(stmt.line, wholeStatement, err)
+		}
+		if step == 0 {
+			return ex.fail(stmt.line, stmt.stepOffset, common.ErrStepZero)
+		}
+	}
+	// NOTE: The bounds are evaluated once, like those in BASIC
+	for i := from; (step > 0 && i <= to) || (step < 0 && i >= to); i += step {
+		sc.set(stmt.name, i)
+		err = ex.runBlock(stmt.body, sc)
+		if err != nil {
+			return err
+		}
+		// NOTE: Stopped before i overflows, since to could be near the bounds of int
+		if (step > 0 && i > to-step) || (step < 0 && i < to-step) {
+			break
+		}
+	}
+	return nil
+}
+
+// runIf runs the block of stmt if its condition is not 0,
+// or the else block otherwise.
+func (ex *execution) runIf(stmt ifStatement, sc *scope) error {
+	cond, err := stmt.cond.eval(sc)
+	if err != nil {
+		return ex.fail(stmt.line, wholeStatement, err)
+	}
+	if cond != 0 {
+		return ex.runBlock(stmt.body, sc)
+	}
+	return ex.runBlock(stmt.elseBody, sc)
+}
+
+// runCall runs the body of the procedure called by stmt,
+// in a new scope containing the parameters, inside the global scope.
+func (ex *execution) runCall(stmt callStatement, sc *scope) error {
+	proc, ok := ex.prog.procedures[stmt.name]
+	if !ok {
+		return ex.fail(stmt.line, stmt.nameOffset, common.ErrUndefinedProcedure)
+	}
+	if len(stmt.args) != len(proc.params) {
+		return ex.fail(stmt.line, wholeStatement, common.ErrInvalidArgumentCount)
+	}
+	if ex.depth >= MaxCallDepth {
+		return ex.fail(stmt.line, stmt.nameOffset, common.ErrRecursionTooDeep)
+	}
+	local := newScope(ex.global)
+	for i, arg := range stmt.args {
+		value, err := arg.eval(sc)
+		if err != nil {
+			return ex.fail(stmt.line, wholeStatement, err)
+		}
+		local.vars[proc.params[i]] = value
+	}
+	ex.depth++
+	defer func() {
+		ex.depth--
+	}()
+	return ex.runBlock(proc.body, local)
+}
+
+// replacement records an argument replaced by its value.
+type replacement struct {
+	// start and end are the byte offsets of the argument in the line.
+	start int
+	end   int
+
+	// newStart and newEnd are the byte offsets of the value
+	// in the line after the replacement.
+	newStart int
+	newEnd   int
+}
+
+// originalOffset returns the byte offset in the original line
+// corresponding to offset in the line after replacements.
+func originalOffset(replacements []replacement, offset int) int {
+	delta := 0
+	for _, r := range replacements {
+		if offset < r.newStart {
+			break
+		}
+		if offset < r.newEnd {
+			return r.start
+		}
+		delta = r.end - r.newEnd
+	}
+	return offset + delta
+}
+
+// runCommand replaces the arguments of stmt containing expressions
+// by their values, then parses the line by the command parser,
+// and interprets the command. The column of a parse error is mapped back
+// to the line before the replacements.
+func (ex *execution) runCommand(stmt commandStatement, sc *scope) error {
+	line := ex.prog.lines[stmt.line-1]
+
+	// Replace the arguments by their values
+	var b bytes.Buffer
+	var replacements []replacement
+	last := 0
+	for _, arg := range stmt.args {
+		value, err := arg.value.eval(sc)
+		if err != nil {
+			return ex.fail(stmt.line, wholeStatement, err)
+		}
+		b.WriteString(line[last:arg.start])
+		newStart := b.Len()
+		b.WriteString(strconv.Itoa(value))
+		replacements = append(replacements, replacement{arg.start, arg.end, newStart, b.Len()})
+		last = arg.end
+	}
+	b.WriteString(line[last:])
+
+	cmd, err := ex.runner.parser.ParseCommand(b.String())
+	if err != nil {
+		offset := wholeStatement
+		if perr, ok := err.(*basic.ParseError); ok {
+			offset = originalOffset(replacements, perr.Offset)
+		}
+		return ex.failCommand(stmt.line, offset, err)
+	}
+	err = ex.runner.interp.Interpret(ex.env, cmd)
+	if err != nil {
+		return ex.failCommand(stmt.line, wholeStatement, err)
+	}
+	if q, ok := ex.env.(quitter); ok && q.ShouldQuit() {
+		return errQuit
+	}
+	return nil
+}
+
+// failCommand reports the failure of the command at lineNumber,
+// and returns the error if the script should stop, or nil otherwise.
+func (ex *execution) failCommand(lineNumber, offset int, err error) error {
+	e := newError(lineNumber, ex.prog.lines[lineNumber-1], offset, err)
+	if ex.report != nil && ex.report(e) {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,235 @@
+package script
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/command"
+	"github.com/asukakenji/drawing-challenge/command/basic"
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+// This type is created for testing purpose only
+type mockEnvironment struct {
+	commands   []command.Command
+	shouldQuit bool
+}
+
+func (env *mockEnvironment) ShouldQuit() bool {
+	return env.shouldQuit
+}
+
+// This type is created for testing purpose only
+type mockInterpreter struct {
+}
+
+// Interpret records cmd in env, which must be a *mockEnvironment.
+// It fails if cmd is a line starting outside the 100x100 canvas.
+func (interp *mockInterpreter) Interpret(env interface{}, cmd command.Command) error {
+	menv := env.(*mockEnvironment)
+	switch cmd := cmd.(type) {
+	case basic.DrawLineCommand:
+		if cmd.X1 > 100 {
+			return common.ErrPointOutsideCanvas
+		}
+	case basic.QuitCommand:
+		menv.shouldQuit = true
+	}
+	menv.commands = append(menv.commands, cmd)
+	return nil
+}
+
+func newTestRunner() *Runner {
+	commandParser, err := basic.NewParser((&bytecolor.Parser{DefaultColor: bytecolor.Color(' ')}).ParseColor)
+	if err != nil {
+		panic(err)
+	}
+	runner, err := NewRunner(commandParser, &mockInterpreter{})
+	if err != nil {
+		panic(err)
+	}
+	return runner
+}
+
+// point returns the DrawLineCommand drawing a point at (x, y).
+func point(x, y int) basic.DrawLineCommand {
+	return basic.DrawLineCommand{X1: x, Y1: y, X2: x, Y2: y}
+}
+
+func TestNewRunner(t *testing.T) {
+	commandParser, err := basic.NewParser(new(bytecolor.Parser).ParseColor)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = NewRunner(commandParser, &mockInterpreter{})
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+
+	_, err = NewRunner(nil, &mockInterpreter{})
+	if err != common.ErrNilPointer {
+		t.Errorf("Expected: err == %#v, Got: %#v", common.ErrNilPointer, err)
+	}
+
+	_, err = NewRunner(commandParser, nil)
+	if err != common.ErrNilPointer {
+		t.Errorf("Expected: err == %#v, Got: %#v", common.ErrNilPointer, err)
+	}
+}
+
+// maxInt is the maximum value of int.
+const maxInt = int(^uint(0) >> 1)
+
+func TestRunner_Run(t *testing.T) {
+	runner := newTestRunner()
+
+	// Positive Cases
+	casesPos := []struct {
+		s        string
+		commands []command.Command
+	}{
+		{"", nil},
+		{"\n# comment", []command.Command{basic.EmptyCommand{}, basic.EmptyCommand{}}},
+		{"for i = 0 to 2\n  L $i*2+1 1 $i*2+1 1\nend", []command.Command{point(1, 1), point(3, 1), point(5, 1)}},
+		{"for i = 3 to 1 step -2\n  L $i 1 $i 1\nend\nfor i = 2 to 1\n  L 9 9 9 9\nend\nL $i 2 $i 2", []command.Command{point(3, 1), point(1, 1), point(1, 2)}},
+		{"let n = 2\nfor i = 1 to $n\n  let n = 5\n  L $i 1 $i 1\nend", []command.Command{point(1, 1), point(2, 1)}},
+		{"for i = 1 to 4\n  if $i % 2 == 0\n    L $i 1 $i 1\n  else\n    L $i 2 $i 2\n  end\nend", []command.Command{point(1, 2), point(2, 1), point(3, 2), point(4, 1)}},
+		{"call row 2 3\nproc row y n\n  if $n > 0\n    L $n $y $n $y\n    call row $y $n-1\n  end\nend", []command.Command{point(3, 2), point(2, 2), point(1, 2)}},
+		{"let g = 1\nproc p x\n  let g = $g + $x\n  let x = 9\nend\ncall p 2\nlet x = 0\ncall p 3\nL $g $x $g $x", []command.Command{point(6, 0)}},
+		{`let x = 1` + "\n" + `T $x $x+1 "$x"`, []command.Command{basic.DrawTextCommand{X: 1, Y: 2, Text: "$x"}}},
+		{"L 1 1 1 1\nQ\nL 2 2 2 2", []command.Command{point(1, 1), basic.QuitCommand{}}},
+		{"for i = 1 to 3\n  L $i 1 $i 1\n  if $i == 2\n    Q\n  end\nend", []command.Command{point(1, 1), point(2, 1), basic.QuitCommand{}}},
+		// The loops end at the bounds of int without overflowing
+		{fmt.Sprintf("let m = %d\nfor i = $m-1 to $m\n  L $i-$m+2 1 $i-$m+2 1\nend", maxInt), []command.Command{point(1, 1), point(2, 1)}},
+		{fmt.Sprintf("let m = -%d-1\nfor i = $m+1 to $m step -1\n  L $i-$m+1 1 $i-$m+1 1\nend", maxInt), []command.Command{point(2, 1), point(1, 1)}},
+		{fmt.Sprintf("let m = %d\nfor i = 1 to $m step $m\n  L $i 1 $i 1\nend", maxInt), []command.Command{point(1, 1)}},
+	}
+	for _, c := range casesPos {
+		prog, err := Parse(strings.NewReader(c.s))
+		if err != nil {
+			panic(err)
+		}
+		env := &mockEnvironment{}
+		err = runner.Run(prog, env, nil)
+		if err != nil {
+			t.Errorf("Case: %q, Expected: err == nil, Got: %#v", c.s, err)
+		}
+		if !reflect.DeepEqual(env.commands, c.commands) {
+			t.Errorf("Case: %q, Expected: %#v, Got: %#v", c.s, c.commands, env.commands)
+		}
+	}
+
+	// Negative Cases
+	casesNeg := []struct {
+		s        string
+		expected *Error
+		commands []command.Command
+	}{
+		{"L 1 1 1 1\nL 1 1 $x 1", &Error{2, 7, common.ErrUndefinedVariable}, []command.Command{point(1, 1)}},
+		{"let x = 1/0", &Error{1, 10, common.ErrDivisionByZero}, nil},
+		{"call p", &Error{1, 6, common.ErrUndefinedProcedure}, nil},
+		{"proc p a\nend\ncall p", &Error{3, 0, common.ErrInvalidArgumentCount}, nil},
+		{"for i = 1 to 2 step 1 - 1\nend", &Error{1, 21, common.ErrStepZero}, nil},
+		{"proc p\n  call p\nend\ncall p", &Error{2, 8, common.ErrRecursionTooDeep}, nil},
+		{"proc p\n  let l = 1\nend\ncall p\nL $l 1 1 1", &Error{5, 3, common.ErrUndefinedVariable}, nil},
+		{"if $x\nend", &Error{1, 4, common.ErrUndefinedVariable}, nil},
+		{"L 1 1 1 1\nL 200 1 1 1\nL 2 2 2 2", &Error{2, 0, common.ErrPointOutsideCanvas}, []command.Command{point(1, 1)}},
+	}
+	for _, c := range casesNeg {
+		prog, err := Parse(strings.NewReader(c.s))
+		if err != nil {
+			panic(err)
+		}
+		env := &mockEnvironment{}
+		var reported []error
+		err = runner.Run(prog, env, func(err *Error) bool {
+			reported = append(reported, err)
+			return false
+		})
+		if !reflect.DeepEqual(err, c.expected) {
+			t.Errorf("Case: %q, Expected: %#v, Got: %#v", c.s, c.expected, err)
+		}
+		if !reflect.DeepEqual(reported, []error{err}) {
+			t.Errorf("Case: %q, Expected: %#v, Got: %#v", c.s, []error{err}, reported)
+		}
+		if !reflect.DeepEqual(env.commands, c.commands) {
+			t.Errorf("Case: %q, Expected: %#v, Got: %#v", c.s, c.commands, env.commands)
+		}
+	}
+}
+
+func TestRunner_Run_ParseError(t *testing.T) {
+	runner := newTestRunner()
+
+	// The column is that in the line before the replacements
+	prog, err := Parse(strings.NewReader("let x = 100\nL $x+1 1 $x A"))
+	if err != nil {
+		panic(err)
+	}
+	err = runner.Run(prog, &mockEnvironment{}, nil)
+	serr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("Expected: *Error, Got: %#v", err)
+	}
+	perr, ok := serr.Err.(*basic.ParseError)
+	if serr.Line != 2 || serr.Column != 13 || !ok || perr.Err != common.ErrInvalidNumber {
+		t.Errorf("Expected: 2:13: %v, Got: %d:%d: %v", common.ErrInvalidNumber, serr.Line, serr.Column, err)
+	}
+}
+
+func TestRunner_Run_KeepGoing(t *testing.T) {
+	runner := newTestRunner()
+	prog, err := Parse(strings.NewReader("L 200 1 1 1\nL 1 1 1 A\nL 1 1 1 1\nL $x 1 1 1\nL 2 2 2 2"))
+	if err != nil {
+		panic(err)
+	}
+	env := &mockEnvironment{}
+	var reported []*Error
+	err = runner.Run(prog, env, func(err *Error) bool {
+		reported = append(reported, err)
+		return true
+	})
+
+	// The script stops at the undefined variable
+	if serr, ok := err.(*Error); !ok || serr.Err != common.ErrUndefinedVariable {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrUndefinedVariable, err)
+	}
+	lines := make([]int, len(reported))
+	for i, e := range reported {
+		lines[i] = e.Line
+	}
+	if !reflect.DeepEqual(lines, []int{1, 2, 4}) {
+		t.Errorf("Expected: %#v, Got: %#v", []int{1, 2, 4}, lines)
+	}
+	expected := []command.Command{point(1, 1)}
+	if !reflect.DeepEqual(env.commands, expected) {
+		t.Errorf("Expected: %#v, Got: %#v", expected, env.commands)
+	}
+}
+
+func TestOriginalOffset(t *testing.T) {
+	// "L $x 1 $y 2" -> "L 100 1 7 2"
+	replacements := []replacement{{2, 4, 2, 5}, {7, 9, 8, 9}}
+	cases := []struct {
+		offset   int
+		expected int
+	}{
+		{0, 0},
+		{2, 2},
+		{4, 2},
+		{6, 5},
+		{8, 7},
+		{10, 10},
+		{11, 11},
+	}
+	for _, c := range cases {
+		got := originalOffset(replacements, c.offset)
+		if got != c.expected {
+			t.Errorf("Case: %d, Expected: %d, Got: %d", c.offset, c.expected, got)
+		}
+	}
+}
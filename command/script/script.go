@@ -0,0 +1,503 @@
+// Package script defines the Program type, which is a parsed script
+// extending the commands with variables, integer expressions,
+// loops, conditionals and procedures, and the Runner type,
+// which expands a Program into commands and runs them by an interpreter.
+package script
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/asukakenji/drawing-challenge/command/basic"
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+// splitWords splits line into words by basic.Tokenize, so that the quoted
+// strings and the comments are recognized in the same way as the command
+// parser. Unlike the parser, it never fails: an invalid quoted string extends
+// to the end of line, and is left to the parser to report.
+func splitWords(line string) []basic.Token {
+	words, _ := basic.Tokenize(line)
+	return words
+}
+
+// statement is a statement of a script.
+type statement interface{}
+
+// letStatement assigns the value of an expression to a variable,
+// like "let x = $x + 1".
+type letStatement struct {
+	line  int
+	name  string
+	value expr
+}
+
+// forStatement repeats a block for each value of a variable,
+// like "for i = 1 to 10 step 2".
+type forStatement struct {
+	line int
+	name string
+	from expr
+	to   expr
+	// step is nil if it is omitted.
+	step expr
+
+	// stepOffset is the byte offset of the step in the line.
+	stepOffset int
+
+	body []statement
+}
+
+// ifStatement executes one of two blocks depending on a condition,
+// like "if $i % 2 == 0".
+type ifStatement struct {
+	line     int
+	cond     expr
+	body     []statement
+	elseBody []statement
+}
+
+// callStatement calls a procedure, like "call window 3 $y".
+type callStatement struct {
+	line       int
+	name       string
+	nameOffset int
+	args       []expr
+}
+
+// commandStatement is a command to be parsed by the command parser,
+// after the arguments containing expressions are replaced by their values.
+type commandStatement struct {
+	line int
+	args []argument
+}
+
+// argument is an argument of a commandStatement containing an expression.
+type argument struct {
+	start int
+	end   int
+	value expr
+}
+
+// procedure is a procedure defined by "proc".
+type procedure struct {
+	line   int
+	params []string
+	body   []statement
+}
+
+// Program is a parsed script.
+//
+// A script contains one statement per line. A line starting with one of the
+// keywords "let", "for", "if", "else", "end", "proc", and "call" is a statement
+// of the script, while any other line is a command to be parsed by the command
+// parser. An argument of a command containing "$" is an integer expression,
+// which is replaced by its value before the command is parsed:
+//
+//     let x = 2
+//     for i = 0 to 3
+//         L $x+$i*5 1 $x+$i*5 4
+//     end
+//
+// An expression consists of integers, variables (like "$x"), parentheses,
+// and the operators "+", "-", "*", "/", "%", "==", "!=", "<", "<=", ">", ">=",
+// "&&", "||", and "!", which have the same meanings and precedences as those
+// in Go. A comparison or a logical operation evaluates to 1 if it is true,
+// or 0 otherwise. A condition is true if it is not 0.
+//
+// Statements:
+//
+// "let name = expr" assigns the value of expr to the variable called name.
+//
+// "for name = expr to expr [step expr]" ... "end" repeats the block for each
+// value of the variable called name, from the first expr to the second expr
+// inclusively, with the step (1 by default) added each time.
+//
+// "if expr" ... ["else" ...] "end" executes the block if expr is true,
+// or the else block otherwise.
+//
+// "proc name [param ...]" ... "end" defines a procedure, which could only be
+// defined at the top level. It could be called before it is defined.
+//
+// "call name [expr ...]" calls the procedure called name, with each argument
+// being a whitespace-separated expression, like the arguments of a command.
+// The parameters and the variables first assigned in a procedure are local to
+// the call, while the other variables are global.
+//
+type Program struct {
+	lines      []string
+	statements []statement
+	procedures map[string]*procedure
+}
+
+// scriptParser parses the lines of a script into a Program.
+type scriptParser struct {
+	lines      []string
+	next       int
+	procedures map[string]*procedure
+}
+
+// Parse reads the script from r, and parses it into a Program.
+// The commands are not parsed until the Program is run.
+//
+// If the script could not be parsed, the Program containing the top-level
+// statements before the failing one, and the procedures defined before it,
+// is returned together with the error, so that they could still be run,
+// like the commands before a failing command.
+// nil is returned only if r could not be read.
+//
+// Errors
+//
+// common.ErrInvalidStatement:
+// Will be returned (wrapped in an *Error) if a statement is malformed.
+//
+// common.ErrInvalidExpression:
+// Will be returned (wrapped in an *Error) if an expression is malformed.
+//
+// common.ErrInvalidNumber:
+// Will be returned (wrapped in an *Error) if an integer is out of range.
+//
+// common.ErrBlockNotClosed:
+// Will be returned (wrapped in an *Error) if a block is not closed by "end".
+//
+// common.ErrUnexpectedBlockEnd:
+// Will be returned (wrapped in an *Error) if "end" or "else" does not close a block.
+//
+// common.ErrProcedureRedefined:
+// Will be returned (wrapped in an *Error) if a procedure is defined more than once.
+//
+// Other errors:
+// Will be returned if r could not be read.
+//
+func Parse(r io.Reader) (*Program, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanLines)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	p := &scriptParser{
+		lines:      lines,
+		procedures: make(map[string]*procedure),
+	}
+	statements, keyword, err := p.parseBlock(true)
+	if err == nil && keyword != "" {
+		err = p.failBlockEnd()
+	}
+	prog := &Program{
+		lines:      lines,
+		statements: statements,
+		procedures: p.procedures,
+	}
+	return prog, err
+}
+
+// fail returns the error caused by the token at offset of the line
+// at lineNumber.
+func (p *scriptParser) fail(lineNumber, offset int, err error) *Error {
+	return newError(lineNumber, p.lines[lineNumber-1], offset, err)
+}
+
+// failBlockEnd returns the error caused by the "end" or "else"
+// which has just been parsed, but does not close the current block.
+func (p *scriptParser) failBlockEnd() *Error {
+	words := splitWords(p.lines[p.next-1])
+	return p.fail(p.next, words[0].Start, common.ErrUnexpectedBlockEnd)
+}
+
+// parseBlock parses the statements until "end", "else", or the end of the
+// script, and returns the statements, and the keyword closing the block
+// ("end", "else", or "" for the end of the script).
+// procedures are allowed if topLevel is true.
+// On errors, the statements parsed before the failing one are returned.
+func (p *scriptParser) parseBlock(topLevel bool) ([]statement, string, error) {
+	var statements []statement
+	for p.next < len(p.lines) {
+		line := p.lines[p.next]
+		p.next++
+		lineNumber := p.next
+		words := splitWords(line)
+		if len(words) == 0 {
+			statements = append(statements, commandStatement{lineNumber, nil})
+			continue
+		}
+		keyword := words[0]
+		name := keyword.Text
+		if keyword.Quoted {
+			// NOTE: A quoted word is never a keyword
+			name = ""
+		}
+		switch name {
+		case "end", "else":
+			if len(words) != 1 {
+				return statements, "", p.fail(lineNumber, words[1].Start, common.ErrInvalidStatement)
+			}
+			return statements, keyword.Text, nil
+		case "let":
+			stmt, err := p.parseLet(lineNumber, words)
+			if err != nil {
+				return statements, "", err
+			}
+			statements = append(statements, stmt)
+		case "for":
+			stmt, err := p.parseFor(lineNumber, words)
+			if err != nil {
+				return statements, "", err
+			}
+			statements = append(statements, stmt)
+		case "if":
+			stmt, err := p.parseIf(lineNumber, words)
+			if err != nil {
+				return statements, "", err
+			}
+			statements = append(statements, stmt)
+		case "proc":
+			if !topLevel {
+				return statements, "", p.fail(lineNumber, keyword.Start, common.ErrInvalidStatement)
+			}
+			err := p.parseProc(lineNumber, words)
+			if err != nil {
+				return statements, "", err
+			}
+		case "call":
+			stmt, err := p.parseCall(lineNumber, words)
+			if err != nil {
+				return statements, "", err
+			}
+			statements = append(statements, stmt)
+		default:
+			stmt, err := p.parseCommand(lineNumber, words)
+			if err != nil {
+				return statements, "", err
+			}
+			statements = append(statements, stmt)
+		}
+	}
+	return statements, "", nil
+}
+
+// parseBody parses the block of the statement at lineNumber,
+// which must be closed by "end", or "else" if elseAllowed is true.
+func (p *scriptParser) parseBody(lineNumber int, keyword basic.Token, elseAllowed bool) ([]statement, string, error) {
+	body, closing, err := p.parseBlock(false)
+	if err != nil {
+		return nil, "", err
+	}
+	switch {
+	case closing == "":
+		return nil, "", p.fail(lineNumber, keyword.Start, common.ErrBlockNotClosed)
+	case closing == "else" && !elseAllowed:
+		return nil, "", p.failBlockEnd()
+	}
+	return body, closing, nil
+}
+
+// rest returns the text of the line from words[i] to the last word,
+// and the byte offsets of its start and end in the line.
+func rest(line string, words []basic.Token, i int) (string, int, int) {
+	start, end := words[i].Start, words[len(words)-1].End
+	return line[start:end], start, end
+}
+
+// parseLet parses "let name = expr".
+func (p *scriptParser) parseLet(lineNumber int, words []basic.Token) (statement, error) {
+	line := p.lines[lineNumber-1]
+	if len(words) < 2 {
+		return nil, p.fail(lineNumber, len(line), common.ErrInvalidStatement)
+	}
+	s, start, end := rest(line, words, 1)
+	tokens, err := lexExpr(s, start)
+	if err != nil {
+		return nil, p.fail(lineNumber, wholeStatement, err)
+	}
+	if len(tokens) < 2 || tokens[0].kind != nameToken || tokens[1].text != "=" {
+		return nil, p.fail(lineNumber, words[1].Start, common.ErrInvalidStatement)
+	}
+	value, err := parseExpr(tokens[2:], end)
+	if err != nil {
+		return nil, p.fail(lineNumber, wholeStatement, err)
+	}
+	return letStatement{lineNumber, tokens[0].text, value}, nil
+}
+
+// parseFor parses "for name = expr to expr [step expr]", and the block.
+func (p *scriptParser) parseFor(lineNumber int, words []basic.Token) (statement, error) {
+	line := p.lines[lineNumber-1]
+	if len(words) < 2 {
+		return nil, p.fail(lineNumber, len(line), common.ErrInvalidStatement)
+	}
+	s, start, end := rest(line, words, 1)
+	tokens, err := lexExpr(s, start)
+	if err != nil {
+		return nil, p.fail(lineNumber, wholeStatement, err)
+	}
+	if len(tokens) < 2 || tokens[0].kind != nameToken || tokens[1].text != "=" {
+		return nil, p.fail(lineNumber, words[1].Start, common.ErrInvalidStatement)
+	}
+
+	// Split the expressions at "to" and "step"
+	var segments [][]exprToken
+	var ends []int
+	from := 2
+	for i := 2; i <= len(tokens); i++ {
+		if i < len(tokens) && tokens[i].kind != nameToken {
+			continue
+		}
+		segmentEnd := end
+		if i < len(tokens) {
+			segmentEnd = tokens[i].offset
+			expected := "to"
+			if len(segments) == 1 {
+				expected = "step"
+			}
+			if len(segments) >= 2 || tokens[i].text != expected {
+				return nil, p.fail(lineNumber, tokens[i].offset, common.ErrInvalidStatement)
+			}
+		}
+		segments = append(segments, tokens[from:i])
+		ends = append(ends, segmentEnd)
+		from = i + 1
+	}
+	if len(segments) < 2 {
+		return nil, p.fail(lineNumber, end, common.ErrInvalidStatement)
+	}
+	exprs := make([]expr, len(segments))
+	for i, segment := range segments {
+		exprs[i], err = parseExpr(segment, ends[i])
+		if err != nil {
+			return nil, p.fail(lineNumber, wholeStatement, err)
+		}
+	}
+	stmt := forStatement{
+		line: lineNumber,
+		name: tokens[0].text,
+		from: exprs[0],
+		to:   exprs[1],
+	}
+	if len(exprs) == 3 {
+		stmt.step = exprs[2]
+		stmt.stepOffset = segments[2][0].offset
+	}
+
+	stmt.body, _, err = p.parseBody(lineNumber, words[0], false)
+	if err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// parseIf parses "if expr", and the blocks.
+func (p *scriptParser) parseIf(lineNumber int, words []basic.Token) (statement, error) {
+	line := p.lines[lineNumber-1]
+	if len(words) < 2 {
+		return nil, p.fail(lineNumber, len(line), common.ErrInvalidStatement)
+	}
+	s, start, end := rest(line, words, 1)
+	tokens, err := lexExpr(s, start)
+	if err != nil {
+		return nil, p.fail(lineNumber, wholeStatement, err)
+	}
+	cond, err := parseExpr(tokens, end)
+	if err != nil {
+		return nil, p.fail(lineNumber, wholeStatement, err)
+	}
+	stmt := ifStatement{
+		line: lineNumber,
+		cond: cond,
+	}
+
+	var closing string
+	stmt.body, closing, err = p.parseBody(lineNumber, words[0], true)
+	if err != nil {
+		return nil, err
+	}
+	if closing == "else" {
+		stmt.elseBody, _, err = p.parseBody(lineNumber, words[0], false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stmt, nil
+}
+
+// parseProc parses "proc name [param ...]", and the block.
+func (p *scriptParser) parseProc(lineNumber int, words []basic.Token) error {
+	line := p.lines[lineNumber-1]
+	if len(words) < 2 {
+		return p.fail(lineNumber, len(line), common.ErrInvalidStatement)
+	}
+	for _, w := range words[1:] {
+		if w.Quoted || !isName(w.Text) {
+			return p.fail(lineNumber, w.Start, common.ErrInvalidStatement)
+		}
+	}
+	name := words[1].Text
+	if _, ok := p.procedures[name]; ok {
+		return p.fail(lineNumber, words[1].Start, common.ErrProcedureRedefined)
+	}
+	params := make([]string, len(words)-2)
+	for i, w := range words[2:] {
+		params[i] = w.Text
+	}
+
+	body, _, err := p.parseBody(lineNumber, words[0], false)
+	if err != nil {
+		return err
+	}
+	p.procedures[name] = &procedure{lineNumber, params, body}
+	return nil
+}
+
+// parseCall parses "call name [expr ...]".
+func (p *scriptParser) parseCall(lineNumber int, words []basic.Token) (statement, error) {
+	line := p.lines[lineNumber-1]
+	if len(words) < 2 {
+		return nil, p.fail(lineNumber, len(line), common.ErrInvalidStatement)
+	}
+	if words[1].Quoted || !isName(words[1].Text) {
+		return nil, p.fail(lineNumber, words[1].Start, common.ErrInvalidStatement)
+	}
+	args := make([]expr, len(words)-2)
+	for i, w := range words[2:] {
+		value, err := parseWord(w)
+		if err != nil {
+			return nil, p.fail(lineNumber, wholeStatement, err)
+		}
+		args[i] = value
+	}
+	return callStatement{lineNumber, words[1].Text, words[1].Start, args}, nil
+}
+
+// parseCommand parses a command, whose arguments containing "$"
+// are expressions.
+func (p *scriptParser) parseCommand(lineNumber int, words []basic.Token) (statement, error) {
+	var args []argument
+	for _, w := range words[1:] {
+		if w.Quoted || !strings.Contains(w.Text, "$") {
+			continue
+		}
+		value, err := parseWord(w)
+		if err != nil {
+			return nil, p.fail(lineNumber, wholeStatement, err)
+		}
+		args = append(args, argument{w.Start, w.End, value})
+	}
+	return commandStatement{lineNumber, args}, nil
+}
+
+// parseWord parses w as an expression.
+func parseWord(w basic.Token) (expr, error) {
+	if w.Quoted {
+		return nil, &failure{w.Start, common.ErrInvalidExpression}
+	}
+	tokens, err := lexExpr(w.Text, w.Start)
+	if err != nil {
+		return nil, err
+	}
+	return parseExpr(tokens, w.End)
+}
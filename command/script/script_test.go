@@ -0,0 +1,146 @@
+package script
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+func TestSplitWords(t *testing.T) {
+	// NOTE: Only the words are checked, since the tokens are tested in package basic
+	cases := []struct {
+		s     string
+		words []string
+	}{
+		{"", []string{}},
+		{"  # comment", []string{}},
+		{"L $x 1", []string{"L", "$x", "1"}},
+		{"FG #ff0000 # red", []string{"FG", "#ff0000"}},
+		{"FG # red", []string{"FG", "#", "red"}},
+		{`T 1 1 "a $x\" b" c`, []string{"T", "1", "1", `"a $x\" b"`, "c"}},
+		{`T "open \`, []string{"T", `"open \`}},
+	}
+	for _, c := range cases {
+		words := []string{}
+		for _, w := range splitWords(c.s) {
+			words = append(words, c.s[w.Start:w.End])
+		}
+		if !reflect.DeepEqual(words, c.words) {
+			t.Errorf("Case: %q, Expected: %#v, Got: %#v", c.s, c.words, words)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	// Positive Cases
+	casesPos := []struct {
+		s          string
+		statements int
+		procedures int
+	}{
+		{"", 0, 0},
+		{"C 20 4\n\n# comment\nL 1 2 $x+1 2", 4, 0},
+		{"let x = 1\nlet y=$x*2 # comment", 2, 0},
+		{"for i = 1 to 10\n  L $i 1 $i 1\nend", 1, 0},
+		{"for i = 10 to 1 step -($x)\nend", 1, 0},
+		{"if $x > 1\nL 1 1 1 1\nelse\nif !$x\nend\nend", 1, 0},
+		{"call box 1 $x+1\nproc box x y\nR $x $y $x+1 $y+1\nend", 1, 1},
+		{"proc dot\nend\nproc line a\nend", 0, 2},
+		{`T 1 1 "$x"`, 1, 0},
+	}
+	for _, c := range casesPos {
+		prog, err := Parse(strings.NewReader(c.s))
+		if err != nil {
+			t.Errorf("Case: %q, Expected: err == nil, Got: %#v", c.s, err)
+			continue
+		}
+		if len(prog.statements) != c.statements {
+			t.Errorf("Case: %q, Expected: %d statements, Got: %d", c.s, c.statements, len(prog.statements))
+		}
+		if len(prog.procedures) != c.procedures {
+			t.Errorf("Case: %q, Expected: %d procedures, Got: %d", c.s, c.procedures, len(prog.procedures))
+		}
+	}
+
+	// Negative Cases
+	casesNeg := []struct {
+		s        string
+		expected *Error
+	}{
+		{"let", &Error{1, 4, common.ErrInvalidStatement}},
+		{"let x", &Error{1, 5, common.ErrInvalidStatement}},
+		{"let 1 = 2", &Error{1, 5, common.ErrInvalidStatement}},
+		{"let x = ", &Error{1, 8, common.ErrInvalidExpression}},
+		{"let x = 1 +", &Error{1, 12, common.ErrInvalidExpression}},
+		{"let x = 1 ^ 2", &Error{1, 11, common.ErrInvalidExpression}},
+		{"L 1 1 $x+ 1", &Error{1, 10, common.ErrInvalidExpression}},
+		{"L 1 1 日本$ 1", &Error{1, 7, common.ErrInvalidExpression}},
+		{"for i = 1\nend", &Error{1, 10, common.ErrInvalidStatement}},
+		{"for i = 1 step 2 to 3\nend", &Error{1, 11, common.ErrInvalidStatement}},
+		{"for i = 1 to 2 step\nend", &Error{1, 20, common.ErrInvalidExpression}},
+		{"for i = 1 to 2 step 1 to 3\nend", &Error{1, 23, common.ErrInvalidStatement}},
+		{"for i = 1 to 2", &Error{1, 1, common.ErrBlockNotClosed}},
+		{"for i = 1 to 2\nelse\nend", &Error{2, 1, common.ErrUnexpectedBlockEnd}},
+		{"if", &Error{1, 3, common.ErrInvalidStatement}},
+		{"  if 1\n  else\n  else\nend", &Error{3, 3, common.ErrUnexpectedBlockEnd}},
+		{"if 1\nelse", &Error{1, 1, common.ErrBlockNotClosed}},
+		{"L 1 1 1 1\n  end", &Error{2, 3, common.ErrUnexpectedBlockEnd}},
+		{"else", &Error{1, 1, common.ErrUnexpectedBlockEnd}},
+		{"if 1\nend x", &Error{2, 5, common.ErrInvalidStatement}},
+		{"proc", &Error{1, 5, common.ErrInvalidStatement}},
+		{"proc p 1", &Error{1, 8, common.ErrInvalidStatement}},
+		{"proc p\nend\nproc p\nend", &Error{3, 6, common.ErrProcedureRedefined}},
+		{"if 1\nproc p\nend\nend", &Error{2, 1, common.ErrInvalidStatement}},
+		{"call", &Error{1, 5, common.ErrInvalidStatement}},
+		{"call $p", &Error{1, 6, common.ErrInvalidStatement}},
+		{`call p "1"`, &Error{1, 8, common.ErrInvalidExpression}},
+	}
+	for _, c := range casesNeg {
+		_, err := Parse(strings.NewReader(c.s))
+		serr, ok := err.(*Error)
+		if !ok {
+			t.Errorf("Case: %q, Expected: %#v, Got: %#v", c.s, c.expected, err)
+			continue
+		}
+		if !reflect.DeepEqual(serr, c.expected) {
+			t.Errorf("Case: %q, Expected: %#v, Got: %#v", c.s, c.expected, serr)
+		}
+	}
+}
+
+func TestParse_Prefix(t *testing.T) {
+	// The statements before the syntax error are returned
+	prog, err := Parse(strings.NewReader("C 20 4\nproc p\nend\nL 1 1 1 1\nfor i = 1 to 3\nL 1 1 1 1"))
+	if serr, ok := err.(*Error); !ok || serr.Line != 5 || serr.Err != common.ErrBlockNotClosed {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrBlockNotClosed, err)
+	}
+	if prog == nil {
+		t.Fatalf("Expected: prog != nil, Got: prog == nil")
+	}
+	if len(prog.statements) != 2 || len(prog.procedures) != 1 {
+		t.Errorf("Expected: 2 statements, 1 procedure, Got: %d statements, %d procedures", len(prog.statements), len(prog.procedures))
+	}
+}
+
+func TestNewError(t *testing.T) {
+	cases := []struct {
+		offset int
+		err    error
+		column int
+	}{
+		{0, common.ErrUnknownCommand, 1},
+		{4, common.ErrInvalidNumber, 5},
+		{wholeStatement, common.ErrPointOutsideCanvas, 0},
+		{wholeStatement, &failure{0, common.ErrUndefinedVariable}, 1},
+		{0, &failure{8, common.ErrUndefinedVariable}, 7},
+		{100, common.ErrInvalidStatement, 9},
+	}
+	for _, c := range cases {
+		got := newError(1, "L 1 日 $x", c.offset, c.err)
+		if got.Line != 1 || got.Column != c.column {
+			t.Errorf("Case: %d, %v, Expected: 1:%d, Got: %d:%d", c.offset, c.err, c.column, got.Line, got.Column)
+		}
+	}
+}
@@ -10,12 +10,21 @@ var (
 
 	// ---
 
-	// ErrCanvasNotSupported indicates the canvas is not supported by the renderer.
+	// ErrCanvasNotSupported indicates the canvas is not supported by the renderer or the operation.
 	ErrCanvasNotSupported = errors.New("Canvas not supported")
 
 	// ErrColorNotSupported indicates the color is not supported by the renderer.
 	ErrColorNotSupported = errors.New("Color not supported")
 
+	// ErrScaleNotPositive indicates the scale factor of the renderer is not positive.
+	ErrScaleNotPositive = errors.New("'scale' not positive")
+
+	// ErrColorModeNotSupported indicates the color mode is not supported by the renderer.
+	ErrColorModeNotSupported = errors.New("Color mode not supported")
+
+	// ErrUnknownRenderer indicates the name of the renderer is not recognized.
+	ErrUnknownRenderer = errors.New("Unknown renderer")
+
 	// ---
 
 	// ErrEnvironmentNotSupported indicates the environment is not supported by the interpreter.
@@ -27,6 +36,42 @@ var (
 	// ErrCanvasNotCreated indicates the canvas is not created where a command needs it.
 	ErrCanvasNotCreated = errors.New("Canvas not created")
 
+	// ErrNothingToUndo indicates there is no command to be undone.
+	ErrNothingToUndo = errors.New("Nothing to undo")
+
+	// ErrNothingToRedo indicates there is no command to be redone.
+	ErrNothingToRedo = errors.New("Nothing to redo")
+
+	// ErrHistoryDepthNegative indicates the depth of the history is negative.
+	ErrHistoryDepthNegative = errors.New("'depth' negative")
+
+	// ErrHistoryPixelsNotPositive indicates the maximum number of pixels in the history is not positive.
+	ErrHistoryPixelsNotPositive = errors.New("'pixels' not positive")
+
+	// ErrCanvasTooLarge indicates the width or height of the canvas exceeds the limit of the environment.
+	ErrCanvasTooLarge = errors.New("Canvas too large")
+
+	// ErrFileFormatNotSupported indicates the format of the file is not supported by the environment.
+	ErrFileFormatNotSupported = errors.New("File format not supported")
+
+	// ErrMacroRecording indicates a macro is being recorded where a command needs none.
+	ErrMacroRecording = errors.New("Macro being recorded")
+
+	// ErrMacroNotRecording indicates no macro is being recorded where a command needs one.
+	ErrMacroNotRecording = errors.New("Macro not being recorded")
+
+	// ErrMacroNotFound indicates the macro specified does not exist.
+	ErrMacroNotFound = errors.New("Macro not found")
+
+	// ErrTooManyMacroCommands indicates the number of commands in the macros exceeds the limit of the environment.
+	ErrTooManyMacroCommands = errors.New("Too many macro commands")
+
+	// ErrMacroLimitNotPositive indicates the maximum number of commands in the macros is not positive.
+	ErrMacroLimitNotPositive = errors.New("'limit' not positive")
+
+	// ErrClipboardEmpty indicates nothing has been copied to the clipboard where a command needs it.
+	ErrClipboardEmpty = errors.New("Clipboard empty")
+
 	// ---
 
 	// ErrUnknownCommand indicates the command is not recognized by the command parser.
@@ -38,6 +83,44 @@ var (
 	// ErrInvalidNumber indicates an argument could not be parsed to a number.
 	ErrInvalidNumber = errors.New("Invalid number")
 
+	// ErrInvalidString indicates an argument could not be parsed to a string.
+	ErrInvalidString = errors.New("Invalid string")
+
+	// ErrInvalidAngle indicates an argument is not a supported angle of rotation.
+	ErrInvalidAngle = errors.New("Invalid angle")
+
+	// ---
+
+	// ErrInvalidStatement indicates the statement of the script is malformed.
+	ErrInvalidStatement = errors.New("Invalid statement")
+
+	// ErrInvalidExpression indicates the expression of the script is malformed.
+	ErrInvalidExpression = errors.New("Invalid expression")
+
+	// ErrBlockNotClosed indicates the block of the script is not closed by "end".
+	ErrBlockNotClosed = errors.New("Block not closed")
+
+	// ErrUnexpectedBlockEnd indicates "end" or "else" appears outside the block it belongs to.
+	ErrUnexpectedBlockEnd = errors.New("Unexpected end of block")
+
+	// ErrProcedureRedefined indicates the procedure of the script is defined more than once.
+	ErrProcedureRedefined = errors.New("Procedure redefined")
+
+	// ErrUndefinedVariable indicates the variable referenced is not defined.
+	ErrUndefinedVariable = errors.New("Undefined variable")
+
+	// ErrUndefinedProcedure indicates the procedure called is not defined.
+	ErrUndefinedProcedure = errors.New("Undefined procedure")
+
+	// ErrDivisionByZero indicates the divisor of the expression is zero.
+	ErrDivisionByZero = errors.New("Division by zero")
+
+	// ErrStepZero indicates the step of the loop is zero.
+	ErrStepZero = errors.New("'step' zero")
+
+	// ErrRecursionTooDeep indicates the procedure calls are nested too deeply.
+	ErrRecursionTooDeep = errors.New("Recursion too deep")
+
 	// ---
 
 	// ErrWidthOrHeightNotPositive indicates the width or height of the canvas is not positive.
@@ -52,8 +135,80 @@ var (
 	// ErrLineNotHorizontalOrVertical indicates the line specified is not horizontal or vertical.
 	ErrLineNotHorizontalOrVertical = errors.New("Line not horizontal or vertical")
 
+	// ErrRadiusNegative indicates the radius of the circle or ellipse is negative.
+	ErrRadiusNegative = errors.New("'radius' negative")
+
+	// ErrTooFewPoints indicates the polyline or polygon does not have enough points.
+	ErrTooFewPoints = errors.New("Too few points")
+
+	// ErrFillRuleNotSupported indicates the fill rule is not supported by the canvas.
+	ErrFillRuleNotSupported = errors.New("Fill rule not supported")
+
+	// ErrLineModeNotSupported indicates the line mode is not supported by the canvas.
+	ErrLineModeNotSupported = errors.New("Line mode not supported")
+
+	// ErrTextModeNotSupported indicates the text mode is not supported by the canvas.
+	ErrTextModeNotSupported = errors.New("Text mode not supported")
+
+	// ErrToleranceNegative indicates the color tolerance of the flood fill is negative.
+	ErrToleranceNegative = errors.New("'tolerance' negative")
+
+	// ErrToleranceNotSupported indicates the color tolerance of the flood fill is not supported by the canvas.
+	ErrToleranceNotSupported = errors.New("Tolerance not supported")
+
+	// ErrExtentNegative indicates the extent by which the canvas is extended is negative.
+	ErrExtentNegative = errors.New("'extent' negative")
+
+	// ErrTransformNotSupported indicates the transform is not supported by the canvas.
+	ErrTransformNotSupported = errors.New("Transform not supported")
+
 	// ---
 
 	// ErrInvalidColor indicates the argument could not be parseed to a color value.
 	ErrInvalidColor = errors.New("Invalid color")
+
+	// ---
+
+	// ErrSessionNotFound indicates the session specified does not exist.
+	ErrSessionNotFound = errors.New("Session not found")
+
+	// ErrUnknownFormat indicates the format of the canvas is not recognized by the server.
+	ErrUnknownFormat = errors.New("Unknown format")
+
+	// ErrMethodNotAllowed indicates the HTTP method is not allowed for the resource.
+	ErrMethodNotAllowed = errors.New("Method not allowed")
+
+	// ErrResourceNotFound indicates the HTTP resource does not exist.
+	ErrResourceNotFound = errors.New("Resource not found")
+
+	// ErrTooManySessions indicates the number of sessions has reached the limit of the server.
+	ErrTooManySessions = errors.New("Too many sessions")
+
+	// ErrSessionLimitNotPositive indicates the maximum number of sessions is not positive.
+	ErrSessionLimitNotPositive = errors.New("'limit' not positive")
+
+	// ErrTimeoutNotPositive indicates the idle timeout of the sessions is not positive.
+	ErrTimeoutNotPositive = errors.New("'timeout' not positive")
+
+	// ErrBadHandshake indicates the WebSocket opening handshake is invalid.
+	ErrBadHandshake = errors.New("Bad handshake")
+
+	// ErrOriginNotAllowed indicates the WebSocket opening handshake
+	// is sent from a page of another origin.
+	ErrOriginNotAllowed = errors.New("Origin not allowed")
+
+	// ErrProtocolViolation indicates the WebSocket frame violates the protocol.
+	ErrProtocolViolation = errors.New("Protocol violation")
+
+	// ErrMessageNotText indicates the WebSocket message is not a text message.
+	ErrMessageNotText = errors.New("Message not text")
+
+	// ErrMessageTooBig indicates the WebSocket message is too big.
+	ErrMessageTooBig = errors.New("Message too big")
+
+	// ErrMessageNotUTF8 indicates the WebSocket text message is not valid UTF-8.
+	ErrMessageNotUTF8 = errors.New("Message not UTF-8")
+
+	// ErrConnectionClosed indicates the WebSocket connection is already closed.
+	ErrConnectionClosed = errors.New("Connection closed")
 )
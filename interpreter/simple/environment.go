@@ -1,7 +1,15 @@
 package simple
 
 import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
 	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/color"
+	"github.com/asukakenji/drawing-challenge/command"
 	"github.com/asukakenji/drawing-challenge/common"
 	"github.com/asukakenji/drawing-challenge/renderer"
 )
@@ -15,6 +23,18 @@ type CanvasContainer interface {
 	NewCanvas(width, height int) error
 }
 
+// CanvasLimiter limits the dimensions of the canvases.
+type CanvasLimiter interface {
+	// CheckCanvasSize checks whether a canvas of width x height is allowed.
+	//
+	// Errors
+	//
+	// common.ErrCanvasTooLarge:
+	// Will be returned if width or height exceeds the limit.
+	//
+	CheckCanvasSize(width, height int) error
+}
+
 // Quitter is a container of a bool which determines if the program should quit.
 type Quitter interface {
 	// ShouldQuit returns if the program should quit.
@@ -24,17 +44,156 @@ type Quitter interface {
 	SetQuit()
 }
 
+// Historian is a container of the history of the canvas,
+// which allows the changes of the canvas to be undone and redone.
+type Historian interface {
+	// HistoryDepth returns the maximum number of changes which could be undone.
+	// No canvas is saved to the history if it is 0.
+	HistoryDepth() int
+
+	// SaveHistory saves cnv, which is the canvas before a change,
+	// to the history. The changes which have been undone could no longer
+	// be redone hereafter.
+	SaveHistory(cnv canvas.Canvas)
+
+	// Undo replaces the contained canvas by the one saved most recently.
+	//
+	// Errors
+	//
+	// common.ErrNothingToUndo:
+	// Will be returned if there is no canvas in the history.
+	//
+	Undo() error
+
+	// Redo reverts the effect of the most recent Undo.
+	//
+	// Errors
+	//
+	// common.ErrNothingToRedo:
+	// Will be returned if there is no undone change.
+	//
+	Redo() error
+}
+
+// Saver saves canvases to files.
+type Saver interface {
+	// Save saves cnv to the file called fileName.
+	//
+	// Errors
+	//
+	// common.ErrFileFormatNotSupported:
+	// Will be returned if the format of the file is not supported.
+	//
+	Save(cnv canvas.Canvas, fileName string) error
+}
+
+// MacroRecorder is a container of macros,
+// which are commands recorded to be replayed later.
+type MacroRecorder interface {
+	// StartRecording starts recording the commands to the macro called name.
+	//
+	// Errors
+	//
+	// common.ErrMacroRecording:
+	// Will be returned if a macro is being recorded.
+	//
+	StartRecording(name string) error
+
+	// Record appends cmd to the macro being recorded.
+	// It does nothing if no macro is being recorded.
+	Record(cmd command.Command)
+
+	// StopRecording stops recording, and saves the macro recorded.
+	// The macro with the same name, if any, is replaced.
+	//
+	// Errors
+	//
+	// common.ErrMacroNotRecording:
+	// Will be returned if no macro is being recorded.
+	//
+	StopRecording() error
+
+	// Macro returns the commands recorded to the macro called name.
+	//
+	// Errors
+	//
+	// common.ErrMacroNotFound:
+	// Will be returned if there is no macro called name.
+	//
+	Macro(name string) ([]command.Command, error)
+}
+
+// MacroLimiter limits the number of commands in the macros.
+type MacroLimiter interface {
+	// CheckMacroCommands checks whether n more commands could be recorded
+	// to the macro being recorded.
+	//
+	// Errors
+	//
+	// common.ErrTooManyMacroCommands:
+	// Will be returned if the number of commands exceeds the limit.
+	//
+	CheckMacroCommands(n int) error
+}
+
+// Clip is a rectangular region copied from a canvas.
+// Pixels holds the colors of the region row by row,
+// so there are Width * Height of them.
+type Clip struct {
+	Width  int
+	Height int
+	Pixels []color.Color
+}
+
+// Clipboard is a container of a Clip,
+// which could be pasted to the canvas later.
+type Clipboard interface {
+	// SetClipboard replaces the content of the clipboard by clip.
+	SetClipboard(clip *Clip)
+
+	// Clipboard returns the content of the clipboard.
+	//
+	// Errors
+	//
+	// common.ErrClipboardEmpty:
+	// Will be returned if nothing has been copied to the clipboard.
+	//
+	Clipboard() (*Clip, error)
+}
+
+// DefaultHistoryDepth is the default maximum number of changes
+// which could be undone.
+const DefaultHistoryDepth = 100
+
 // Environment is a simple environment for the interpreter.
-// It implements the CanvasContainer interface
-// and the renderer.Renderer interface.
+// It implements the CanvasContainer interface, the CanvasLimiter interface,
+// the renderer.Renderer interface, the Quitter interface,
+// the Historian interface, the Saver interface,
+// the MacroRecorder interface, the MacroLimiter interface,
+// and the Clipboard interface.
 type Environment struct {
-	newCanvasFunc func(int, int) (canvas.Canvas, error)
-	cnv           canvas.Canvas
-	rdr           renderer.Renderer
-	shouldQuit    bool
+	newCanvasFunc    func(int, int) (canvas.Canvas, error)
+	cnv              canvas.Canvas
+	rdr              renderer.Renderer
+	shouldQuit       bool
+	maxWidth         int
+	maxHeight        int
+	historyDepth     int
+	maxHistoryPixels int
+	undoHistory      []canvas.Canvas
+	redoHistory      []canvas.Canvas
+	fileRdrFuncs     map[string]func(io.Writer) (renderer.Renderer, error)
+	recording        bool
+	recordingName    string
+	recorded         []command.Command
+	macros           map[string][]command.Command
+	macroCommands    int
+	maxMacroCommands int
+	clip             *Clip
 }
 
 // NewEnvironment returns a new Environment.
+// The history depth of the new Environment is DefaultHistoryDepth.
 //
 // Errors
 //
@@ -51,6 +210,7 @@ func NewEnvironment(newCanvasFunc func(int, int) (canvas.Canvas, error), rdr ren
 	return &Environment{
 		newCanvasFunc: newCanvasFunc,
 		rdr:           rdr,
+		historyDepth:  DefaultHistoryDepth,
 	}, nil
 }
 
@@ -60,7 +220,20 @@ func (env *Environment) Canvas() canvas.Canvas {
 }
 
 // NewCanvas creates a new canvas.Canvas.
+//
+// Errors
+//
+// common.ErrCanvasTooLarge:
+// Will be returned if width or height exceeds the limit set by SetMaxCanvasSize.
+//
+// Errors returned from the newCanvasFunc function are returned without
+// modifications.
+//
 func (env *Environment) NewCanvas(width, height int) error {
+	err := env.CheckCanvasSize(width, height)
+	if err != nil {
+		return err
+	}
 	cnv, err := env.newCanvasFunc(width, height)
 	if err != nil {
 		return err
@@ -69,6 +242,38 @@ func (env *Environment) NewCanvas(width, height int) error {
 	return nil
 }
 
+// SetMaxCanvasSize limits the width and height of the canvases
+// created or resized hereafter. The canvases are not limited by default.
+//
+// Errors
+//
+// common.ErrWidthOrHeightNotPositive:
+// Will be returned if width <= 0, or height <= 0.
+//
+func (env *Environment) SetMaxCanvasSize(width, height int) error {
+	if width <= 0 || height <= 0 {
+		return common.ErrWidthOrHeightNotPositive
+	}
+	env.maxWidth = width
+	env.maxHeight = height
+	return nil
+}
+
+// CheckCanvasSize checks whether a canvas of width x height is allowed.
+//
+// Errors
+//
+// common.ErrCanvasTooLarge:
+// Will be returned if width or height exceeds the limit set by SetMaxCanvasSize.
+//
+func (env *Environment) CheckCanvasSize(width, height int) error {
+	// NOTE: The limit is 0 if SetMaxCanvasSize has not been called
+	if env.maxWidth > 0 && (width > env.maxWidth || height > env.maxHeight) {
+		return common.ErrCanvasTooLarge
+	}
+	return nil
+}
+
 // Render renders cnv.
 func (env *Environment) Render(cnv canvas.Canvas) error {
 	return env.rdr.Render(cnv)
@@ -83,3 +288,308 @@ func (env *Environment) ShouldQuit() bool {
 func (env *Environment) SetQuit() {
 	env.shouldQuit = true
 }
+
+// HistoryDepth returns the maximum number of changes which could be undone.
+func (env *Environment) HistoryDepth() int {
+	return env.historyDepth
+}
+
+// SetHistoryDepth sets the maximum number of changes which could be undone.
+// The oldest changes are discarded if there are more changes in the history.
+// Setting depth to 0 disables the history.
+//
+// Errors
+//
+// common.ErrHistoryDepthNegative:
+// Will be returned if depth < 0.
+//
+func (env *Environment) SetHistoryDepth(depth int) error {
+	if depth < 0 {
+		return common.ErrHistoryDepthNegative
+	}
+	env.historyDepth = depth
+	env.undoHistory = env.trimHistory(env.undoHistory)
+	env.redoHistory = env.trimHistory(env.redoHistory)
+	return nil
+}
+
+// SetMaxHistoryPixels limits the total number of pixels of the canvases
+// in the history of undo, and in the history of redo, so that the history
+// of large canvases is shallower than the history depth.
+// The oldest changes are discarded if there are more pixels in the history,
+// so a change of a canvas larger than pixels could not be undone or redone.
+// The history is not limited by pixels by default.
+//
+// Errors
+//
+// common.ErrHistoryPixelsNotPositive:
+// Will be returned if pixels <= 0.
+//
+func (env *Environment) SetMaxHistoryPixels(pixels int) error {
+	if pixels <= 0 {
+		return common.ErrHistoryPixelsNotPositive
+	}
+	env.maxHistoryPixels = pixels
+	env.undoHistory = env.trimHistory(env.undoHistory)
+	env.redoHistory = env.trimHistory(env.redoHistory)
+	return nil
+}
+
+// trimHistory discards the oldest canvases in history,
+// so that there are at most historyDepth canvases,
+// with at most maxHistoryPixels pixels in total.
+func (env *Environment) trimHistory(history []canvas.Canvas) []canvas.Canvas {
+	n := 0
+	pixels := 0
+	for i := len(history) - 1; i >= 0 && n < env.historyDepth; i-- {
+		// NOTE: The limit is 0 if SetMaxHistoryPixels has not been called.
+		// The canvas is nil if the change created the first canvas.
+		if env.maxHistoryPixels > 0 && history[i] != nil {
+			width, height := history[i].Dimensions()
+			pixels += width * height
+			if pixels > env.maxHistoryPixels {
+				break
+			}
+		}
+		n++
+	}
+	if len(history) == n {
+		return history
+	}
+	// NOTE: Copied to allow the discarded canvases to be garbage collected
+	trimmed := make([]canvas.Canvas, n)
+	copy(trimmed, history[len(history)-n:])
+	return trimmed
+}
+
+// SaveHistory saves cnv, which is the canvas before a change,
+// to the history. The changes which have been undone could no longer
+// be redone hereafter.
+func (env *Environment) SaveHistory(cnv canvas.Canvas) {
+	env.undoHistory = env.trimHistory(append(env.undoHistory, cnv))
+	env.redoHistory = nil
+}
+
+// Undo replaces the contained canvas by the one saved most recently.
+//
+// Errors
+//
+// common.ErrNothingToUndo:
+// Will be returned if there is no canvas in the history.
+//
+func (env *Environment) Undo() error {
+	n := len(env.undoHistory)
+	if n == 0 {
+		return common.ErrNothingToUndo
+	}
+	env.redoHistory = env.trimHistory(append(env.redoHistory, env.cnv))
+	env.cnv = env.undoHistory[n-1]
+	env.undoHistory[n-1] = nil
+	env.undoHistory = env.undoHistory[:n-1]
+	return nil
+}
+
+// Redo reverts the effect of the most recent Undo.
+//
+// Errors
+//
+// common.ErrNothingToRedo:
+// Will be returned if there is no undone change.
+//
+func (env *Environment) Redo() error {
+	n := len(env.redoHistory)
+	if n == 0 {
+		return common.ErrNothingToRedo
+	}
+	env.undoHistory = env.trimHistory(append(env.undoHistory, env.cnv))
+	env.cnv = env.redoHistory[n-1]
+	env.redoHistory[n-1] = nil
+	env.redoHistory = env.redoHistory[:n-1]
+	return nil
+}
+
+// SetFileRendererFunc sets the function which creates the renderer
+// for saving canvases to files with the extension ext (like ".png").
+// The extension is case-insensitive.
+// Setting newRendererFunc to nil disables saving to such files.
+func (env *Environment) SetFileRendererFunc(ext string, newRendererFunc func(io.Writer) (renderer.Renderer, error)) {
+	ext = strings.ToLower(ext)
+	if newRendererFunc == nil {
+		delete(env.fileRdrFuncs, ext)
+		return
+	}
+	if env.fileRdrFuncs == nil {
+		env.fileRdrFuncs = make(map[string]func(io.Writer) (renderer.Renderer, error))
+	}
+	env.fileRdrFuncs[ext] = newRendererFunc
+}
+
+// Save saves cnv to the file called fileName.
+// The renderer is picked according to the extension of fileName.
+// The file is not created if cnv could not be rendered.
+//
+// Errors
+//
+// common.ErrFileFormatNotSupported:
+// Will be returned if no renderer is set for the extension of fileName.
+//
+// Errors returned from the function creating the renderer,
+// the renderer's Render method, and the file system
+// are returned without modifications.
+//
+func (env *Environment) Save(cnv canvas.Canvas, fileName string) error {
+	newRendererFunc, ok := env.fileRdrFuncs[strings.ToLower(filepath.Ext(fileName))]
+	if !ok {
+		return common.ErrFileFormatNotSupported
+	}
+	buf := new(bytes.Buffer)
+	rdr, err := newRendererFunc(buf)
+	if err != nil {
+		return err
+	}
+	err = rdr.Render(cnv)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName, buf.Bytes(), 0666)
+}
+
+// StartRecording starts recording the commands to the macro called name.
+//
+// Errors
+//
+// common.ErrMacroRecording:
+// Will be returned if a macro is being recorded.
+//
+// common.ErrTooManyMacroCommands:
+// Will be returned if there is no macro called name,
+// but a new macro exceeds the limit set by SetMaxMacroCommands.
+//
+func (env *Environment) StartRecording(name string) error {
+	if env.recording {
+		return common.ErrMacroRecording
+	}
+	_, ok := env.macros[name]
+	if !ok && env.maxMacroCommands > 0 && env.macroCommands >= env.maxMacroCommands {
+		return common.ErrTooManyMacroCommands
+	}
+	env.recording = true
+	env.recordingName = name
+	env.recorded = nil
+	return nil
+}
+
+// Record appends cmd to the macro being recorded.
+// It does nothing if no macro is being recorded.
+func (env *Environment) Record(cmd command.Command) {
+	if env.recording {
+		env.recorded = append(env.recorded, cmd)
+	}
+}
+
+// StopRecording stops recording, and saves the macro recorded.
+// The macro with the same name, if any, is replaced.
+//
+// Errors
+//
+// common.ErrMacroNotRecording:
+// Will be returned if no macro is being recorded.
+//
+func (env *Environment) StopRecording() error {
+	if !env.recording {
+		return common.ErrMacroNotRecording
+	}
+	if env.macros == nil {
+		env.macros = make(map[string][]command.Command)
+	}
+	env.macroCommands = env.recordingCommands()
+	env.macros[env.recordingName] = env.recorded
+	env.recording = false
+	env.recordingName = ""
+	env.recorded = nil
+	return nil
+}
+
+// Macro returns the commands recorded to the macro called name.
+//
+// Errors
+//
+// common.ErrMacroNotFound:
+// Will be returned if there is no macro called name.
+//
+func (env *Environment) Macro(name string) ([]command.Command, error) {
+	cmds, ok := env.macros[name]
+	if !ok {
+		return nil, common.ErrMacroNotFound
+	}
+	return cmds, nil
+}
+
+// SetMaxMacroCommands limits the total number of commands in the macros,
+// including the one being recorded. Each macro counts as one more command,
+// so that the number of macros is limited too. Since a replayed macro is
+// recorded as the commands in it, this also limits the number of commands
+// a replayed macro expands to. The macros are not limited by default.
+//
+// Errors
+//
+// common.ErrMacroLimitNotPositive:
+// Will be returned if n <= 0.
+//
+func (env *Environment) SetMaxMacroCommands(n int) error {
+	if n <= 0 {
+		return common.ErrMacroLimitNotPositive
+	}
+	env.maxMacroCommands = n
+	return nil
+}
+
+// CheckMacroCommands checks whether n more commands could be recorded
+// to the macro being recorded. It always succeeds if no macro is being
+// recorded.
+//
+// Errors
+//
+// common.ErrTooManyMacroCommands:
+// Will be returned if the number of commands exceeds the limit
+// set by SetMaxMacroCommands.
+//
+func (env *Environment) CheckMacroCommands(n int) error {
+	// NOTE: The limit is 0 if SetMaxMacroCommands has not been called
+	if !env.recording || env.maxMacroCommands <= 0 {
+		return nil
+	}
+	if n > env.maxMacroCommands-env.recordingCommands() {
+		return common.ErrTooManyMacroCommands
+	}
+	return nil
+}
+
+// recordingCommands returns the total number of commands in the macros
+// if the macro being recorded is saved now.
+func (env *Environment) recordingCommands() int {
+	n := env.macroCommands + len(env.recorded) + 1
+	if cmds, ok := env.macros[env.recordingName]; ok {
+		n -= len(cmds) + 1
+	}
+	return n
+}
+
+// SetClipboard replaces the content of the clipboard by clip.
+func (env *Environment) SetClipboard(clip *Clip) {
+	env.clip = clip
+}
+
+// Clipboard returns the content of the clipboard.
+//
+// Errors
+//
+// common.ErrClipboardEmpty:
+// Will be returned if nothing has been copied to the clipboard.
+//
+func (env *Environment) Clipboard() (*Clip, error) {
+	if env.clip == nil {
+		return nil, common.ErrClipboardEmpty
+	}
+	return env.clip, nil
+}
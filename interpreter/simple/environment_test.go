@@ -1,9 +1,22 @@
 package simple
 
 import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
+	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/color"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/command"
+	"github.com/asukakenji/drawing-challenge/command/basic"
 	"github.com/asukakenji/drawing-challenge/common"
+	"github.com/asukakenji/drawing-challenge/renderer"
+	"github.com/asukakenji/drawing-challenge/renderer/writer"
 )
 
 func TestNewEnvironment(t *testing.T) {
@@ -52,6 +65,46 @@ func TestEnvironment_NewCanvas(t *testing.T) {
 	}
 }
 
+func TestEnvironment_SetMaxCanvasSize(t *testing.T) {
+	env, err := NewEnvironment(newCanvasFunc, &mockRenderer{})
+	if err != nil {
+		panic(err)
+	}
+
+	err = env.SetMaxCanvasSize(4, 0)
+	if err != common.ErrWidthOrHeightNotPositive {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 0, common.ErrWidthOrHeightNotPositive, err)
+	}
+	err = env.NewCanvas(100, 100)
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 1, nil, err)
+	}
+	err = env.SetMaxCanvasSize(4, 2)
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 2, nil, err)
+	}
+
+	cases := []struct {
+		width  int
+		height int
+		err    error
+	}{
+		{4, 2, nil},
+		{5, 2, common.ErrCanvasTooLarge},
+		{4, 3, common.ErrCanvasTooLarge},
+	}
+	for _, c := range cases {
+		err = env.CheckCanvasSize(c.width, c.height)
+		if err != c.err {
+			t.Errorf("Case: CheckCanvasSize(%d, %d), Expected: %#v, Got: %#v", c.width, c.height, c.err, err)
+		}
+		err = env.NewCanvas(c.width, c.height)
+		if err != c.err {
+			t.Errorf("Case: NewCanvas(%d, %d), Expected: %#v, Got: %#v", c.width, c.height, c.err, err)
+		}
+	}
+}
+
 func TestEnvironment_Render(t *testing.T) {
 	env, err := NewEnvironment(newCanvasFunc, &mockRenderer{})
 	if err != nil {
@@ -95,3 +148,351 @@ func TestEnvironment_SetQuit(t *testing.T) {
 		t.Errorf("Case #%d: Expected: %t, Got: %t", 0, expected, got)
 	}
 }
+
+func TestEnvironment_SetHistoryDepth(t *testing.T) {
+	env, err := NewEnvironment(newCanvasFunc, &mockRenderer{})
+	if err != nil {
+		panic(err)
+	}
+	if depth := env.HistoryDepth(); depth != DefaultHistoryDepth {
+		t.Errorf("Case: Default, Expected: %d, Got: %d", DefaultHistoryDepth, depth)
+	}
+	for i := 0; i < 5; i++ {
+		env.SaveHistory(nil)
+	}
+
+	err = env.SetHistoryDepth(-1)
+	if err != common.ErrHistoryDepthNegative {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 0, common.ErrHistoryDepthNegative, err)
+	}
+
+	err = env.SetHistoryDepth(2)
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 1, nil, err)
+	}
+	if depth := env.HistoryDepth(); depth != 2 {
+		t.Errorf("Case: Set, Expected: %d, Got: %d", 2, depth)
+	}
+	env.SaveHistory(nil)
+	for i := 0; i < 2; i++ {
+		err = env.Undo()
+		if err != nil {
+			t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 2+i, nil, err)
+		}
+	}
+	err = env.Undo()
+	if err != common.ErrNothingToUndo {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 4, common.ErrNothingToUndo, err)
+	}
+
+	err = env.SetHistoryDepth(1)
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 5, nil, err)
+	}
+	err = env.Redo()
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 6, nil, err)
+	}
+	err = env.Redo()
+	if err != common.ErrNothingToRedo {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 7, common.ErrNothingToRedo, err)
+	}
+
+	err = env.SetHistoryDepth(0)
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 8, nil, err)
+	}
+	env.SaveHistory(nil)
+	err = env.Undo()
+	if err != common.ErrNothingToUndo {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 9, common.ErrNothingToUndo, err)
+	}
+}
+
+func TestEnvironment_SetMaxHistoryPixels(t *testing.T) {
+	env, err := NewEnvironment(newCanvasFunc, &mockRenderer{})
+	if err != nil {
+		panic(err)
+	}
+	err = env.SetMaxHistoryPixels(0)
+	if err != common.ErrHistoryPixelsNotPositive {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 0, common.ErrHistoryPixelsNotPositive, err)
+	}
+
+	// 3 canvases of 2x2 pixels are kept in the history
+	err = env.SetMaxHistoryPixels(12)
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 1, nil, err)
+	}
+	err = env.NewCanvas(2, 2)
+	if err != nil {
+		panic(err)
+	}
+	env.SaveHistory(nil)
+	for i := 0; i < 4; i++ {
+		env.SaveHistory(env.Canvas().Clone())
+	}
+	for i := 0; i < 3; i++ {
+		err = env.Undo()
+		if err != nil {
+			t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 2+i, nil, err)
+		}
+	}
+	err = env.Undo()
+	if err != common.ErrNothingToUndo {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 5, common.ErrNothingToUndo, err)
+	}
+
+	// Only 1 canvas of 3x3 pixels is kept in the history
+	err = env.NewCanvas(3, 3)
+	if err != nil {
+		panic(err)
+	}
+	for i := 0; i < 2; i++ {
+		env.SaveHistory(env.Canvas().Clone())
+	}
+	err = env.Undo()
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 6, nil, err)
+	}
+	err = env.Undo()
+	if err != common.ErrNothingToUndo {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 7, common.ErrNothingToUndo, err)
+	}
+
+	// The history of a canvas larger than the limit is discarded
+	err = env.NewCanvas(4, 4)
+	if err != nil {
+		panic(err)
+	}
+	env.SaveHistory(env.Canvas().Clone())
+	err = env.Undo()
+	if err != common.ErrNothingToUndo {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 8, common.ErrNothingToUndo, err)
+	}
+}
+
+func TestEnvironment_Save(t *testing.T) {
+	dir, err := ioutil.TempDir("", "simple")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	env, err := NewEnvironment(newCanvasFunc, &mockRenderer{})
+	if err != nil {
+		panic(err)
+	}
+	err = env.NewCanvas(2, 1)
+	if err != nil {
+		panic(err)
+	}
+	errNewRenderer := errors.New("new renderer")
+	env.SetFileRendererFunc(".TXT", func(w io.Writer) (renderer.Renderer, error) {
+		return writer.NewRenderer(w)
+	})
+	env.SetFileRendererFunc(".bad", func(w io.Writer) (renderer.Renderer, error) {
+		return nil, errNewRenderer
+	})
+	env.SetFileRendererFunc(".gone", func(w io.Writer) (renderer.Renderer, error) {
+		return writer.NewRenderer(w)
+	})
+	env.SetFileRendererFunc(".gone", nil)
+
+	// Positive Cases
+	fileName := filepath.Join(dir, "canvas.txt")
+	err = env.Save(env.Canvas(), fileName)
+	if err != nil {
+		t.Errorf("Case: %s, Expected: err == nil, Got: %#v", fileName, err)
+	}
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Errorf("Case: %s, Expected: err == nil, Got: %#v", fileName, err)
+	}
+	expected := "----\n|  |\n----\n\n"
+	if string(content) != expected {
+		t.Errorf("Case: %s, Expected: %#v, Got: %#v", fileName, expected, string(content))
+	}
+
+	// Negative Cases
+	casesNeg := []struct {
+		cnv      canvas.Canvas
+		fileName string
+		err      error
+	}{
+		{env.Canvas(), "canvas.png", common.ErrFileFormatNotSupported},
+		{env.Canvas(), "canvas", common.ErrFileFormatNotSupported},
+		{env.Canvas(), "canvas.gone", common.ErrFileFormatNotSupported},
+		{env.Canvas(), "canvas.bad", errNewRenderer},
+		{nil, "nil.txt", common.ErrCanvasNotSupported},
+	}
+	for _, c := range casesNeg {
+		fileName := filepath.Join(dir, c.fileName)
+		err = env.Save(c.cnv, fileName)
+		if err != c.err {
+			t.Errorf("Case: %s, Expected: %#v, Got: %#v", c.fileName, c.err, err)
+		}
+		if _, err := os.Stat(fileName); !os.IsNotExist(err) {
+			t.Errorf("Case: %s, Expected: file not created, Got: %#v", c.fileName, err)
+		}
+	}
+}
+
+func TestEnvironment_Macro(t *testing.T) {
+	env, err := NewEnvironment(newCanvasFunc, &mockRenderer{})
+	if err != nil {
+		panic(err)
+	}
+	line := basic.DrawLineCommand{X1: 1, Y1: 1, X2: 2, Y2: 1}
+	fill := basic.BucketFillCommand{X: 1, Y: 1, C: bytecolor.Color('o')}
+
+	err = env.StopRecording()
+	if err != common.ErrMacroNotRecording {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 0, common.ErrMacroNotRecording, err)
+	}
+	env.Record(line)
+	_, err = env.Macro("m")
+	if err != common.ErrMacroNotFound {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 1, common.ErrMacroNotFound, err)
+	}
+
+	err = env.StartRecording("m")
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 2, nil, err)
+	}
+	err = env.StartRecording("n")
+	if err != common.ErrMacroRecording {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 3, common.ErrMacroRecording, err)
+	}
+	env.Record(line)
+	env.Record(fill)
+	err = env.StopRecording()
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 4, nil, err)
+	}
+	env.Record(line)
+	cmds, err := env.Macro("m")
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 5, nil, err)
+	}
+	expected := []command.Command{line, fill}
+	if !reflect.DeepEqual(cmds, expected) {
+		t.Errorf("Case #%d: Expected: %#v, Got: %#v", 6, expected, cmds)
+	}
+
+	// The macro with the same name is replaced
+	err = env.StartRecording("m")
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 7, nil, err)
+	}
+	err = env.StopRecording()
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 8, nil, err)
+	}
+	cmds, err = env.Macro("m")
+	if err != nil || len(cmds) != 0 {
+		t.Errorf("Case #%d: Expected: empty macro, Got: %#v, %#v", 9, cmds, err)
+	}
+}
+
+func TestEnvironment_SetMaxMacroCommands(t *testing.T) {
+	env, err := NewEnvironment(newCanvasFunc, &mockRenderer{})
+	if err != nil {
+		panic(err)
+	}
+	line := basic.DrawLineCommand{X1: 1, Y1: 1, X2: 2, Y2: 1}
+
+	err = env.SetMaxMacroCommands(0)
+	if err != common.ErrMacroLimitNotPositive {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 0, common.ErrMacroLimitNotPositive, err)
+	}
+	err = env.SetMaxMacroCommands(5)
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 1, nil, err)
+	}
+
+	// Not limited if no macro is being recorded
+	err = env.CheckMacroCommands(6)
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 2, nil, err)
+	}
+
+	// The macro itself counts as 1 command
+	err = env.StartRecording("m")
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 3, nil, err)
+	}
+	err = env.CheckMacroCommands(5)
+	if err != common.ErrTooManyMacroCommands {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 4, common.ErrTooManyMacroCommands, err)
+	}
+	err = env.CheckMacroCommands(4)
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 5, nil, err)
+	}
+	env.Record(line)
+	env.Record(line)
+	err = env.StopRecording()
+	if err != nil {
+		panic(err)
+	}
+
+	// The commands of the other macros are counted
+	err = env.StartRecording("n")
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 6, nil, err)
+	}
+	err = env.CheckMacroCommands(2)
+	if err != common.ErrTooManyMacroCommands {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 7, common.ErrTooManyMacroCommands, err)
+	}
+	env.Record(line)
+	err = env.StopRecording()
+	if err != nil {
+		panic(err)
+	}
+
+	// No new macro could be recorded at the limit
+	err = env.StartRecording("o")
+	if err != common.ErrTooManyMacroCommands {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 8, common.ErrTooManyMacroCommands, err)
+	}
+
+	// The commands of the macro being replaced are not counted
+	err = env.StartRecording("m")
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 9, nil, err)
+	}
+	err = env.CheckMacroCommands(2)
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 10, nil, err)
+	}
+	err = env.StopRecording()
+	if err != nil {
+		panic(err)
+	}
+	err = env.StartRecording("o")
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 11, nil, err)
+	}
+}
+
+func TestEnvironment_Clipboard(t *testing.T) {
+	env, err := NewEnvironment(newCanvasFunc, &mockRenderer{})
+	if err != nil {
+		panic(err)
+	}
+	_, err = env.Clipboard()
+	if err != common.ErrClipboardEmpty {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 0, common.ErrClipboardEmpty, err)
+	}
+	expected := &Clip{2, 1, []color.Color{bytecolor.Color('x'), bytecolor.Color('o')}}
+	env.SetClipboard(expected)
+	clip, err := env.Clipboard()
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 1, nil, err)
+	}
+	if clip != expected {
+		t.Errorf("Case #%d: Expected: %#v, Got: %#v", 2, expected, clip)
+	}
+}
@@ -1,11 +1,18 @@
 // Package simple defines the Interpreter type,
 // which is a stateless interpreter implementing interpreter.Interpreter,
-// and the CanvasContainer interface and the Quitter interface,
-// which are used to specify the requirements of the Interpreter type,
-// and the Environment type, which fulfills the requirements.
+// and the CanvasContainer interface, the CanvasLimiter interface,
+// the Quitter interface, the Historian interface, the Saver interface,
+// the MacroRecorder interface, and the Clipboard interface, which are used
+// to specify the requirements of the Interpreter type,
+// and the Clip type held by a Clipboard,
+// and the Environment type, which fulfills the requirements,
+// and the SyncEnvironment type, which wraps an Environment
+// to make it safe for concurrent use.
 package simple
 
 import (
+	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/color"
 	"github.com/asukakenji/drawing-challenge/command"
 	"github.com/asukakenji/drawing-challenge/command/basic"
 	"github.com/asukakenji/drawing-challenge/common"
@@ -19,9 +26,29 @@ import (
 // Commands supported by this interpreter:
 // basic.EmptyCommand,
 // basic.NewCanvasCommand,
+// basic.CropCanvasCommand,
+// basic.ExtendCanvasCommand,
+// basic.ScaleCanvasCommand,
+// basic.FlipCommand,
+// basic.RotateCommand,
 // basic.DrawLineCommand,
 // basic.DrawRectCommand,
+// basic.FillRectCommand,
+// basic.DrawCircleCommand,
+// basic.DrawEllipseCommand,
+// basic.DrawPolylineCommand,
+// basic.DrawPolygonCommand,
+// basic.DrawTextCommand,
 // basic.BucketFillCommand,
+// basic.CopyCommand,
+// basic.PasteCommand,
+// basic.SetForegroundColorCommand,
+// basic.UndoCommand,
+// basic.RedoCommand,
+// basic.SaveCommand,
+// basic.StartMacroCommand,
+// basic.EndMacroCommand,
+// basic.ReplayMacroCommand,
 // basic.QuitCommand.
 //
 type Interpreter struct {
@@ -42,12 +69,67 @@ func NewInterpreter() (*Interpreter, error) {
 	return &Interpreter{}, nil
 }
 
+// toCanvasPoints translates the one-based points of a command
+// into the zero-based points of a canvas.
+func toCanvasPoints(points []basic.Point) []canvas.Point {
+	cps := make([]canvas.Point, len(points))
+	for i, p := range points {
+		cps[i] = canvas.Point{X: p.X - 1, Y: p.Y - 1}
+	}
+	return cps
+}
+
+// withForegroundColor calls f with the foreground color of cnv
+// temporarily set to c. The foreground color is restored after f returns.
+// f is called directly if c is nil.
+func withForegroundColor(cnv canvas.Canvas, c color.Color, f func() error) error {
+	if c == nil {
+		return f()
+	}
+	prev := cnv.ForegroundColor()
+	err := cnv.SetForegroundColor(c)
+	if err != nil {
+		return err
+	}
+	defer cnv.SetForegroundColor(prev)
+	return f()
+}
+
 // Interpret interprets the command cmd with the given environment env.
 //
 // env must implement the CanvasContainer interface,
 // the renderer.Renderer interface,
 // and the Quitter interface.
 //
+// If env also implements the CanvasLimiter interface, the dimensions of the
+// canvas resized by basic.ExtendCanvasCommand, basic.ScaleCanvasCommand, or
+// basic.RotateCommand are checked before the canvas is changed.
+//
+// If env also implements the Historian interface, the canvas before each
+// successful change is saved to the history, and basic.UndoCommand and
+// basic.RedoCommand are supported. The canvas is not copied for the history
+// if the history depth is 0.
+//
+// If env also implements the Saver interface, basic.SaveCommand is supported.
+//
+// If env also implements the MacroRecorder interface,
+// basic.StartMacroCommand, basic.EndMacroCommand, and basic.ReplayMacroCommand
+// are supported. While a macro is being recorded, the commands changing the
+// contents of the canvas are recorded, except the ones applying to the whole
+// canvas, which could not be translated by the offset of a replay. These are
+// basic.NewCanvasCommand, basic.CropCanvasCommand, basic.ExtendCanvasCommand,
+// basic.ScaleCanvasCommand, and basic.FlipCommand and basic.RotateCommand
+// without a rectangle. A replayed macro is recorded as the translated commands
+// in it. A replayed macro is rendered once, and is undone as a single change.
+// The canvas is unchanged if any of the commands in a replayed macro fails.
+//
+// If env also implements the MacroLimiter interface, the number of commands
+// to be recorded, including the ones in a replayed macro, is checked before
+// the canvas is changed.
+//
+// If env also implements the Clipboard interface, basic.CopyCommand and
+// basic.PasteCommand are supported.
+//
 // Errors
 //
 // common.ErrEnvironmentNotSupported:
@@ -61,8 +143,29 @@ func NewInterpreter() (*Interpreter, error) {
 // common.ErrCanvasNotCreated:
 // Will be returned if a canvas is needed, but it has not been created.
 //
-// Errors returned from the newCanvasFunc function, the canvas' DrawLine,
-// DrawRect, and BucketFill methods are returned without modifications.
+// common.ErrCanvasNotSupported:
+// Will be returned if cmd is basic.CropCanvasCommand,
+// basic.ExtendCanvasCommand, or basic.ScaleCanvasCommand,
+// but the canvas does not implement the canvas.ResizableCanvas interface,
+// or cmd is basic.FlipCommand or basic.RotateCommand,
+// but the canvas does not implement the canvas.TransformableCanvas interface,
+// or cmd is basic.CopyCommand or basic.PasteCommand,
+// but the canvas does not implement the canvas.BufferBasedCanvas interface.
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if cmd is basic.PasteCommand,
+// but any part of the pasted region is outside the canvas.
+//
+// common.ErrInvalidAngle:
+// Will be returned if cmd is basic.RotateCommand,
+// but the angle is not 90, 180, or 270.
+//
+// Errors returned from the newCanvasFunc function, the canvas' Crop, Extend,
+// Scale, DrawLine, DrawRect, FillRect, DrawCircle, DrawEllipse, DrawPolyline,
+// DrawPolygon, DrawText, FloodFill, Transform, TransformRect, At, Set, and
+// SetForegroundColor methods, and the environment's NewCanvas, CheckCanvasSize,
+// Undo, Redo, Save, StartRecording, StopRecording, Macro, CheckMacroCommands,
+// and Clipboard methods are returned without modifications.
 //
 func (interp *Interpreter) Interpret(env interface{}, cmd command.Command) error {
 	cc, ok := env.(CanvasContainer)
@@ -77,6 +180,185 @@ func (interp *Interpreter) Interpret(env interface{}, cmd command.Command) error
 	if !ok {
 		return common.ErrEnvironmentNotSupported
 	}
+	if isRecordable(cmd) {
+		err := checkMacroCommands(env, 1)
+		if err != nil {
+			return err
+		}
+	}
+	hst, saving := env.(Historian)
+	saving = saving && isUndoable(cmd) && hst.HistoryDepth() > 0
+	var snapshot canvas.Canvas
+	if cnv := cc.Canvas(); saving && cnv != nil {
+		snapshot = cnv.Clone()
+	}
+	err := interp.interpret(env, cc, rdr, qt, cmd)
+	if err != nil {
+		return err
+	}
+	if saving {
+		hst.SaveHistory(snapshot)
+	}
+	if mr, ok := env.(MacroRecorder); ok && isRecordable(cmd) {
+		mr.Record(cmd)
+	}
+	return nil
+}
+
+// checkCanvasSize checks whether env allows a canvas of width x height,
+// if env implements the CanvasLimiter interface.
+func checkCanvasSize(env interface{}, width, height int) error {
+	cl, ok := env.(CanvasLimiter)
+	if !ok {
+		return nil
+	}
+	return cl.CheckCanvasSize(width, height)
+}
+
+// checkMacroCommands checks whether n more commands could be recorded
+// to the macro being recorded, if env implements the MacroLimiter interface.
+func checkMacroCommands(env interface{}, n int) error {
+	ml, ok := env.(MacroLimiter)
+	if !ok {
+		return nil
+	}
+	return ml.CheckMacroCommands(n)
+}
+
+// extendedSize returns n + a + b, saturated at the maximum int.
+// a and b must not be negative.
+func extendedSize(n, a, b int) int {
+	const maxInt = int(^uint(0) >> 1)
+	if a > maxInt-n {
+		return maxInt
+	}
+	n += a
+	if b > maxInt-n {
+		return maxInt
+	}
+	return n + b
+}
+
+// transform transforms the rectangle with one-based corners (x1, y1) and
+// (x2, y2) of cnv by t, or the whole canvas if whole is true.
+func transform(cnv canvas.Canvas, whole bool, x1, y1, x2, y2 int, t canvas.Transform) error {
+	tcnv, ok := cnv.(canvas.TransformableCanvas)
+	if !ok {
+		return common.ErrCanvasNotSupported
+	}
+	if whole {
+		return tcnv.Transform(t)
+	}
+	return tcnv.TransformRect(x1-1, y1-1, x2-1, y2-1, t)
+}
+
+// copyRect returns a Clip holding the pixels of the rectangle
+// with one-based corners (x1, y1) and (x2, y2) of cnv.
+func copyRect(cnv canvas.Canvas, x1, y1, x2, y2 int) (*Clip, error) {
+	bcnv, ok := cnv.(canvas.BufferBasedCanvas)
+	if !ok {
+		return nil, common.ErrCanvasNotSupported
+	}
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+	clip := &Clip{
+		Width:  x2 - x1 + 1,
+		Height: y2 - y1 + 1,
+	}
+	clip.Pixels = make([]color.Color, 0, clip.Width*clip.Height)
+	for y := y1; y <= y2; y++ {
+		for x := x1; x <= x2; x++ {
+			c, err := bcnv.At(x-1, y-1)
+			if err != nil {
+				return nil, err
+			}
+			clip.Pixels = append(clip.Pixels, c)
+		}
+	}
+	return clip, nil
+}
+
+// pasteClip pastes clip to cnv with the one-based top-left corner at (x, y).
+// The pixels in the color transparent are skipped, unless it is nil.
+// The clip is pasted to a clone of cnv first,
+// so that cnv is unchanged if any of the pixels could not be set.
+func pasteClip(cnv canvas.Canvas, clip *Clip, x, y int, transparent color.Color) error {
+	bcnv, ok := cnv.(canvas.BufferBasedCanvas)
+	if !ok {
+		return common.ErrCanvasNotSupported
+	}
+	// NOTE: Checked in advance, so that the canvas is not cloned in vain
+	width, height := cnv.Dimensions()
+	if x < 1 || y < 1 || x-1+clip.Width > width || y-1+clip.Height > height {
+		return common.ErrPointOutsideCanvas
+	}
+	trial, ok := bcnv.Clone().(canvas.BufferBasedCanvas)
+	if !ok {
+		return common.ErrCanvasNotSupported
+	}
+	err := setPixels(trial, clip, x, y, transparent)
+	if err != nil {
+		return err
+	}
+	return setPixels(bcnv, clip, x, y, transparent)
+}
+
+// setPixels is the same as pasteClip, but without boundary checks,
+// and cnv is left partially changed if any of the pixels could not be set.
+func setPixels(bcnv canvas.BufferBasedCanvas, clip *Clip, x, y int, transparent color.Color) error {
+	for j := 0; j < clip.Height; j++ {
+		for i := 0; i < clip.Width; i++ {
+			c := clip.Pixels[j*clip.Width+i]
+			if transparent != nil && c.Equals(transparent) {
+				continue
+			}
+			err := bcnv.Set(x-1+i, y-1+j, c)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isUndoable returns whether cmd could be undone.
+func isUndoable(cmd command.Command) bool {
+	switch cmd.(type) {
+	case basic.EmptyCommand, basic.UndoCommand, basic.RedoCommand, basic.SaveCommand, basic.QuitCommand,
+		basic.StartMacroCommand, basic.EndMacroCommand, basic.CopyCommand:
+		return false
+	default:
+		return true
+	}
+}
+
+// isRecordable returns whether cmd could be recorded to a macro.
+// The commands applying to the whole canvas are not recorded,
+// since they could not be translated by the offset of a replay.
+func isRecordable(cmd command.Command) bool {
+	switch cmd := cmd.(type) {
+	case basic.DrawLineCommand, basic.DrawRectCommand, basic.FillRectCommand,
+		basic.DrawCircleCommand, basic.DrawEllipseCommand,
+		basic.DrawPolylineCommand, basic.DrawPolygonCommand,
+		basic.DrawTextCommand, basic.BucketFillCommand,
+		basic.PasteCommand, basic.SetForegroundColorCommand:
+		return true
+	case basic.FlipCommand:
+		return !cmd.Whole
+	case basic.RotateCommand:
+		return !cmd.Whole
+	default:
+		return false
+	}
+}
+
+// interpret is the same as Interpret,
+// but the requirements on env are already checked.
+func (interp *Interpreter) interpret(env interface{}, cc CanvasContainer, rdr renderer.Renderer, qt Quitter, cmd command.Command) error {
 	switch cmd := cmd.(type) {
 	case basic.EmptyCommand:
 		// Nothing to be done
@@ -87,12 +369,68 @@ func (interp *Interpreter) Interpret(env interface{}, cmd command.Command) error
 		}
 		cnv := cc.Canvas()
 		rdr.Render(cnv)
+	case basic.CropCanvasCommand:
+		cnv := cc.Canvas()
+		if cnv == nil {
+			return common.ErrCanvasNotCreated
+		}
+		rcnv, ok := cnv.(canvas.ResizableCanvas)
+		if !ok {
+			return common.ErrCanvasNotSupported
+		}
+		err := rcnv.Crop(cmd.X1-1, cmd.Y1-1, cmd.X2-1, cmd.Y2-1)
+		if err != nil {
+			return err
+		}
+		rdr.Render(cnv)
+	case basic.ExtendCanvasCommand:
+		cnv := cc.Canvas()
+		if cnv == nil {
+			return common.ErrCanvasNotCreated
+		}
+		rcnv, ok := cnv.(canvas.ResizableCanvas)
+		if !ok {
+			return common.ErrCanvasNotSupported
+		}
+		// NOTE: Negative extents are left to be reported by the canvas
+		if cmd.Left >= 0 && cmd.Top >= 0 && cmd.Right >= 0 && cmd.Bottom >= 0 {
+			width, height := cnv.Dimensions()
+			err := checkCanvasSize(env, extendedSize(width, cmd.Left, cmd.Right), extendedSize(height, cmd.Top, cmd.Bottom))
+			if err != nil {
+				return err
+			}
+		}
+		err := rcnv.Extend(cmd.Left, cmd.Top, cmd.Right, cmd.Bottom)
+		if err != nil {
+			return err
+		}
+		rdr.Render(cnv)
+	case basic.ScaleCanvasCommand:
+		cnv := cc.Canvas()
+		if cnv == nil {
+			return common.ErrCanvasNotCreated
+		}
+		rcnv, ok := cnv.(canvas.ResizableCanvas)
+		if !ok {
+			return common.ErrCanvasNotSupported
+		}
+		err := checkCanvasSize(env, cmd.Width, cmd.Height)
+		if err != nil {
+			return err
+		}
+		err = rcnv.Scale(cmd.Width, cmd.Height)
+		if err != nil {
+			return err
+		}
+		rdr.Render(cnv)
 	case basic.DrawLineCommand:
 		cnv := cc.Canvas()
 		if cnv == nil {
 			return common.ErrCanvasNotCreated
 		}
-		err := cnv.DrawLine(cmd.X1-1, cmd.Y1-1, cmd.X2-1, cmd.Y2-1)
+		err := withForegroundColor(cnv, cmd.C, func() error {
+			return cnv.DrawLine(cmd.X1-1, cmd.Y1-1, cmd.X2-1, cmd.Y2-1)
+		})
 		if err != nil {
 			return err
 		}
@@ -102,7 +440,115 @@ func (interp *Interpreter) Interpret(env interface{}, cmd command.Command) error
 		if cnv == nil {
 			return common.ErrCanvasNotCreated
 		}
-		err := cnv.DrawRect(cmd.X1-1, cmd.Y1-1, cmd.X2-1, cmd.Y2-1)
+		err := withForegroundColor(cnv, cmd.C, func() error {
+			return cnv.DrawRect(cmd.X1-1, cmd.Y1-1, cmd.X2-1, cmd.Y2-1)
+		})
+		if err != nil {
+			return err
+		}
+		rdr.Render(cnv)
+	case basic.FillRectCommand:
+		cnv := cc.Canvas()
+		if cnv == nil {
+			return common.ErrCanvasNotCreated
+		}
+		err := cnv.FillRect(cmd.X1-1, cmd.Y1-1, cmd.X2-1, cmd.Y2-1, cmd.Stroke, cmd.Fill)
+		if err != nil {
+			return err
+		}
+		rdr.Render(cnv)
+	case basic.DrawCircleCommand:
+		cnv := cc.Canvas()
+		if cnv == nil {
+			return common.ErrCanvasNotCreated
+		}
+		err := cnv.DrawCircle(cmd.X-1, cmd.Y-1, cmd.R, cmd.Filled)
+		if err != nil {
+			return err
+		}
+		rdr.Render(cnv)
+	case basic.DrawEllipseCommand:
+		cnv := cc.Canvas()
+		if cnv == nil {
+			return common.ErrCanvasNotCreated
+		}
+		err := cnv.DrawEllipse(cmd.X-1, cmd.Y-1, cmd.RX, cmd.RY, cmd.Filled)
+		if err != nil {
+			return err
+		}
+		rdr.Render(cnv)
+	case basic.DrawPolylineCommand:
+		cnv := cc.Canvas()
+		if cnv == nil {
+			return common.ErrCanvasNotCreated
+		}
+		err := cnv.DrawPolyline(toCanvasPoints(cmd.Points))
+		if err != nil {
+			return err
+		}
+		rdr.Render(cnv)
+	case basic.DrawPolygonCommand:
+		cnv := cc.Canvas()
+		if cnv == nil {
+			return common.ErrCanvasNotCreated
+		}
+		rule := canvas.FillRuleEvenOdd
+		if cmd.NonZero {
+			rule = canvas.FillRuleNonZero
+		}
+		err := cnv.DrawPolygon(toCanvasPoints(cmd.Points), cmd.Filled, rule)
+		if err != nil {
+			return err
+		}
+		rdr.Render(cnv)
+	case basic.DrawTextCommand:
+		cnv := cc.Canvas()
+		if cnv == nil {
+			return common.ErrCanvasNotCreated
+		}
+		err := cnv.DrawText(cmd.X-1, cmd.Y-1, cmd.Text)
+		if err != nil {
+			return err
+		}
+		rdr.Render(cnv)
+	case basic.FlipCommand:
+		cnv := cc.Canvas()
+		if cnv == nil {
+			return common.ErrCanvasNotCreated
+		}
+		t := canvas.TransformFlipHorizontal
+		if cmd.Vertical {
+			t = canvas.TransformFlipVertical
+		}
+		err := transform(cnv, cmd.Whole, cmd.X1, cmd.Y1, cmd.X2, cmd.Y2, t)
+		if err != nil {
+			return err
+		}
+		rdr.Render(cnv)
+	case basic.RotateCommand:
+		cnv := cc.Canvas()
+		if cnv == nil {
+			return common.ErrCanvasNotCreated
+		}
+		var t canvas.Transform
+		switch cmd.Degrees {
+		case 90:
+			t = canvas.TransformRotate90
+		case 180:
+			t = canvas.TransformRotate180
+		case 270:
+			t = canvas.TransformRotate270
+		default:
+			return common.ErrInvalidAngle
+		}
+		if cmd.Degrees != 180 && cmd.Whole {
+			width, height := cnv.Dimensions()
+			err := checkCanvasSize(env, height, width)
+			if err != nil {
+				return err
+			}
+		}
+		err := transform(cnv, cmd.Whole, cmd.X1, cmd.Y1, cmd.X2, cmd.Y2, t)
 		if err != nil {
 			return err
 		}
@@ -112,11 +558,109 @@ func (interp *Interpreter) Interpret(env interface{}, cmd command.Command) error
 		if cnv == nil {
 			return common.ErrCanvasNotCreated
 		}
-		err := cnv.BucketFill(cmd.X-1, cmd.Y-1, cmd.C)
+		opts := canvas.FillOptions{
+			Diagonal:  cmd.Diagonal,
+			Global:    cmd.Global,
+			Tolerance: cmd.Tolerance,
+		}
+		err := cnv.FloodFill(cmd.X-1, cmd.Y-1, cmd.C, opts)
+		if err != nil {
+			return err
+		}
+		rdr.Render(cnv)
+	case basic.CopyCommand:
+		cnv := cc.Canvas()
+		if cnv == nil {
+			return common.ErrCanvasNotCreated
+		}
+		cb, ok := env.(Clipboard)
+		if !ok {
+			return common.ErrEnvironmentNotSupported
+		}
+		clip, err := copyRect(cnv, cmd.X1, cmd.Y1, cmd.X2, cmd.Y2)
+		if err != nil {
+			return err
+		}
+		cb.SetClipboard(clip)
+	case basic.PasteCommand:
+		cnv := cc.Canvas()
+		if cnv == nil {
+			return common.ErrCanvasNotCreated
+		}
+		cb, ok := env.(Clipboard)
+		if !ok {
+			return common.ErrEnvironmentNotSupported
+		}
+		clip, err := cb.Clipboard()
+		if err != nil {
+			return err
+		}
+		err = pasteClip(cnv, clip, cmd.X, cmd.Y, cmd.Transparent)
 		if err != nil {
 			return err
 		}
 		rdr.Render(cnv)
+	case basic.SetForegroundColorCommand:
+		cnv := cc.Canvas()
+		if cnv == nil {
+			return common.ErrCanvasNotCreated
+		}
+		err := cnv.SetForegroundColor(cmd.C)
+		if err != nil {
+			return err
+		}
+	case basic.UndoCommand:
+		hst, ok := env.(Historian)
+		if !ok {
+			return common.ErrEnvironmentNotSupported
+		}
+		err := hst.Undo()
+		if err != nil {
+			return err
+		}
+		if cnv := cc.Canvas(); cnv != nil {
+			rdr.Render(cnv)
+		}
+	case basic.RedoCommand:
+		hst, ok := env.(Historian)
+		if !ok {
+			return common.ErrEnvironmentNotSupported
+		}
+		err := hst.Redo()
+		if err != nil {
+			return err
+		}
+		if cnv := cc.Canvas(); cnv != nil {
+			rdr.Render(cnv)
+		}
+	case basic.SaveCommand:
+		cnv := cc.Canvas()
+		if cnv == nil {
+			return common.ErrCanvasNotCreated
+		}
+		sv, ok := env.(Saver)
+		if !ok {
+			return common.ErrEnvironmentNotSupported
+		}
+		return sv.Save(cnv, cmd.FileName)
+	case basic.StartMacroCommand:
+		mr, ok := env.(MacroRecorder)
+		if !ok {
+			return common.ErrEnvironmentNotSupported
+		}
+		return mr.StartRecording(cmd.Name)
+	case basic.EndMacroCommand:
+		mr, ok := env.(MacroRecorder)
+		if !ok {
+			return common.ErrEnvironmentNotSupported
+		}
+		return mr.StopRecording()
+	case basic.ReplayMacroCommand:
+		mr, ok := env.(MacroRecorder)
+		if !ok {
+			return common.ErrEnvironmentNotSupported
+		}
+		return interp.replay(env, cc, rdr, qt, mr, cmd)
 	case basic.QuitCommand:
 		qt.SetQuit()
 	default:
@@ -124,3 +668,66 @@ func (interp *Interpreter) Interpret(env interface{}, cmd command.Command) error
 	}
 	return nil
 }
+
+// replay replays the macro specified by cmd.
+// The commands are replayed on a clone of the canvas first,
+// so that the canvas is unchanged if any of them fails.
+func (interp *Interpreter) replay(env interface{}, cc CanvasContainer, rdr renderer.Renderer, qt Quitter, mr MacroRecorder, cmd basic.ReplayMacroCommand) error {
+	cmds, err := mr.Macro(cmd.Name)
+	if err != nil {
+		return err
+	}
+	err = checkMacroCommands(env, len(cmds))
+	if err != nil {
+		return err
+	}
+	cnv := cc.Canvas()
+	if cnv == nil {
+		return common.ErrCanvasNotCreated
+	}
+	translated := make([]command.Command, len(cmds))
+	for i, c := range cmds {
+		translated[i] = basic.Translate(c, cmd.DX, cmd.DY)
+	}
+	trial := &canvasHolder{cnv.Clone()}
+	for _, c := range translated {
+		err := interp.interpret(env, trial, discardRenderer{}, qt, c)
+		if err != nil {
+			return err
+		}
+	}
+	for _, c := range translated {
+		err := interp.interpret(env, cc, discardRenderer{}, qt, c)
+		if err != nil {
+			return err
+		}
+		mr.Record(c)
+	}
+	rdr.Render(cnv)
+	return nil
+}
+
+// canvasHolder is a CanvasContainer holding a canvas,
+// which is used to try the commands in a macro.
+type canvasHolder struct {
+	cnv canvas.Canvas
+}
+
+// Canvas returns the contained canvas.Canvas.
+func (cc *canvasHolder) Canvas() canvas.Canvas {
+	return cc.cnv
+}
+
+// NewCanvas is not supported, since it is never recorded to a macro.
+func (cc *canvasHolder) NewCanvas(width, height int) error {
+	return common.ErrCommandNotSupported
+}
+
+// discardRenderer is a renderer.Renderer which renders nothing.
+// It is used to avoid rendering each command in a macro.
+type discardRenderer struct{}
+
+// Render does nothing.
+func (rdr discardRenderer) Render(cnv canvas.Canvas) error {
+	return nil
+}
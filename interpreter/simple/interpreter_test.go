@@ -2,6 +2,10 @@ package simple
 
 import (
 	"container/list"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -12,6 +16,8 @@ import (
 	"github.com/asukakenji/drawing-challenge/command"
 	"github.com/asukakenji/drawing-challenge/command/basic"
 	"github.com/asukakenji/drawing-challenge/common"
+	"github.com/asukakenji/drawing-challenge/renderer"
+	"github.com/asukakenji/drawing-challenge/renderer/writer"
 )
 
 var newCanvasFunc = func(width, height int) (canvas.Canvas, error) {
@@ -22,6 +28,7 @@ var newCanvasFunc = func(width, height int) (canvas.Canvas, error) {
 type mockCanvas struct {
 	width    int
 	height   int
+	fgColor  color.Color
 	commands *list.List
 }
 
@@ -40,13 +47,76 @@ func (mc *mockCanvas) Dimensions() (int, int) {
 	return mc.width, mc.height
 }
 
+func (mc *mockCanvas) Clone() canvas.Canvas {
+	clone := *mc
+	return &clone
+}
+
+func (mc *mockCanvas) ForegroundColor() color.Color {
+	return mc.fgColor
+}
+
+func (mc *mockCanvas) SetForegroundColor(c color.Color) error {
+	if _, ok := c.(dummyColor); ok {
+		return common.ErrColorTypeNotSupported
+	}
+	mc.fgColor = c
+	return nil
+}
+
+func (mc *mockCanvas) SetLineMode(mode canvas.LineMode) error {
+	return nil
+}
+
 func (mc *mockCanvas) DrawLine(x1, y1, x2, y2 int) error {
-	mc.commands.PushBack(basic.DrawLineCommand{X1: x1, Y1: y1, X2: x2, Y2: y2})
+	mc.commands.PushBack(basic.DrawLineCommand{X1: x1, Y1: y1, X2: x2, Y2: y2, C: mc.fgColor})
 	return nil
 }
 
 func (mc *mockCanvas) DrawRect(x1, y1, x2, y2 int) error {
-	mc.commands.PushBack(basic.DrawRectCommand{X1: x1, Y1: y1, X2: x2, Y2: y2})
+	mc.commands.PushBack(basic.DrawRectCommand{X1: x1, Y1: y1, X2: x2, Y2: y2, C: mc.fgColor})
+	return nil
+}
+
+func (mc *mockCanvas) FillRect(x1, y1, x2, y2 int, stroke, fill color.Color) error {
+	mc.commands.PushBack(basic.FillRectCommand{X1: x1, Y1: y1, X2: x2, Y2: y2, Fill: fill, Stroke: stroke})
+	return nil
+}
+
+func (mc *mockCanvas) DrawCircle(xc, yc, r int, filled bool) error {
+	mc.commands.PushBack(basic.DrawCircleCommand{X: xc, Y: yc, R: r, Filled: filled})
+	return nil
+}
+
+func (mc *mockCanvas) DrawEllipse(xc, yc, rx, ry int, filled bool) error {
+	mc.commands.PushBack(basic.DrawEllipseCommand{X: xc, Y: yc, RX: rx, RY: ry, Filled: filled})
+	return nil
+}
+
+func toBasicPoints(points []canvas.Point) []basic.Point {
+	bps := make([]basic.Point, len(points))
+	for i, p := range points {
+		bps[i] = basic.Point{X: p.X, Y: p.Y}
+	}
+	return bps
+}
+
+func (mc *mockCanvas) DrawPolyline(points []canvas.Point) error {
+	mc.commands.PushBack(basic.DrawPolylineCommand{Points: toBasicPoints(points)})
+	return nil
+}
+
+func (mc *mockCanvas) DrawPolygon(points []canvas.Point, filled bool, rule canvas.FillRule) error {
+	mc.commands.PushBack(basic.DrawPolygonCommand{Points: toBasicPoints(points), Filled: filled, NonZero: rule == canvas.FillRuleNonZero})
+	return nil
+}
+
+func (mc *mockCanvas) SetTextMode(mode canvas.TextMode) error {
+	return nil
+}
+
+func (mc *mockCanvas) DrawText(x, y int, text string) error {
+	mc.commands.PushBack(basic.DrawTextCommand{X: x, Y: y, Text: text})
 	return nil
 }
 
@@ -55,7 +125,74 @@ func (mc *mockCanvas) BucketFill(x, y int, c color.Color) error {
 	return nil
 }
 
+func (mc *mockCanvas) FloodFill(x, y int, c color.Color, opts canvas.FillOptions) error {
+	mc.commands.PushBack(basic.BucketFillCommand{X: x, Y: y, C: c, Diagonal: opts.Diagonal, Global: opts.Global, Tolerance: opts.Tolerance})
+	return nil
+}
+
+func (mc *mockCanvas) Crop(x1, y1, x2, y2 int) error {
+	mc.commands.PushBack(basic.CropCanvasCommand{X1: x1, Y1: y1, X2: x2, Y2: y2})
+	return nil
+}
+
+func (mc *mockCanvas) Extend(left, top, right, bottom int) error {
+	mc.commands.PushBack(basic.ExtendCanvasCommand{Left: left, Top: top, Right: right, Bottom: bottom})
+	return nil
+}
+
+func (mc *mockCanvas) Scale(width, height int) error {
+	mc.commands.PushBack(basic.ScaleCanvasCommand{Width: width, Height: height})
+	return nil
+}
+
+// This type is created for testing purpose only
+type mockTransform struct {
+	t              canvas.Transform
+	whole          bool
+	x1, y1, x2, y2 int
+}
+
+// Command is a dummy method, so that the transforms could be compared
+// with the commands recorded by mockCanvas.
+func (mt mockTransform) Command() {}
+
+func (mc *mockCanvas) Transform(t canvas.Transform) error {
+	mc.commands.PushBack(mockTransform{t: t, whole: true})
+	return nil
+}
+
+func (mc *mockCanvas) TransformRect(x1, y1, x2, y2 int, t canvas.Transform) error {
+	mc.commands.PushBack(mockTransform{t, false, x1, y1, x2, y2})
+	return nil
+}
+
+// This type is created for testing purpose only
+// cloneCountingCanvas is a canvas counting the calls to Clone.
+type cloneCountingCanvas struct {
+	canvas.Canvas
+	clones *int
+}
+
+func (cnv *cloneCountingCanvas) Clone() canvas.Canvas {
+	*cnv.clones++
+	return &cloneCountingCanvas{cnv.Canvas.Clone(), cnv.clones}
+}
+
 // This type is created for testing purpose only
+// fixedSizeCanvas is a canvas hiding the methods other than
+// the ones of the canvas.Canvas interface, so that it is not resizable.
+type fixedSizeCanvas struct {
+	canvas.Canvas
+}
+
+func newFixedSizeCanvas(width, height int) (canvas.Canvas, error) {
+	cnv, err := newCanvasFunc(width, height)
+	if err != nil {
+		return nil, err
+	}
+	return fixedSizeCanvas{cnv}, nil
+}
+
 type mockCanvasContainer struct {
 	cnv           canvas.Canvas
 	newCanvasFunc func(int, int) (canvas.Canvas, error)
@@ -133,6 +270,13 @@ func newMockEnvironment2(newCanvasFunc func(int, int) (canvas.Canvas, error)) *m
 	}
 }
 
+// This type is created for testing purpose only
+type dummyColor byte
+
+func (c1 dummyColor) Equals(c2 color.Color) bool {
+	return true
+}
+
 // This type is created for testing purpose only
 type mockCommand struct{}
 
@@ -166,6 +310,27 @@ func TestInterpreter_Interpret(t *testing.T) {
 		{basic.BucketFillCommand{X: 10, Y: 3, C: bytecolor.Color('o')}, basic.BucketFillCommand{X: 9, Y: 2, C: bytecolor.Color('o')}}, // Example 5
 		{basic.EmptyCommand{}, basic.BucketFillCommand{X: 9, Y: 2, C: bytecolor.Color('o')}},
 		{basic.QuitCommand{}, basic.BucketFillCommand{X: 9, Y: 2, C: bytecolor.Color('o')}},
+		{basic.DrawLineCommand{X1: 1, Y1: 2, X2: 6, Y2: 2, C: bytecolor.Color('R')}, basic.DrawLineCommand{X1: 0, Y1: 1, X2: 5, Y2: 1, C: bytecolor.Color('R')}},
+		{basic.DrawRectCommand{X1: 14, Y1: 1, X2: 18, Y2: 3, C: bytecolor.Color('G')}, basic.DrawRectCommand{X1: 13, Y1: 0, X2: 17, Y2: 2, C: bytecolor.Color('G')}},
+		{basic.DrawLineCommand{X1: 1, Y1: 2, X2: 6, Y2: 2}, basic.DrawLineCommand{X1: 0, Y1: 1, X2: 5, Y2: 1}},
+		{basic.SetForegroundColorCommand{C: bytecolor.Color('B')}, basic.DrawLineCommand{X1: 0, Y1: 1, X2: 5, Y2: 1}},
+		{basic.DrawLineCommand{X1: 1, Y1: 2, X2: 6, Y2: 2}, basic.DrawLineCommand{X1: 0, Y1: 1, X2: 5, Y2: 1, C: bytecolor.Color('B')}},
+		{basic.FillRectCommand{X1: 14, Y1: 1, X2: 18, Y2: 3, Fill: bytecolor.Color('o')}, basic.FillRectCommand{X1: 13, Y1: 0, X2: 17, Y2: 2, Fill: bytecolor.Color('o')}},
+		{basic.DrawCircleCommand{X: 4, Y: 3, R: 1}, basic.DrawCircleCommand{X: 3, Y: 2, R: 1}},
+		{basic.DrawTextCommand{X: 2, Y: 3, Text: "label"}, basic.DrawTextCommand{X: 1, Y: 2, Text: "label"}},
+		{basic.DrawEllipseCommand{X: 4, Y: 3, RX: 2, RY: 1, Filled: true}, basic.DrawEllipseCommand{X: 3, Y: 2, RX: 2, RY: 1, Filled: true}},
+		{basic.DrawPolylineCommand{Points: []basic.Point{{X: 1, Y: 1}, {X: 3, Y: 4}}}, basic.DrawPolylineCommand{Points: []basic.Point{{X: 0, Y: 0}, {X: 2, Y: 3}}}},
+		{basic.BucketFillCommand{X: 2, Y: 3, C: bytecolor.Color('o'), Diagonal: true, Global: true, Tolerance: 4}, basic.BucketFillCommand{X: 1, Y: 2, C: bytecolor.Color('o'), Diagonal: true, Global: true, Tolerance: 4}},
+		{basic.DrawPolygonCommand{Points: []basic.Point{{X: 1, Y: 1}, {X: 3, Y: 4}, {X: 1, Y: 4}}}, basic.DrawPolygonCommand{Points: []basic.Point{{X: 0, Y: 0}, {X: 2, Y: 3}, {X: 0, Y: 3}}}},
+		{basic.DrawPolygonCommand{Points: []basic.Point{{X: 1, Y: 1}, {X: 3, Y: 4}, {X: 1, Y: 4}}, Filled: true, NonZero: true}, basic.DrawPolygonCommand{Points: []basic.Point{{X: 0, Y: 0}, {X: 2, Y: 3}, {X: 0, Y: 3}}, Filled: true, NonZero: true}},
+		{basic.CropCanvasCommand{X1: 2, Y1: 1, X2: 19, Y2: 3}, basic.CropCanvasCommand{X1: 1, Y1: 0, X2: 18, Y2: 2}},
+		{basic.ExtendCanvasCommand{Left: 1, Top: 0, Right: 2, Bottom: 3}, basic.ExtendCanvasCommand{Left: 1, Top: 0, Right: 2, Bottom: 3}},
+		{basic.ScaleCanvasCommand{Width: 40, Height: 8}, basic.ScaleCanvasCommand{Width: 40, Height: 8}},
+		{basic.FlipCommand{Whole: true}, mockTransform{t: canvas.TransformFlipHorizontal, whole: true}},
+		{basic.FlipCommand{Vertical: true, X1: 1, Y1: 2, X2: 3, Y2: 4}, mockTransform{canvas.TransformFlipVertical, false, 0, 1, 2, 3}},
+		{basic.RotateCommand{Degrees: 90, Whole: true}, mockTransform{t: canvas.TransformRotate90, whole: true}},
+		{basic.RotateCommand{Degrees: 180, X1: 2, Y1: 2, X2: 1, Y2: 1}, mockTransform{canvas.TransformRotate180, false, 1, 1, 0, 0}},
+		{basic.RotateCommand{Degrees: 270, X1: 1, Y1: 1, X2: 1, Y2: 1}, mockTransform{canvas.TransformRotate270, false, 0, 0, 0, 0}},
 	}
 	for _, c := range casesPos {
 		err = interp.Interpret(envPos, c.cmd)
@@ -192,12 +357,39 @@ func TestInterpreter_Interpret(t *testing.T) {
 		{basic.DrawLineCommand{X1: 1, Y1: 2, X2: 6, Y2: 2}, common.ErrCanvasNotCreated},
 		{basic.DrawLineCommand{X1: 6, Y1: 3, X2: 6, Y2: 4}, common.ErrCanvasNotCreated},
 		{basic.DrawRectCommand{X1: 14, Y1: 1, X2: 18, Y2: 3}, common.ErrCanvasNotCreated},
+		{basic.FillRectCommand{X1: 14, Y1: 1, X2: 18, Y2: 3, Fill: bytecolor.Color('o')}, common.ErrCanvasNotCreated},
+		{basic.DrawCircleCommand{X: 4, Y: 3, R: 1}, common.ErrCanvasNotCreated},
+		{basic.DrawEllipseCommand{X: 4, Y: 3, RX: 2, RY: 1}, common.ErrCanvasNotCreated},
+		{basic.DrawPolylineCommand{Points: []basic.Point{{X: 1, Y: 1}, {X: 3, Y: 4}}}, common.ErrCanvasNotCreated},
+		{basic.DrawPolygonCommand{Points: []basic.Point{{X: 1, Y: 1}, {X: 3, Y: 4}, {X: 1, Y: 4}}}, common.ErrCanvasNotCreated},
 		{basic.BucketFillCommand{X: 10, Y: 3, C: bytecolor.Color('o')}, common.ErrCanvasNotCreated},
+		{basic.SetForegroundColorCommand{C: bytecolor.Color('B')}, common.ErrCanvasNotCreated},
+		{basic.DrawTextCommand{X: 2, Y: 3, Text: "label"}, common.ErrCanvasNotCreated},
+		{basic.CropCanvasCommand{X1: 1, Y1: 1, X2: 2, Y2: 2}, common.ErrCanvasNotCreated},
+		{basic.ExtendCanvasCommand{Left: 1, Top: 1, Right: 1, Bottom: 1}, common.ErrCanvasNotCreated},
+		{basic.ScaleCanvasCommand{Width: 2, Height: 2}, common.ErrCanvasNotCreated},
+		{basic.FlipCommand{Whole: true}, common.ErrCanvasNotCreated},
+		{basic.RotateCommand{Degrees: 90, Whole: true}, common.ErrCanvasNotCreated},
 		// With Canvas
 		{basic.NewCanvasCommand{Width: 20, Height: 4}, nil},
 		{basic.DrawLineCommand{X1: -1, Y1: -1, X2: -1, Y2: -1}, common.ErrPointOutsideCanvas},
 		{basic.DrawRectCommand{X1: -1, Y1: -1, X2: -1, Y2: -1}, common.ErrPointOutsideCanvas},
+		{basic.FillRectCommand{X1: -1, Y1: -1, X2: -1, Y2: -1, Fill: bytecolor.Color('o')}, common.ErrPointOutsideCanvas},
+		{basic.DrawCircleCommand{X: 1, Y: 1, R: 1}, common.ErrPointOutsideCanvas},
+		{basic.DrawEllipseCommand{X: 4, Y: 3, RX: -1, RY: 1}, common.ErrRadiusNegative},
+		{basic.DrawPolylineCommand{Points: []basic.Point{{X: 1, Y: 1}}}, common.ErrTooFewPoints},
+		{basic.DrawPolygonCommand{Points: []basic.Point{{X: 0, Y: 0}, {X: 3, Y: 4}, {X: 1, Y: 4}}}, common.ErrPointOutsideCanvas},
 		{basic.BucketFillCommand{X: -1, Y: -1, C: bytecolor.Color('o')}, common.ErrPointOutsideCanvas},
+		{basic.DrawLineCommand{X1: 1, Y1: 2, X2: 6, Y2: 2, C: dummyColor('R')}, common.ErrColorTypeNotSupported},
+		{basic.SetForegroundColorCommand{C: dummyColor('B')}, common.ErrColorTypeNotSupported},
+		{basic.DrawTextCommand{X: 18, Y: 3, Text: "label"}, common.ErrPointOutsideCanvas},
+		{basic.CropCanvasCommand{X1: 1, Y1: 1, X2: 21, Y2: 4}, common.ErrPointOutsideCanvas},
+		{basic.ExtendCanvasCommand{Left: 1, Top: -1, Right: 1, Bottom: 1}, common.ErrExtentNegative},
+		{basic.ScaleCanvasCommand{Width: 0, Height: 2}, common.ErrWidthOrHeightNotPositive},
+		{basic.FlipCommand{}, common.ErrPointOutsideCanvas},
+		{basic.FlipCommand{X1: 1, Y1: 1, X2: 21, Y2: 1}, common.ErrPointOutsideCanvas},
+		{basic.RotateCommand{Degrees: 90, X1: 1, Y1: 1, X2: 5, Y2: 1}, common.ErrPointOutsideCanvas},
+		{basic.RotateCommand{Degrees: 45, Whole: true}, common.ErrInvalidAngle},
 	}
 	for _, c := range casesNeg {
 		err = interp.Interpret(envNeg, c.cmd)
@@ -219,4 +411,459 @@ func TestInterpreter_Interpret(t *testing.T) {
 			t.Errorf("Case #%d: Expected: %#v, Got: %#v", i, common.ErrEnvironmentNotSupported, err)
 		}
 	}
+
+	// Canvases which are not resizable
+	envFixed := newMockEnvironment(newFixedSizeCanvas)
+	err = interp.Interpret(envFixed, basic.NewCanvasCommand{Width: 2, Height: 2})
+	if err != nil {
+		panic(err)
+	}
+	for _, cmd := range []command.Command{
+		basic.CropCanvasCommand{X1: 1, Y1: 1, X2: 1, Y2: 1},
+		basic.ExtendCanvasCommand{Left: 1, Top: 1, Right: 1, Bottom: 1},
+		basic.ScaleCanvasCommand{Width: 4, Height: 4},
+	} {
+		err = interp.Interpret(envFixed, cmd)
+		if err != common.ErrCanvasNotSupported {
+			t.Errorf("Case: %#v, Expected: %#v, Got: %#v", cmd, common.ErrCanvasNotSupported, err)
+		}
+	}
+}
+
+func TestInterpreter_Interpret_History(t *testing.T) {
+	interp, err := NewInterpreter()
+	if err != nil {
+		panic(err)
+	}
+	env, err := NewEnvironment(newCanvasFunc, &mockRenderer{})
+	if err != nil {
+		panic(err)
+	}
+
+	cases := []struct {
+		cmd    command.Command
+		err    error
+		pixels []bytecolor.Color
+	}{
+		{basic.UndoCommand{}, common.ErrNothingToUndo, nil},
+		{basic.NewCanvasCommand{Width: 3, Height: 1}, nil, []bytecolor.Color{' ', ' ', ' '}},
+		{basic.DrawLineCommand{X1: 1, Y1: 1, X2: 2, Y2: 1}, nil, []bytecolor.Color{'x', 'x', ' '}},
+		{basic.DrawLineCommand{X1: 1, Y1: 1, X2: 4, Y2: 1}, common.ErrPointOutsideCanvas, []bytecolor.Color{'x', 'x', ' '}},
+		{basic.BucketFillCommand{X: 3, Y: 1, C: bytecolor.Color('o')}, nil, []bytecolor.Color{'x', 'x', 'o'}},
+		{basic.UndoCommand{}, nil, []bytecolor.Color{'x', 'x', ' '}},
+		{basic.UndoCommand{}, nil, []bytecolor.Color{' ', ' ', ' '}},
+		{basic.RedoCommand{}, nil, []bytecolor.Color{'x', 'x', ' '}},
+		{basic.RedoCommand{}, nil, []bytecolor.Color{'x', 'x', 'o'}},
+		{basic.RedoCommand{}, common.ErrNothingToRedo, []bytecolor.Color{'x', 'x', 'o'}},
+		{basic.UndoCommand{}, nil, []bytecolor.Color{'x', 'x', ' '}},
+		{basic.BucketFillCommand{X: 3, Y: 1, C: bytecolor.Color('v')}, nil, []bytecolor.Color{'x', 'x', 'v'}},
+		{basic.RedoCommand{}, common.ErrNothingToRedo, []bytecolor.Color{'x', 'x', 'v'}},
+		{basic.ExtendCanvasCommand{Right: 1}, nil, []bytecolor.Color{'x', 'x', 'v', ' '}},
+		{basic.UndoCommand{}, nil, []bytecolor.Color{'x', 'x', 'v'}},
+		{basic.CropCanvasCommand{X1: 2, Y1: 1, X2: 3, Y2: 1}, nil, []bytecolor.Color{'x', 'v'}},
+		{basic.UndoCommand{}, nil, []bytecolor.Color{'x', 'x', 'v'}},
+		{basic.FlipCommand{X1: 2, Y1: 1, X2: 3, Y2: 1}, nil, []bytecolor.Color{'x', 'v', 'x'}},
+		{basic.RotateCommand{Degrees: 180, Whole: true}, nil, []bytecolor.Color{'x', 'v', 'x'}},
+		{basic.RotateCommand{Degrees: 270, Whole: true}, nil, []bytecolor.Color{'x', 'v', 'x'}},
+		{basic.UndoCommand{}, nil, []bytecolor.Color{'x', 'v', 'x'}},
+		{basic.UndoCommand{}, nil, []bytecolor.Color{'x', 'v', 'x'}},
+		{basic.UndoCommand{}, nil, []bytecolor.Color{'x', 'x', 'v'}},
+		{basic.UndoCommand{}, nil, []bytecolor.Color{'x', 'x', ' '}},
+		{basic.UndoCommand{}, nil, []bytecolor.Color{' ', ' ', ' '}},
+		{basic.UndoCommand{}, nil, nil},
+		{basic.UndoCommand{}, common.ErrNothingToUndo, nil},
+	}
+	for i, c := range cases {
+		err = interp.Interpret(env, c.cmd)
+		if err != c.err {
+			t.Errorf("Case #%d: Expected: %#v, Got: %#v", i, c.err, err)
+		}
+		cnv := env.Canvas()
+		if c.pixels == nil {
+			if cnv != nil {
+				t.Errorf("Case #%d: Expected: cnv == nil, Got: %#v", i, cnv)
+			}
+			continue
+		}
+		pixels := cnv.(*bc.Buffer).Pixels()
+		if !reflect.DeepEqual(pixels, c.pixels) {
+			t.Errorf("Case #%d: Expected: %#v, Got: %#v", i, c.pixels, pixels)
+		}
+	}
+
+	// The canvas is not copied if the history depth is 0
+	clones := 0
+	envNoHistory, err := NewEnvironment(func(width, height int) (canvas.Canvas, error) {
+		cnv, err := newCanvasFunc(width, height)
+		return &cloneCountingCanvas{cnv, &clones}, err
+	}, &mockRenderer{})
+	if err != nil {
+		panic(err)
+	}
+	envNoHistory.SetHistoryDepth(0)
+	for _, cmd := range []command.Command{
+		basic.NewCanvasCommand{Width: 3, Height: 1},
+		basic.DrawLineCommand{X1: 1, Y1: 1, X2: 2, Y2: 1},
+		basic.BucketFillCommand{X: 3, Y: 1, C: bytecolor.Color('o')},
+	} {
+		err = interp.Interpret(envNoHistory, cmd)
+		if err != nil {
+			t.Errorf("Case: %#v, Expected: err == nil, Got: %#v", cmd, err)
+		}
+	}
+	if clones != 0 {
+		t.Errorf("Case: Depth 0, Expected: 0 clones, Got: %d", clones)
+	}
+	err = interp.Interpret(envNoHistory, basic.UndoCommand{})
+	if err != common.ErrNothingToUndo {
+		t.Errorf("Case: Depth 0, Expected: %#v, Got: %#v", common.ErrNothingToUndo, err)
+	}
+
+	// Environments without history
+	envNeg := newMockEnvironment(newCanvasFunc)
+	for _, cmd := range []command.Command{basic.UndoCommand{}, basic.RedoCommand{}} {
+		err = interp.Interpret(envNeg, cmd)
+		if err != common.ErrEnvironmentNotSupported {
+			t.Errorf("Case: %#v, Expected: %#v, Got: %#v", cmd, common.ErrEnvironmentNotSupported, err)
+		}
+	}
+}
+
+func TestInterpreter_Interpret_Save(t *testing.T) {
+	dir, err := ioutil.TempDir("", "simple")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "canvas.txt")
+
+	interp, err := NewInterpreter()
+	if err != nil {
+		panic(err)
+	}
+	env, err := NewEnvironment(newCanvasFunc, &mockRenderer{})
+	if err != nil {
+		panic(err)
+	}
+	env.SetFileRendererFunc(".txt", func(w io.Writer) (renderer.Renderer, error) {
+		return writer.NewRenderer(w)
+	})
+	envNeg := newMockEnvironment(newCanvasFunc)
+	err = envNeg.NewCanvas(2, 1)
+	if err != nil {
+		panic(err)
+	}
+
+	cases := []struct {
+		env interface{}
+		cmd command.Command
+		err error
+	}{
+		{env, basic.SaveCommand{FileName: fileName}, common.ErrCanvasNotCreated},
+		{env, basic.NewCanvasCommand{Width: 2, Height: 1}, nil},
+		{env, basic.SaveCommand{FileName: fileName}, nil},
+		{env, basic.SaveCommand{FileName: fileName + ".png"}, common.ErrFileFormatNotSupported},
+		{env, basic.UndoCommand{}, nil},
+		{env, basic.UndoCommand{}, common.ErrNothingToUndo},
+		{envNeg, basic.SaveCommand{FileName: fileName}, common.ErrEnvironmentNotSupported},
+	}
+	for i, c := range cases {
+		err = interp.Interpret(c.env, c.cmd)
+		if err != c.err {
+			t.Errorf("Case #%d: Expected: %#v, Got: %#v", i, c.err, err)
+		}
+	}
+	if _, err := os.Stat(fileName); err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+}
+
+// This type is created for testing purpose only
+type countingRenderer struct {
+	count int
+}
+
+func (rdr *countingRenderer) Render(cnv canvas.Canvas) error {
+	rdr.count++
+	return nil
+}
+
+func TestInterpreter_Interpret_Macro(t *testing.T) {
+	interp, err := NewInterpreter()
+	if err != nil {
+		panic(err)
+	}
+	rdr := &countingRenderer{}
+	env, err := NewEnvironment(newCanvasFunc, rdr)
+	if err != nil {
+		panic(err)
+	}
+
+	cases := []struct {
+		cmd     command.Command
+		err     error
+		pixels  []bytecolor.Color
+		renders int
+	}{
+		{basic.EndMacroCommand{}, common.ErrMacroNotRecording, nil, 0},
+		{basic.ReplayMacroCommand{Name: "m"}, common.ErrMacroNotFound, nil, 0},
+		{basic.StartMacroCommand{Name: "m"}, nil, nil, 0},
+		{basic.StartMacroCommand{Name: "n"}, common.ErrMacroRecording, nil, 0},
+		{basic.NewCanvasCommand{Width: 6, Height: 1}, nil, []bytecolor.Color{' ', ' ', ' ', ' ', ' ', ' '}, 1},
+		{basic.DrawLineCommand{X1: 1, Y1: 1, X2: 1, Y2: 1}, nil, []bytecolor.Color{'x', ' ', ' ', ' ', ' ', ' '}, 1},
+		{basic.DrawLineCommand{X1: 9, Y1: 1, X2: 9, Y2: 1}, common.ErrPointOutsideCanvas, []bytecolor.Color{'x', ' ', ' ', ' ', ' ', ' '}, 0},
+		{basic.BucketFillCommand{X: 2, Y: 1, C: bytecolor.Color('o')}, nil, []bytecolor.Color{'x', 'o', 'o', 'o', 'o', 'o'}, 1},
+		{basic.UndoCommand{}, nil, []bytecolor.Color{'x', ' ', ' ', ' ', ' ', ' '}, 1},
+		{basic.FlipCommand{Whole: true}, nil, []bytecolor.Color{' ', ' ', ' ', ' ', ' ', 'x'}, 1},
+		{basic.FlipCommand{Whole: true}, nil, []bytecolor.Color{'x', ' ', ' ', ' ', ' ', ' '}, 1},
+		{basic.EndMacroCommand{}, nil, []bytecolor.Color{'x', ' ', ' ', ' ', ' ', ' '}, 0},
+		// The macro contains the line and the bucket fill only,
+		// since the flips of the whole canvas are not recorded
+		{basic.ReplayMacroCommand{Name: "m", DX: 2}, nil, []bytecolor.Color{'x', ' ', 'x', 'o', 'o', 'o'}, 1},
+		{basic.UndoCommand{}, nil, []bytecolor.Color{'x', ' ', ' ', ' ', ' ', ' '}, 1},
+		// The canvas is unchanged if the macro fails
+		{basic.ReplayMacroCommand{Name: "m", DX: 6}, common.ErrPointOutsideCanvas, []bytecolor.Color{'x', ' ', ' ', ' ', ' ', ' '}, 0},
+		// A replayed macro is recorded as the translated commands
+		{basic.StartMacroCommand{Name: "n"}, nil, []bytecolor.Color{'x', ' ', ' ', ' ', ' ', ' '}, 0},
+		{basic.ReplayMacroCommand{Name: "m", DX: 4}, nil, []bytecolor.Color{'x', ' ', ' ', ' ', 'x', 'o'}, 1},
+		{basic.EndMacroCommand{}, nil, []bytecolor.Color{'x', ' ', ' ', ' ', 'x', 'o'}, 0},
+		{basic.NewCanvasCommand{Width: 6, Height: 1}, nil, []bytecolor.Color{' ', ' ', ' ', ' ', ' ', ' '}, 1},
+		{basic.ReplayMacroCommand{Name: "n", DX: -3}, nil, []bytecolor.Color{' ', 'x', 'o', 'o', 'o', 'o'}, 1},
+	}
+	for i, c := range cases {
+		rdr.count = 0
+		err = interp.Interpret(env, c.cmd)
+		if err != c.err {
+			t.Errorf("Case #%d: Expected: %#v, Got: %#v", i, c.err, err)
+		}
+		if rdr.count != c.renders {
+			t.Errorf("Case #%d: Expected: %d renders, Got: %d", i, c.renders, rdr.count)
+		}
+		cnv := env.Canvas()
+		if c.pixels == nil {
+			if cnv != nil {
+				t.Errorf("Case #%d: Expected: cnv == nil, Got: %#v", i, cnv)
+			}
+			continue
+		}
+		pixels := cnv.(*bc.Buffer).Pixels()
+		if !reflect.DeepEqual(pixels, c.pixels) {
+			t.Errorf("Case #%d: Expected: %#v, Got: %#v", i, c.pixels, pixels)
+		}
+	}
+
+	// The flips of the whole canvas are not recorded
+	cmds, err := env.Macro("m")
+	if err != nil {
+		t.Errorf("Case: Macro m, Expected: err == nil, Got: %#v", err)
+	}
+	expected := []command.Command{
+		basic.DrawLineCommand{X1: 1, Y1: 1, X2: 1, Y2: 1},
+		basic.BucketFillCommand{X: 2, Y: 1, C: bytecolor.Color('o')},
+	}
+	if !reflect.DeepEqual(cmds, expected) {
+		t.Errorf("Case: Macro m, Expected: %#v, Got: %#v", expected, cmds)
+	}
+
+	// Environments without macros
+	envNeg := newMockEnvironment(newCanvasFunc)
+	for _, cmd := range []command.Command{basic.StartMacroCommand{Name: "m"}, basic.EndMacroCommand{}, basic.ReplayMacroCommand{Name: "m"}} {
+		err = interp.Interpret(envNeg, cmd)
+		if err != common.ErrEnvironmentNotSupported {
+			t.Errorf("Case: %#v, Expected: %#v, Got: %#v", cmd, common.ErrEnvironmentNotSupported, err)
+		}
+	}
+}
+
+func TestInterpreter_Interpret_Clipboard(t *testing.T) {
+	interp, err := NewInterpreter()
+	if err != nil {
+		panic(err)
+	}
+	rdr := &countingRenderer{}
+	env, err := NewEnvironment(newCanvasFunc, rdr)
+	if err != nil {
+		panic(err)
+	}
+
+	cases := []struct {
+		cmd     command.Command
+		err     error
+		pixels  []bytecolor.Color
+		renders int
+	}{
+		{basic.PasteCommand{X: 1, Y: 1}, common.ErrCanvasNotCreated, nil, 0},
+		{basic.NewCanvasCommand{Width: 4, Height: 2}, nil, []bytecolor.Color{' ', ' ', ' ', ' ', ' ', ' ', ' ', ' '}, 1},
+		{basic.PasteCommand{X: 1, Y: 1}, common.ErrClipboardEmpty, []bytecolor.Color{' ', ' ', ' ', ' ', ' ', ' ', ' ', ' '}, 0},
+		{basic.BucketFillCommand{X: 1, Y: 1, C: bytecolor.Color('o')}, nil, []bytecolor.Color{'o', 'o', 'o', 'o', 'o', 'o', 'o', 'o'}, 1},
+		{basic.DrawLineCommand{X1: 1, Y1: 1, X2: 1, Y2: 1}, nil, []bytecolor.Color{'x', 'o', 'o', 'o', 'o', 'o', 'o', 'o'}, 1},
+		{basic.CopyCommand{X1: 2, Y1: 2, X2: 1, Y2: 1}, nil, []bytecolor.Color{'x', 'o', 'o', 'o', 'o', 'o', 'o', 'o'}, 0},
+		{basic.CopyCommand{X1: 1, Y1: 1, X2: 5, Y2: 1}, common.ErrPointOutsideCanvas, []bytecolor.Color{'x', 'o', 'o', 'o', 'o', 'o', 'o', 'o'}, 0},
+		{basic.BucketFillCommand{X: 2, Y: 1, C: bytecolor.Color('-')}, nil, []bytecolor.Color{'x', '-', '-', '-', '-', '-', '-', '-'}, 1},
+		{basic.PasteCommand{X: 3, Y: 1}, nil, []bytecolor.Color{'x', '-', 'x', 'o', '-', '-', 'o', 'o'}, 1},
+		// The copy is not undone, so the paste is
+		{basic.UndoCommand{}, nil, []bytecolor.Color{'x', '-', '-', '-', '-', '-', '-', '-'}, 1},
+		{basic.PasteCommand{X: 3, Y: 1, Transparent: bytecolor.Color('o')}, nil, []bytecolor.Color{'x', '-', 'x', '-', '-', '-', '-', '-'}, 1},
+		// The canvas is unchanged if the region does not fit
+		{basic.PasteCommand{X: 4, Y: 1}, common.ErrPointOutsideCanvas, []bytecolor.Color{'x', '-', 'x', '-', '-', '-', '-', '-'}, 0},
+		{basic.PasteCommand{X: 0, Y: 1}, common.ErrPointOutsideCanvas, []bytecolor.Color{'x', '-', 'x', '-', '-', '-', '-', '-'}, 0},
+	}
+	for i, c := range cases {
+		rdr.count = 0
+		err = interp.Interpret(env, c.cmd)
+		if err != c.err {
+			t.Errorf("Case #%d: Expected: %#v, Got: %#v", i, c.err, err)
+		}
+		if rdr.count != c.renders {
+			t.Errorf("Case #%d: Expected: %d renders, Got: %d", i, c.renders, rdr.count)
+		}
+		cnv := env.Canvas()
+		if c.pixels == nil {
+			if cnv != nil {
+				t.Errorf("Case #%d: Expected: cnv == nil, Got: %#v", i, cnv)
+			}
+			continue
+		}
+		pixels := cnv.(*bc.Buffer).Pixels()
+		if !reflect.DeepEqual(pixels, c.pixels) {
+			t.Errorf("Case #%d: Expected: %#v, Got: %#v", i, c.pixels, pixels)
+		}
+	}
+
+	// The canvas is unchanged if a color of the clip is not supported
+	env.SetClipboard(&Clip{2, 1, []color.Color{bytecolor.Color('y'), dummyColor('z')}})
+	err = interp.Interpret(env, basic.PasteCommand{X: 1, Y: 1})
+	if err != common.ErrColorTypeNotSupported {
+		t.Errorf("Case: Color Type, Expected: %#v, Got: %#v", common.ErrColorTypeNotSupported, err)
+	}
+	expected := []bytecolor.Color{'x', '-', 'x', '-', '-', '-', '-', '-'}
+	pixels := env.Canvas().(*bc.Buffer).Pixels()
+	if !reflect.DeepEqual(pixels, expected) {
+		t.Errorf("Case: Color Type, Expected: %#v, Got: %#v", expected, pixels)
+	}
+
+	cmds := []command.Command{basic.CopyCommand{X1: 1, Y1: 1, X2: 1, Y2: 1}, basic.PasteCommand{X: 1, Y: 1}}
+
+	// Environments without a clipboard
+	envNeg := newMockEnvironment(newCanvasFunc)
+	err = interp.Interpret(envNeg, basic.NewCanvasCommand{Width: 1, Height: 1})
+	if err != nil {
+		panic(err)
+	}
+	for _, cmd := range cmds {
+		err = interp.Interpret(envNeg, cmd)
+		if err != common.ErrEnvironmentNotSupported {
+			t.Errorf("Case: %#v, Expected: %#v, Got: %#v", cmd, common.ErrEnvironmentNotSupported, err)
+		}
+	}
+
+	// Canvases which are not buffer based
+	envMock, err := NewEnvironment(newMockCanvas, &mockRenderer{})
+	if err != nil {
+		panic(err)
+	}
+	err = envMock.NewCanvas(1, 1)
+	if err != nil {
+		panic(err)
+	}
+	envMock.SetClipboard(&Clip{1, 1, []color.Color{bytecolor.Color('x')}})
+	for _, cmd := range cmds {
+		err = interp.Interpret(envMock, cmd)
+		if err != common.ErrCanvasNotSupported {
+			t.Errorf("Case: %#v, Expected: %#v, Got: %#v", cmd, common.ErrCanvasNotSupported, err)
+		}
+	}
+}
+
+func TestInterpreter_Interpret_CanvasLimit(t *testing.T) {
+	interp, err := NewInterpreter()
+	if err != nil {
+		panic(err)
+	}
+	env, err := NewEnvironment(newCanvasFunc, &mockRenderer{})
+	if err != nil {
+		panic(err)
+	}
+	err = env.SetMaxCanvasSize(4, 2)
+	if err != nil {
+		panic(err)
+	}
+	const maxInt = int(^uint(0) >> 1)
+
+	cases := []struct {
+		cmd command.Command
+		err error
+	}{
+		{basic.NewCanvasCommand{Width: 5, Height: 1}, common.ErrCanvasTooLarge},
+		{basic.NewCanvasCommand{Width: 4, Height: 1}, nil},
+		{basic.ExtendCanvasCommand{Bottom: 2}, common.ErrCanvasTooLarge},
+		{basic.ExtendCanvasCommand{Left: maxInt, Right: maxInt}, common.ErrCanvasTooLarge},
+		{basic.ExtendCanvasCommand{Left: -1}, common.ErrExtentNegative},
+		{basic.ScaleCanvasCommand{Width: 4, Height: 3}, common.ErrCanvasTooLarge},
+		{basic.RotateCommand{Degrees: 90, Whole: true}, common.ErrCanvasTooLarge},
+		{basic.RotateCommand{Degrees: 180, Whole: true}, nil},
+		{basic.ExtendCanvasCommand{Top: 1}, nil},
+	}
+	for _, c := range cases {
+		err = interp.Interpret(env, c.cmd)
+		if err != c.err {
+			t.Errorf("Case: %#v, Expected: %#v, Got: %#v", c.cmd, c.err, err)
+		}
+	}
+	width, height := env.Canvas().Dimensions()
+	if width != 4 || height != 2 {
+		t.Errorf("Expected: 4 x 2, Got: %d x %d", width, height)
+	}
+}
+
+func TestInterpreter_Interpret_MacroLimit(t *testing.T) {
+	interp, err := NewInterpreter()
+	if err != nil {
+		panic(err)
+	}
+	env, err := NewEnvironment(newCanvasFunc, &mockRenderer{})
+	if err != nil {
+		panic(err)
+	}
+	err = env.SetMaxMacroCommands(10)
+	if err != nil {
+		panic(err)
+	}
+
+	cases := []struct {
+		cmd command.Command
+		err error
+	}{
+		{basic.NewCanvasCommand{Width: 4, Height: 1}, nil},
+		{basic.StartMacroCommand{Name: "m"}, nil},
+		{basic.DrawLineCommand{X1: 1, Y1: 1, X2: 1, Y2: 1}, nil},
+		{basic.EndMacroCommand{}, nil},
+		// Each replay of a macro is recorded as the commands in it
+		{basic.StartMacroCommand{Name: "n"}, nil},
+		{basic.ReplayMacroCommand{Name: "m"}, nil},
+		{basic.ReplayMacroCommand{Name: "m", DX: 1}, nil},
+		{basic.ReplayMacroCommand{Name: "m", DX: 2}, nil},
+		{basic.EndMacroCommand{}, nil},
+		{basic.StartMacroCommand{Name: "o"}, nil},
+		{basic.ReplayMacroCommand{Name: "n"}, nil},
+		// The replays expanding beyond the limit fail
+		{basic.ReplayMacroCommand{Name: "n"}, common.ErrTooManyMacroCommands},
+		{basic.DrawLineCommand{X1: 4, Y1: 1, X2: 4, Y2: 1}, common.ErrTooManyMacroCommands},
+		{basic.EndMacroCommand{}, nil},
+		{basic.StartMacroCommand{Name: "p"}, common.ErrTooManyMacroCommands},
+		// Not limited if no macro is being recorded
+		{basic.ReplayMacroCommand{Name: "o"}, nil},
+		{basic.DrawLineCommand{X1: 4, Y1: 1, X2: 4, Y2: 1}, nil},
+	}
+	for _, c := range cases {
+		err = interp.Interpret(env, c.cmd)
+		if err != c.err {
+			t.Errorf("Case: %#v, Expected: %#v, Got: %#v", c.cmd, c.err, err)
+		}
+	}
+	expected := []bytecolor.Color{'x', 'x', 'x', 'x'}
+	pixels := env.Canvas().(*bc.Buffer).Pixels()
+	if !reflect.DeepEqual(pixels, expected) {
+		t.Errorf("Expected: %#v, Got: %#v", expected, pixels)
+	}
 }
@@ -0,0 +1,115 @@
+package simple
+
+import (
+	"sync"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/command"
+	"github.com/asukakenji/drawing-challenge/common"
+	"github.com/asukakenji/drawing-challenge/interpreter"
+)
+
+// SyncEnvironment is a wrapper of Environment which is safe for concurrent use.
+// It implements the Quitter interface.
+//
+// The commands are interpreted by Interpret, which changes the wrapped
+// Environment with the write lock held. Canvas and View read the canvas
+// with the read lock held, so that they never see a partially applied command.
+//
+// SyncEnvironment does not implement the CanvasContainer interface
+// nor the renderer.Renderer interface, so that it could not be passed to
+// an interpreter directly, bypassing the lock.
+type SyncEnvironment struct {
+	mutex sync.RWMutex
+	env   *Environment
+}
+
+// Ensure that SyncEnvironment implements the Quitter interface.
+var (
+	_ Quitter = &SyncEnvironment{}
+)
+
+// NewSyncEnvironment returns a new SyncEnvironment wrapping env.
+// env should not be used directly hereafter.
+//
+// Errors
+//
+// common.ErrNilPointer:
+// Will be returned if env == nil.
+//
+func NewSyncEnvironment(env *Environment) (*SyncEnvironment, error) {
+	if env == nil {
+		return nil, common.ErrNilPointer
+	}
+	return &SyncEnvironment{
+		env: env,
+	}, nil
+}
+
+// Interpret interprets cmd by interp with the wrapped Environment.
+// The write lock is held until interp returns,
+// including the time the canvas is rendered by the wrapped Environment.
+//
+// Errors
+//
+// Errors returned from interp are returned without modifications.
+//
+func (senv *SyncEnvironment) Interpret(interp interpreter.Interpreter, cmd command.Command) error {
+	senv.mutex.Lock()
+	defer senv.mutex.Unlock()
+	return interp.Interpret(senv.env, cmd)
+}
+
+// Canvas returns a snapshot of the contained canvas.Canvas,
+// or nil if it has not been created.
+// The snapshot is a clone, which is not changed by the commands hereafter.
+func (senv *SyncEnvironment) Canvas() canvas.Canvas {
+	senv.mutex.RLock()
+	defer senv.mutex.RUnlock()
+	if senv.env.cnv == nil {
+		return nil
+	}
+	return senv.env.cnv.Clone()
+}
+
+// View calls f with the contained canvas.Canvas, or nil if it has not been created.
+// The read lock is held until f returns, so that the canvas is not changed
+// meanwhile. f must not change the canvas, nor call the methods which
+// acquire the write lock.
+//
+// Errors
+//
+// Errors returned from f are returned without modifications.
+//
+func (senv *SyncEnvironment) View(f func(canvas.Canvas) error) error {
+	senv.mutex.RLock()
+	defer senv.mutex.RUnlock()
+	return f(senv.env.cnv)
+}
+
+// ShouldQuit returns if the program should quit.
+func (senv *SyncEnvironment) ShouldQuit() bool {
+	senv.mutex.RLock()
+	defer senv.mutex.RUnlock()
+	return senv.env.ShouldQuit()
+}
+
+// SetQuit causes ShouldQuit to return true hereafter.
+func (senv *SyncEnvironment) SetQuit() {
+	senv.mutex.Lock()
+	defer senv.mutex.Unlock()
+	senv.env.SetQuit()
+}
+
+// SetHistoryDepth sets the maximum number of changes which could be undone.
+//
+// Errors
+//
+// common.ErrHistoryDepthNegative:
+// Will be returned if depth < 0.
+//
+func (senv *SyncEnvironment) SetHistoryDepth(depth int) error {
+	senv.mutex.Lock()
+	defer senv.mutex.Unlock()
+	return senv.env.SetHistoryDepth(depth)
+}
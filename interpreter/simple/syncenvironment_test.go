@@ -0,0 +1,176 @@
+package simple
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/command/basic"
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+func newSyncEnvironment() *SyncEnvironment {
+	env, err := NewEnvironment(newCanvasFunc, &mockRenderer{})
+	if err != nil {
+		panic(err)
+	}
+	senv, err := NewSyncEnvironment(env)
+	if err != nil {
+		panic(err)
+	}
+	return senv
+}
+
+// checkUniform returns whether all pixels of cnv have the same color.
+func checkUniform(cnv canvas.Canvas) bool {
+	bbcnv := cnv.(canvas.BufferBasedCanvas)
+	width, height := bbcnv.Dimensions()
+	first, _ := bbcnv.At(0, 0)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c, _ := bbcnv.At(x, y)
+			if c != first {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestNewSyncEnvironment(t *testing.T) {
+	env, _ := NewEnvironment(newCanvasFunc, &mockRenderer{})
+	_, err := NewSyncEnvironment(env)
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 0, nil, err)
+	}
+
+	_, err = NewSyncEnvironment(nil)
+	if err != common.ErrNilPointer {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 1, common.ErrNilPointer, err)
+	}
+}
+
+func TestSyncEnvironment_NotInterpretable(t *testing.T) {
+	interp, _ := NewInterpreter()
+	err := interp.Interpret(newSyncEnvironment(), basic.EmptyCommand{})
+	if err != common.ErrEnvironmentNotSupported {
+		t.Errorf("Expected: err == %#v, Got: %#v", common.ErrEnvironmentNotSupported, err)
+	}
+}
+
+func TestSyncEnvironment_Canvas(t *testing.T) {
+	senv := newSyncEnvironment()
+	interp, _ := NewInterpreter()
+
+	cnv := senv.Canvas()
+	if cnv != nil {
+		t.Errorf("Case #%d: Expected: cnv == %#v, Got: %#v", 0, nil, cnv)
+	}
+
+	err := senv.Interpret(interp, basic.NewCanvasCommand{Width: 3, Height: 1})
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 1, nil, err)
+	}
+	snapshot := senv.Canvas()
+	err = senv.Interpret(interp, basic.DrawLineCommand{X1: 1, Y1: 1, X2: 3, Y2: 1})
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 2, nil, err)
+	}
+	c, _ := snapshot.(canvas.BufferBasedCanvas).At(0, 0)
+	if c != bytecolor.Color(' ') {
+		t.Errorf("Case #%d: Expected: c == %#v, Got: %#v", 3, bytecolor.Color(' '), c)
+	}
+	err = senv.View(func(cnv canvas.Canvas) error {
+		c, _ := cnv.(canvas.BufferBasedCanvas).At(0, 0)
+		if c != bytecolor.Color('x') {
+			t.Errorf("Case #%d: Expected: c == %#v, Got: %#v", 4, bytecolor.Color('x'), c)
+		}
+		return common.ErrNilPointer
+	})
+	if err != common.ErrNilPointer {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 5, common.ErrNilPointer, err)
+	}
+}
+
+func TestSyncEnvironment_SetQuit(t *testing.T) {
+	senv := newSyncEnvironment()
+	if senv.ShouldQuit() {
+		t.Errorf("Case #%d: Expected: %t, Got: %t", 0, false, true)
+	}
+	senv.SetQuit()
+	if !senv.ShouldQuit() {
+		t.Errorf("Case #%d: Expected: %t, Got: %t", 1, true, false)
+	}
+}
+
+func TestSyncEnvironment_SetHistoryDepth(t *testing.T) {
+	senv := newSyncEnvironment()
+	err := senv.SetHistoryDepth(-1)
+	if err != common.ErrHistoryDepthNegative {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 0, common.ErrHistoryDepthNegative, err)
+	}
+	err = senv.SetHistoryDepth(0)
+	if err != nil {
+		t.Errorf("Case #%d: Expected: err == %#v, Got: %#v", 1, nil, err)
+	}
+}
+
+// TestSyncEnvironment_Concurrent should be run with the race detector enabled:
+//
+//     go test -race
+//
+func TestSyncEnvironment_Concurrent(t *testing.T) {
+	const writers, readers, iterations = 4, 4, 200
+	senv := newSyncEnvironment()
+	interp, _ := NewInterpreter()
+	err := senv.Interpret(interp, basic.NewCanvasCommand{Width: 8, Height: 8})
+	if err != nil {
+		panic(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// NOTE: Each command fills the whole canvas with a single color
+			c := bytecolor.Color('a' + i)
+			for j := 0; j < iterations; j++ {
+				err := senv.Interpret(interp, basic.FillRectCommand{X1: 1, Y1: 1, X2: 8, Y2: 8, Fill: c, Stroke: c})
+				if err != nil {
+					t.Errorf("Writer #%d: Expected: err == %#v, Got: %#v", i, nil, err)
+					return
+				}
+				if j%50 == 0 {
+					senv.Interpret(interp, basic.UndoCommand{})
+				}
+			}
+		}(i)
+	}
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				if !checkUniform(senv.Canvas()) {
+					t.Errorf("Reader #%d: Expected: a consistent snapshot from Canvas", i)
+					return
+				}
+				senv.View(func(cnv canvas.Canvas) error {
+					if !checkUniform(cnv) {
+						t.Errorf("Reader #%d: Expected: a consistent canvas in View", i)
+					}
+					return nil
+				})
+				senv.ShouldQuit()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	senv.SetQuit()
+	if !senv.ShouldQuit() {
+		t.Errorf("Expected: %t, Got: %t", true, false)
+	}
+}
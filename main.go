@@ -5,37 +5,136 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"unicode/utf8"
 
 	"github.com/asukakenji/drawing-challenge/canvas"
 	bc "github.com/asukakenji/drawing-challenge/canvas/bytecolor"
+	rc "github.com/asukakenji/drawing-challenge/canvas/rgba"
+	"github.com/asukakenji/drawing-challenge/color"
 	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/color/rgba"
 	"github.com/asukakenji/drawing-challenge/command/basic"
+	"github.com/asukakenji/drawing-challenge/command/script"
+	"github.com/asukakenji/drawing-challenge/common"
 	"github.com/asukakenji/drawing-challenge/interpreter/simple"
+	"github.com/asukakenji/drawing-challenge/renderer"
+	"github.com/asukakenji/drawing-challenge/renderer/ansi"
+	"github.com/asukakenji/drawing-challenge/renderer/json"
+	"github.com/asukakenji/drawing-challenge/renderer/png"
+	"github.com/asukakenji/drawing-challenge/renderer/svg"
 	"github.com/asukakenji/drawing-challenge/renderer/writer"
+	"github.com/asukakenji/drawing-challenge/server/collab"
+	"github.com/asukakenji/drawing-challenge/server/httpapi"
 )
 
 const (
+	// Prompt is printed before reading each command interactively.
+	Prompt = "enter command: "
+
 	// DefaultBGColorString is the default value for bgColorString.
 	DefaultBGColorString = " "
 
 	// DefaultFGColorString is the default value for fgColorString.
 	DefaultFGColorString = "x"
+
+	// DefaultRGBABGColorString is the default background color
+	// if useRGBA is true and bgColorString is not changed.
+	DefaultRGBABGColorString = "white"
+
+	// DefaultRGBAFGColorString is the default foreground color
+	// if useRGBA is true and fgColorString is not changed.
+	DefaultRGBAFGColorString = "black"
+
+	// DefaultUseRGBA is the default value for useRGBA.
+	DefaultUseRGBA = false
+
+	// DefaultRendererName is the default value for rendererName.
+	DefaultRendererName = "auto"
+
+	// DefaultColorModeString is the default value for colorModeString.
+	DefaultColorModeString = "auto"
+
+	// DefaultStrictLines is the default value for strictLines.
+	DefaultStrictLines = false
+
+	// DefaultBitmapText is the default value for bitmapText.
+	DefaultBitmapText = false
+
+	// DefaultHistoryDepth is the default value for historyDepth.
+	DefaultHistoryDepth = simple.DefaultHistoryDepth
+
+	// DefaultScriptFile is the default value for scriptFile.
+	DefaultScriptFile = ""
+
+	// DefaultKeepGoing is the default value for keepGoing.
+	DefaultKeepGoing = false
+
+	// DefaultScripting is the default value for scripting.
+	DefaultScripting = false
+
+	// DefaultPNGScale is the default value for pngScale.
+	DefaultPNGScale = 1
+
+	// DefaultSVGScale is the default value for svgScale.
+	DefaultSVGScale = 10
+
+	// DefaultSVGVector is the default value for svgVector.
+	DefaultSVGVector = false
+
+	// DefaultHTTPAddr is the default value for httpAddr.
+	DefaultHTTPAddr = ""
+
+	// DefaultCollabAddr is the default value for collabAddr.
+	DefaultCollabAddr = ""
 )
 
 var (
-	bgColorString string
-	fgColorString string
+	bgColorString   string
+	fgColorString   string
+	useRGBA         bool
+	rendererName    string
+	colorModeString string
+	strictLines     bool
+	bitmapText      bool
+	historyDepth    int
+	scriptFile      string
+	keepGoing       bool
+	scripting       bool
+	pngScale        int
+	svgScale        int
+	svgVector       bool
+	httpAddr        string
+	collabAddr      string
 )
 
 func init() {
 	flag.StringVar(&bgColorString, "bgColor", DefaultBGColorString, "The background color of the canvas")
 	flag.StringVar(&fgColorString, "fgColor", DefaultFGColorString, "The foreground color of the canvas")
+	flag.BoolVar(&useRGBA, "rgba", DefaultUseRGBA, "Use 32-bit RGBA colors (like \"#ff8000\" or \"orange\") instead of characters")
+	flag.StringVar(&rendererName, "renderer", DefaultRendererName, "The renderer for the screen: \"text\", \"ansi\", \"halfblock\", \"braille\", or \"auto\"")
+	flag.StringVar(&colorModeString, "colorMode", DefaultColorModeString, "The color mode of the ansi, halfblock, and braille renderers: \"16\", \"256\", \"truecolor\", or \"auto\"")
+	flag.BoolVar(&strictLines, "strictLines", DefaultStrictLines, "Accept horizontal and vertical lines only")
+	flag.BoolVar(&bitmapText, "bitmapText", DefaultBitmapText, "Draw text with the built-in 5x7 bitmap font")
+	flag.IntVar(&historyDepth, "historyDepth", DefaultHistoryDepth, "The maximum number of changes which could be undone")
+	flag.StringVar(&scriptFile, "script", DefaultScriptFile, "The script file to be executed non-interactively")
+	flag.BoolVar(&keepGoing, "keep-going", DefaultKeepGoing, "Continue executing the scripts after a failure")
+	flag.BoolVar(&scripting, "scripting", DefaultScripting, "Execute the scripts with variables, expressions, loops, and procedures")
+	flag.IntVar(&pngScale, "pngScale", DefaultPNGScale, "The size of each pixel in the saved PNG images")
+	flag.IntVar(&svgScale, "svgScale", DefaultSVGScale, "The size of each pixel in the saved SVG images")
+	flag.BoolVar(&svgVector, "svgVector", DefaultSVGVector, "Record the commands to save lines and shapes as vector elements in SVG images")
+	flag.StringVar(&httpAddr, "http", DefaultHTTPAddr, "Serve the HTTP API on the address (like \":8080\") instead of reading commands")
+	flag.StringVar(&collabAddr, "collab", DefaultCollabAddr, "Serve a shared canvas over WebSocket on the address (like \":8080\") instead of reading commands")
 }
 
 var (
-	input  io.Reader = os.Stdin
-	output io.Writer = os.Stdout
+	input     io.Reader = os.Stdin
+	output    io.Writer = os.Stdout
+	errOutput io.Writer = os.Stderr
+	exit                = os.Exit
+
+	listenAndServe = http.ListenAndServe
 )
 
 func main() {
@@ -43,23 +142,45 @@ func main() {
 	flag.Parse()
 
 	// Setup color parser
-	colorParser := &bytecolor.Parser{
+	var colorParser color.Parser = &bytecolor.Parser{
 		DefaultColor: bytecolor.Color(' '),
 	}
+	_bgColorString, _fgColorString := bgColorString, fgColorString
+	if useRGBA {
+		colorParser = &rgba.Parser{
+			DefaultColor: rgba.Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		}
+		if _bgColorString == DefaultBGColorString {
+			_bgColorString = DefaultRGBABGColorString
+		}
+		if _fgColorString == DefaultFGColorString {
+			_fgColorString = DefaultRGBAFGColorString
+		}
+	}
 
 	// Setup background color
-	_bgColor, err := colorParser.ParseColor(bgColorString)
+	bgColor, err := colorParser.ParseColor(_bgColorString)
 	if err != nil {
 		panic(err)
 	}
-	bgColor := _bgColor.(bytecolor.Color)
 
 	// Setup foreground color
-	_fgColor, err := colorParser.ParseColor(fgColorString)
+	fgColor, err := colorParser.ParseColor(_fgColorString)
 	if err != nil {
 		panic(err)
 	}
-	fgColor := _fgColor.(bytecolor.Color)
+
+	// Setup line mode
+	lineMode := canvas.LineModeAnyAngle
+	if strictLines {
+		lineMode = canvas.LineModeHorizontalOrVertical
+	}
+
+	// Setup text mode
+	textMode := canvas.TextModeCharacter
+	if bitmapText || useRGBA {
+		textMode = canvas.TextModeBitmap
+	}
 
 	// Setup command parser (the only possible error is common.ErrNilPointer)
 	commandParser, _ := basic.NewParser(colorParser.ParseColor)
@@ -67,28 +188,136 @@ func main() {
 	// Setup interpreter (no error)
 	interp, _ := simple.NewInterpreter()
 
-	// Setup renderer (the only possible error is common.ErrNilPointer)
-	rdr, err := writer.NewRenderer(output)
+	// Setup renderer
+	_rendererName := rendererName
+	if _rendererName == "auto" {
+		_rendererName = "text"
+		if useRGBA {
+			_rendererName = "ansi"
+		}
+	}
+	colorMode := ansi.DetectColorMode(os.Getenv("COLORTERM"), os.Getenv("TERM"))
+	if colorModeString != "auto" {
+		colorMode, err = ansi.ParseColorMode(colorModeString)
+		if err != nil {
+			panic(err)
+		}
+	}
+	var rdr renderer.Renderer
+	switch _rendererName {
+	case "text":
+		rdr, err = writer.NewRenderer(output)
+	case "ansi":
+		rdr, err = ansi.NewRenderer(output, colorMode, bytecolor.DefaultPalette())
+	case "halfblock":
+		rdr, err = ansi.NewHalfBlockRenderer(output, colorMode, bytecolor.DefaultPalette())
+	case "braille":
+		rdr, err = ansi.NewBrailleRenderer(output, colorMode, bytecolor.DefaultPalette(), bgColor)
+	default:
+		err = common.ErrUnknownRenderer
+	}
 	if err != nil {
 		panic(err)
 	}
 
 	// Setup environment (the only possible error is common.ErrNilPointer)
 	newCanvasFunc := func(width, height int) (canvas.Canvas, error) {
-		return bc.NewBuffer(width, height, bgColor, fgColor)
+		var cnv canvas.Canvas
+		var err error
+		if useRGBA {
+			cnv, err = rc.NewBuffer(width, height, bgColor.(rgba.Color), fgColor.(rgba.Color))
+		} else {
+			cnv, err = bc.NewBuffer(width, height, bgColor.(bytecolor.Color), fgColor.(bytecolor.Color))
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = cnv.SetLineMode(lineMode)
+		if err != nil {
+			return nil, err
+		}
+		err = cnv.SetTextMode(textMode)
+		if err != nil {
+			return nil, err
+		}
+		if svgVector {
+			cnv, err = svg.NewRecorder(cnv)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return cnv, nil
 	}
 	env, _ := simple.NewEnvironment(newCanvasFunc, rdr)
 
+	// Setup history depth
+	err = env.SetHistoryDepth(historyDepth)
+	if err != nil {
+		panic(err)
+	}
+
+	// Setup file renderers
+	if pngScale <= 0 {
+		panic(common.ErrScaleNotPositive)
+	}
+	env.SetFileRendererFunc(".png", func(w io.Writer) (renderer.Renderer, error) {
+		return png.NewRenderer(w, bytecolor.DefaultPalette(), pngScale)
+	})
+	if svgScale <= 0 {
+		panic(common.ErrScaleNotPositive)
+	}
+	env.SetFileRendererFunc(".svg", func(w io.Writer) (renderer.Renderer, error) {
+		return svg.NewRenderer(w, bytecolor.DefaultPalette(), svgScale)
+	})
+	env.SetFileRendererFunc(".txt", func(w io.Writer) (renderer.Renderer, error) {
+		return writer.NewRenderer(w)
+	})
+	env.SetFileRendererFunc(".ans", func(w io.Writer) (renderer.Renderer, error) {
+		return ansi.NewRenderer(w, ansi.ColorModeTrueColor, bytecolor.DefaultPalette())
+	})
+
+	// Serve the HTTP API, if requested
+	if httpAddr != "" {
+		if !serveHTTP(newCanvasFunc, commandParser, interp) {
+			exit(1)
+		}
+		return
+	}
+
+	// Serve the shared canvas, if requested
+	if collabAddr != "" {
+		if !serveCollab(newCanvasFunc, commandParser, interp) {
+			exit(1)
+		}
+		return
+	}
+
+	// Execute the scripts non-interactively, if any
+	scriptFiles := flag.Args()
+	if scriptFile != "" {
+		scriptFiles = append([]string{scriptFile}, scriptFiles...)
+	}
+	if len(scriptFiles) != 0 {
+		if !runScriptFiles(scriptFiles, commandParser, interp, env) {
+			exit(1)
+		}
+		return
+	}
+
 	stdin := bufio.NewScanner(input)
 	stdin.Split(bufio.ScanLines)
 	for !env.ShouldQuit() {
-		fmt.Fprint(output, "enter command: ")
+		fmt.Fprint(output, Prompt)
 		if !stdin.Scan() {
 			break
 		}
 		line := stdin.Text()
 		cmd, err := commandParser.ParseCommand(line)
 		if err != nil {
+			if column, ok := errorColumn(line, err); ok {
+				// NOTE: The caret is aligned with the line echoed after the prompt
+				fmt.Fprintf(output, "%*s^\n", utf8.RuneCountInString(Prompt)+column-1, "")
+			}
 			fmt.Fprintln(output, err)
 			continue
 		}
@@ -99,3 +328,178 @@ func main() {
 		}
 	}
 }
+
+// serveHTTP serves the HTTP API on httpAddr.
+// Each session of the API creates its canvas by newCanvasFunc.
+// It returns false if the server fails.
+func serveHTTP(newCanvasFunc func(int, int) (canvas.Canvas, error), commandParser *basic.Parser, interp *simple.Interpreter) bool {
+	// Setup server (the only possible error is common.ErrNilPointer)
+	srv, _ := httpapi.NewServer(newCanvasFunc, commandParser, interp)
+	err := srv.SetHistoryDepth(historyDepth)
+	if err != nil {
+		panic(err)
+	}
+	srv.SetFormat("text", "text/plain; charset=utf-8", func(w io.Writer) (renderer.Renderer, error) {
+		return writer.NewRenderer(w)
+	})
+	srv.SetFormat("json", "application/json", func(w io.Writer) (renderer.Renderer, error) {
+		return json.NewRenderer(w, bytecolor.DefaultPalette())
+	})
+	srv.SetFormat("png", "image/png", func(w io.Writer) (renderer.Renderer, error) {
+		return png.NewRenderer(w, bytecolor.DefaultPalette(), pngScale)
+	})
+	srv.SetFormat("svg", "image/svg+xml", func(w io.Writer) (renderer.Renderer, error) {
+		return svg.NewRenderer(w, bytecolor.DefaultPalette(), svgScale)
+	})
+
+	err = listenAndServe(httpAddr, srv)
+	if err != nil {
+		fmt.Fprintln(errOutput, err)
+		return false
+	}
+	return true
+}
+
+// serveCollab serves the shared canvas on collabAddr.
+// The web client is served at "/", and the WebSocket endpoint at "/ws".
+// It returns false if the hub could not be created,
+// or if the server could not be started.
+func serveCollab(newCanvasFunc func(int, int) (canvas.Canvas, error), commandParser *basic.Parser, interp *simple.Interpreter) bool {
+	// Setup hub
+	hub, err := collab.NewHub(newCanvasFunc, commandParser, interp, bytecolor.DefaultPalette())
+	if err != nil {
+		fmt.Fprintln(errOutput, err)
+		return false
+	}
+	err = hub.SetHistoryDepth(historyDepth)
+	if err != nil {
+		panic(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", collab.PageHandler{})
+	mux.Handle("/ws", hub)
+
+	err = listenAndServe(collabAddr, mux)
+	if err != nil {
+		fmt.Fprintln(errOutput, err)
+		return false
+	}
+	return true
+}
+
+// errorColumn returns the one-based column of the token causing err
+// in line, if err is a *basic.ParseError.
+func errorColumn(line string, err error) (int, bool) {
+	perr, ok := err.(*basic.ParseError)
+	if !ok {
+		return 0, false
+	}
+	return utf8.RuneCountInString(line[:perr.Offset]) + 1, true
+}
+
+// runScriptFiles executes the scripts in names one by one,
+// until the environment should quit.
+// It returns false if any failure occurs.
+// Unless keepGoing is set, it stops at the first failure.
+func runScriptFiles(names []string, commandParser *basic.Parser, interp *simple.Interpreter, env *simple.Environment) bool {
+	ok := true
+	for _, name := range names {
+		if env.ShouldQuit() {
+			break
+		}
+		if !runScriptFile(name, commandParser, interp, env) {
+			ok = false
+			if !keepGoing {
+				break
+			}
+		}
+	}
+	return ok
+}
+
+// runScriptFile executes the script in the file called name.
+// The script contains one command per line, unless scripting is set,
+// in which case it is run by runProgram.
+// Failures are reported to errOutput in the form "name:line: error",
+// or "name:line:column: error" if the command could not be parsed.
+// It returns false if any failure occurs.
+// Unless keepGoing is set, it stops at the first failure.
+func runScriptFile(name string, commandParser *basic.Parser, interp *simple.Interpreter, env *simple.Environment) bool {
+	f, err := os.Open(name)
+	if err != nil {
+		fmt.Fprintf(errOutput, "%s: %v\n", name, err)
+		return false
+	}
+	defer f.Close()
+
+	if scripting {
+		return runProgram(name, f, commandParser, interp, env)
+	}
+
+	ok := true
+	scanner := bufio.NewScanner(f)
+	scanner.Split(bufio.ScanLines)
+	for lineNumber := 1; !env.ShouldQuit() && scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		cmd, err := commandParser.ParseCommand(line)
+		if err == nil {
+			err = interp.Interpret(env, cmd)
+		}
+		if err != nil {
+			if column, parsed := errorColumn(line, err); parsed {
+				fmt.Fprintf(errOutput, "%s:%d:%d: %v\n", name, lineNumber, column, err)
+			} else {
+				fmt.Fprintf(errOutput, "%s:%d: %v\n", name, lineNumber, err)
+			}
+			ok = false
+			if !keepGoing {
+				return false
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(errOutput, "%s: %v\n", name, err)
+		return false
+	}
+	return ok
+}
+
+// runProgram runs the script read from r, which is in the file called name,
+// with variables, expressions, loops, and procedures.
+// Failures are reported to errOutput in the form "name:line: error",
+// or "name:line:column: error" if the column of the failure is known.
+// If the script could not be parsed, the statements before the syntax error
+// are still run, and the syntax error is reported after them.
+// It returns false if any failure occurs.
+// Unless keepGoing is set, it stops at the first failure.
+func runProgram(name string, r io.Reader, commandParser *basic.Parser, interp *simple.Interpreter, env *simple.Environment) bool {
+	prog, err := script.Parse(r)
+	if prog == nil {
+		fmt.Fprintf(errOutput, "%s: %v\n", name, err)
+		return false
+	}
+
+	// Setup runner (the only possible error is common.ErrNilPointer)
+	runner, _ := script.NewRunner(commandParser, interp)
+	ok := true
+	runErr := runner.Run(prog, env, func(err *script.Error) bool {
+		reportScriptError(name, err)
+		ok = false
+		return keepGoing
+	})
+	if serr, isErr := err.(*script.Error); isErr && runErr == nil && !env.ShouldQuit() {
+		reportScriptError(name, serr)
+		ok = false
+	}
+	return ok
+}
+
+// reportScriptError reports err, which occurs in the script in the file
+// called name, to errOutput.
+func reportScriptError(name string, err *script.Error) {
+	if err.Column != 0 {
+		fmt.Fprintf(errOutput, "%s:%d:%d: %v\n", name, err.Line, err.Column, err)
+	} else {
+		fmt.Fprintf(errOutput, "%s:%d: %v\n", name, err.Line, err)
+	}
+}
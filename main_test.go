@@ -2,8 +2,17 @@ package main
 
 import (
 	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/asukakenji/drawing-challenge/common"
+	"github.com/asukakenji/drawing-challenge/server/websocket"
 )
 
 func TestMain(t *testing.T) {
@@ -13,8 +22,16 @@ L 1 2 3 4
 C 20 4
 L 1 2 6 2
 L 6 3 6 4
+L 1 1 3 4
 R 14 1 18 3
 B 10 3 o
+L 1 4 4 4 R
+FG G
+R 1 1 3 3
+T 8 2 "label"
+U
+U
+Y
 `
 	input = strings.NewReader(inputText)
 
@@ -57,6 +74,369 @@ B 10 3 o
 		main()
 	}()
 
+	// Neg3
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("Case #3: Expected panic")
+			} else {
+				historyDepth = DefaultHistoryDepth
+			}
+		}()
+		historyDepth = -1
+		main()
+	}()
+
+	// Neg4
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("Case #4: Expected panic")
+			} else {
+				pngScale = DefaultPNGScale
+			}
+		}()
+		pngScale = 0
+		main()
+	}()
+
+	// Neg5
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("Case #5: Expected panic")
+			} else {
+				rendererName = DefaultRendererName
+			}
+		}()
+		rendererName = "unknown"
+		main()
+	}()
+
+	// Neg6
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("Case #6: Expected panic")
+			} else {
+				rendererName = DefaultRendererName
+				colorModeString = DefaultColorModeString
+			}
+		}()
+		rendererName = "ansi"
+		colorModeString = "8"
+		main()
+	}()
+
+	// Neg7
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("Case #7: Expected panic")
+			} else {
+				useRGBA = DefaultUseRGBA
+				bgColorString = DefaultBGColorString
+			}
+		}()
+		useRGBA = true
+		bgColorString = "notacolor"
+		main()
+	}()
+
+	// Neg8
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("Case #8: Expected panic")
+			} else {
+				svgScale = DefaultSVGScale
+			}
+		}()
+		svgScale = 0
+		main()
+	}()
+
+	// Pos
+	main()
+
+	// Pos (strict lines)
+	input = strings.NewReader(inputText)
+	strictLines = true
+	main()
+	strictLines = DefaultStrictLines
+
+	// Pos (bitmap text)
+	input = strings.NewReader(inputText)
+	bitmapText = true
+	main()
+	bitmapText = DefaultBitmapText
+
+	// Pos (RGBA colors)
+	input = strings.NewReader(inputText + "FG orange\nL 1 1 20 1 #0000ff\n")
+	useRGBA = true
+	main()
+	colorModeString = "256"
+	input = strings.NewReader(inputText)
+	main()
+	useRGBA = DefaultUseRGBA
+	colorModeString = DefaultColorModeString
+
+	// Pos (high-density renderers)
+	for _, name := range []string{"halfblock", "braille"} {
+		input = strings.NewReader(inputText)
+		rendererName = name
+		main()
+		input = strings.NewReader(inputText)
+		useRGBA = true
+		main()
+		useRGBA = DefaultUseRGBA
+	}
+	rendererName = DefaultRendererName
+}
+
+func TestMainScript(t *testing.T) {
+	dir, err := ioutil.TempDir("", "drawing-challenge")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeScript := func(name, text string) string {
+		path := filepath.Join(dir, name)
+		err := ioutil.WriteFile(path, []byte(text), 0644)
+		if err != nil {
+			panic(err)
+		}
+		return path
+	}
+	saved := filepath.Join(dir, "saved.png")
+	good := writeScript("good.txt", "C 20 4\nL 1 2 6 2\n\nR 14 1 18 3\nS "+saved+"\n")
+	bad := writeScript("bad.txt", "L 1 2 6 2\nC 20 4\nL 1 2 6 A\nL 1 2 30 2\nB 10 3 o\n")
+	quit := writeScript("quit.txt", "C 20 4\nQ\nL 1 2 6 A\n")
+	missing := filepath.Join(dir, "missing.txt")
+	savedSVG := filepath.Join(dir, "saved.svg")
+	vector := writeScript("vector.txt", "C 20 4\nL 1 2 6 2\nR 14 1 18 3\nS "+savedSVG+"\n")
+	dollar := writeScript("dollar.txt", "C 20 4\nB 1 1 $\nT 1 2 $x\n")
+	loop := writeScript("loop.txt", "C 20 4\nfor i = 1 to 3\n  L $i 1 $i 1\nend\nL $x 1 1 1\n")
+	syntax := writeScript("syntax.txt", "C 20 4\nfor i = 1 to 3\n")
+	savedPrefix := filepath.Join(dir, "prefix.png")
+	prefix := writeScript("prefix.txt", "C 20 4\nL 1 2 6 2\nS "+savedPrefix+"\nend\nS "+saved+"\n")
+	prefixQuit := writeScript("prefix_quit.txt", "C 20 4\nQ\nend\n")
+	prefixFail := writeScript("prefix_fail.txt", "L 1 2 6 2\nC 20 4\nend\n")
+
+	cases := []struct {
+		args      []string
+		keepGoing bool
+		exitCode  int
+		errOutput string
+	}{
+		{[]string{"-script", good}, false, 0, ""},
+		{[]string{good, good}, false, 0, ""},
+		{[]string{"-script", quit, bad}, false, 0, ""},
+		{[]string{"-script", bad, good}, false, 1, bad + ":1: " + common.ErrCanvasNotCreated.Error() + "\n"},
+		{[]string{"-keep-going", bad}, true, 1, bad + ":1: " + common.ErrCanvasNotCreated.Error() + "\n" +
+			bad + ":3:9: " + common.ErrInvalidNumber.Error() + "\n" +
+			bad + ":4: " + common.ErrPointOutsideCanvas.Error() + "\n"},
+		{[]string{missing, good}, false, 1, missing + ": "},
+		{[]string{"-keep-going", missing, good}, true, 1, missing + ": "},
+		{[]string{"-svgVector", vector}, false, 0, ""},
+		// The statements are executed only with -scripting
+		{[]string{dollar}, false, 0, ""},
+		{[]string{loop}, false, 1, loop + ":2:1: " + common.ErrUnknownCommand.Error() + "\n"},
+		{[]string{"-scripting", loop}, false, 1, loop + ":5:3: " + common.ErrUndefinedVariable.Error() + "\n"},
+		{[]string{"-scripting", "-keep-going", syntax}, true, 1, syntax + ":2:1: " + common.ErrBlockNotClosed.Error() + "\n"},
+		{[]string{"-scripting", prefix}, false, 1, prefix + ":4:1: " + common.ErrUnexpectedBlockEnd.Error() + "\n"},
+		{[]string{"-scripting", prefixQuit}, false, 0, ""},
+		{[]string{"-scripting", prefixFail}, false, 1, prefixFail + ":1: " + common.ErrCanvasNotCreated.Error() + "\n"},
+		{[]string{"-scripting", "-keep-going", prefixFail}, true, 1, prefixFail + ":1: " + common.ErrCanvasNotCreated.Error() + "\n" +
+			prefixFail + ":3:1: " + common.ErrUnexpectedBlockEnd.Error() + "\n"},
+	}
+	osArgs := os.Args
+	defer func() {
+		os.Args = osArgs
+		output = new(bytes.Buffer)
+		errOutput = os.Stderr
+		exit = os.Exit
+		scriptFile = DefaultScriptFile
+		keepGoing = DefaultKeepGoing
+		scripting = DefaultScripting
+		svgVector = DefaultSVGVector
+	}()
+	for _, c := range cases {
+		exitCode := 0
+		exit = func(code int) {
+			exitCode = code
+		}
+		stdout := new(bytes.Buffer)
+		stderr := new(bytes.Buffer)
+		output = stdout
+		errOutput = stderr
+		scriptFile = DefaultScriptFile
+		keepGoing = DefaultKeepGoing
+		scripting = DefaultScripting
+		svgVector = DefaultSVGVector
+		os.Args = append([]string{osArgs[0]}, c.args...)
+		main()
+		if exitCode != c.exitCode {
+			t.Errorf("Case: %v, Expected: %#v, Got: %#v", c.args, c.exitCode, exitCode)
+		}
+		if keepGoing != c.keepGoing {
+			t.Errorf("Case: %v, Expected: %#v, Got: %#v", c.args, c.keepGoing, keepGoing)
+		}
+		if !strings.HasPrefix(stderr.String(), c.errOutput) || (c.exitCode == 0) != (stderr.Len() == 0) {
+			t.Errorf("Case: %v, Expected: %#v, Got: %#v", c.args, c.errOutput, stderr.String())
+		}
+		if strings.Contains(stdout.String(), "enter command: ") {
+			t.Errorf("Case: %v, Expected: no prompt, Got: %#v", c.args, stdout.String())
+		}
+	}
+	if _, err := os.Stat(saved); err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	if _, err := os.Stat(savedPrefix); err != nil {
+		t.Errorf("Case: Prefix, Expected: err == nil, Got: %#v", err)
+	}
+	if data, err := ioutil.ReadFile(savedSVG); err != nil || !bytes.Contains(data, []byte("<line ")) {
+		t.Errorf("Expected: SVG with <line> elements, Got: %q, %#v", data, err)
+	}
+}
+
+func TestMainHTTP(t *testing.T) {
+	var handler http.Handler
+	errListen := errors.New("listen error")
+	defer func() {
+		httpAddr = DefaultHTTPAddr
+		listenAndServe = http.ListenAndServe
+		errOutput = os.Stderr
+		exit = os.Exit
+	}()
+	exitCode := 0
+	exit = func(code int) {
+		exitCode = code
+	}
+	stderr := new(bytes.Buffer)
+	errOutput = stderr
+	httpAddr = "localhost:0"
+
+	// Pos
+	listenAndServe = func(addr string, h http.Handler) error {
+		if addr != httpAddr {
+			t.Errorf("Expected: %q, Got: %q", httpAddr, addr)
+		}
+		handler = h
+		return nil
+	}
+	main()
+	if exitCode != 0 || handler == nil {
+		t.Errorf("Expected: exit code 0 and a handler, Got: %d, %#v", exitCode, handler)
+	}
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+	resp, err := http.Post(ts.URL+"/sessions", "", nil)
+	if err != nil {
+		panic(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected: %d, Got: %d", http.StatusCreated, resp.StatusCode)
+	}
+	resp, err = http.Post(ts.URL+resp.Header.Get("Location")+"/commands", "text/plain", strings.NewReader("C 4 2\nL 1 1 4 1\n"))
+	if err != nil {
+		panic(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected: %d, Got: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	// Neg
+	listenAndServe = func(addr string, h http.Handler) error {
+		return errListen
+	}
+	main()
+	if exitCode != 1 || stderr.String() != errListen.Error()+"\n" {
+		t.Errorf("Expected: exit code 1 and %q, Got: %d, %q", errListen.Error(), exitCode, stderr.String())
+	}
+}
+
+func TestMainCollab(t *testing.T) {
+	var handler http.Handler
+	errListen := errors.New("listen error")
+	defer func() {
+		collabAddr = DefaultCollabAddr
+		listenAndServe = http.ListenAndServe
+		errOutput = os.Stderr
+		exit = os.Exit
+	}()
+	exitCode := 0
+	exit = func(code int) {
+		exitCode = code
+	}
+	stderr := new(bytes.Buffer)
+	errOutput = stderr
+	collabAddr = "localhost:0"
+
 	// Pos
+	listenAndServe = func(addr string, h http.Handler) error {
+		if addr != collabAddr {
+			t.Errorf("Expected: %q, Got: %q", collabAddr, addr)
+		}
+		handler = h
+		return nil
+	}
+	main()
+	if exitCode != 0 || handler == nil {
+		t.Errorf("Expected: exit code 0 and a handler, Got: %d, %#v", exitCode, handler)
+	}
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+	resp, err := http.Get(ts.URL + "/")
+	if err != nil {
+		panic(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Errorf("Expected: %d, text/html, Got: %d, %q", http.StatusOK, resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	conn, err := websocket.Dial("ws" + strings.TrimPrefix(ts.URL, "http") + "/ws")
+	if err != nil {
+		t.Fatalf("Expected: err == nil, Got: %#v", err)
+	}
+	defer conn.Close()
+	conn.WriteMessage("C 2 1")
+	msg, err := conn.ReadMessage()
+	if err != nil || !strings.HasPrefix(msg, `{"type":"canvas"`) {
+		t.Errorf("Expected: a canvas message, Got: %q, %#v", msg, err)
+	}
+
+	// Neg
+	listenAndServe = func(addr string, h http.Handler) error {
+		return errListen
+	}
+	main()
+	if exitCode != 1 || stderr.String() != errListen.Error()+"\n" {
+		t.Errorf("Expected: exit code 1 and %q, Got: %d, %q", errListen.Error(), exitCode, stderr.String())
+	}
+}
+
+func TestMainCaret(t *testing.T) {
+	stdout := new(bytes.Buffer)
+	defer func() {
+		input = os.Stdin
+		output = os.Stdout
+	}()
+	input = strings.NewReader("C a 2\nX\nT 1 1 日本 \"x\"\n")
+	output = stdout
 	main()
+
+	expecteds := []string{
+		Prompt + strings.Repeat(" ", len(Prompt)+2) + "^\n" + common.ErrInvalidNumber.Error() + "\n",
+		Prompt + strings.Repeat(" ", len(Prompt)) + "^\n" + common.ErrUnknownCommand.Error() + "\n",
+		Prompt + strings.Repeat(" ", len(Prompt)+9) + "^\n" + common.ErrInvalidString.Error() + "\n",
+	}
+	for _, expected := range expecteds {
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("Expected: %q in the output, Got: %q", expected, stdout.String())
+		}
+	}
 }
@@ -0,0 +1,163 @@
+package ansi
+
+import (
+	"bytes"
+	imagecolor "image/color"
+	"io"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/color"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/common"
+	"github.com/asukakenji/drawing-challenge/renderer"
+)
+
+const (
+	// brailleBase is the braille pattern with no dots raised.
+	brailleBase = '⠀'
+
+	// brailleWidth is the number of pixels packed horizontally
+	// into a braille character.
+	brailleWidth = 2
+
+	// brailleHeight is the number of pixels packed vertically
+	// into a braille character.
+	brailleHeight = 4
+)
+
+// brailleDots contains the bits of the dots of the braille patterns,
+// indexed by [y][x] inside a character.
+var brailleDots = [brailleHeight][brailleWidth]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// BrailleRenderer is a renderer drawing each (2 x 4) block of pixels
+// as a braille character colored with ANSI escape codes.
+// A dot is raised for each pixel not in the background color.
+// It implements the renderer.Renderer interface.
+type BrailleRenderer struct {
+	writer     io.Writer
+	mode       ColorMode
+	palette    bytecolor.Palette
+	background imagecolor.RGBA
+}
+
+// Ensure that BrailleRenderer implements the renderer.Renderer interface.
+var (
+	_ renderer.Renderer = &BrailleRenderer{}
+)
+
+// NewBrailleRenderer returns a new BrailleRenderer.
+// The escape codes are chosen according to mode.
+// The byte colors are mapped to RGBA colors by palette.
+// The pixels in the background color are not drawn.
+//
+// Errors
+//
+// common.ErrNilPointer:
+// Will be returned if writer == nil or palette == nil.
+//
+// common.ErrColorModeNotSupported:
+// Will be returned if mode is not supported by this renderer.
+//
+// common.ErrColorNotSupported:
+// Will be returned if background is not supported by this renderer.
+//
+func NewBrailleRenderer(writer io.Writer, mode ColorMode, palette bytecolor.Palette, background color.Color) (*BrailleRenderer, error) {
+	if writer == nil {
+		return nil, common.ErrNilPointer
+	}
+	if palette == nil {
+		return nil, common.ErrNilPointer
+	}
+	switch mode {
+	case ColorMode16, ColorMode256, ColorModeTrueColor:
+		// OK
+	default:
+		return nil, common.ErrColorModeNotSupported
+	}
+	bg, err := palette.ToRGBA(background)
+	if err != nil {
+		return nil, err
+	}
+	return &BrailleRenderer{
+		writer:     writer,
+		mode:       mode,
+		palette:    palette,
+		background: bg,
+	}, nil
+}
+
+// Render renders cnv.
+//
+// cnv must implement the canvas.BufferBasedCanvas interface.
+// The pixels of cnv must be bytecolor.Color in the palette,
+// or implement the image/color.Color interface.
+// Fully transparent pixels are not drawn.
+// Since a braille character has only one color,
+// it is drawn with the most frequent color of its raised dots.
+// No borders are drawn, so that a canvas (w x h) fits in
+// ceil(w / 2) columns and ceil(h / 4) rows.
+//
+// Errors
+//
+// common.ErrCanvasNotSupported:
+// Will be returned if cnv is not supported by this renderer.
+//
+// common.ErrColorNotSupported:
+// Will be returned if a color inside cnv is not supported by this renderer.
+//
+// Errors returned from the writer are returned without modifications.
+//
+func (rdr *BrailleRenderer) Render(cnv canvas.Canvas) error {
+	bbcnv, ok := cnv.(canvas.BufferBasedCanvas)
+	if !ok {
+		return common.ErrCanvasNotSupported
+	}
+	width, height := bbcnv.Dimensions()
+	// NOTE: The output is buffered, so that nothing is written on errors
+	buf := new(bytes.Buffer)
+	for j := 0; j < height; j += brailleHeight {
+		state := colorState{}
+		for i := 0; i < width; i += brailleWidth {
+			pattern := brailleBase
+			counts := map[imagecolor.RGBA]int{}
+			var fgColor imagecolor.RGBA
+			for y := j; y < j+brailleHeight && y < height; y++ {
+				for x := i; x < i+brailleWidth && x < width; x++ {
+					c, err := bbcnv.At(x, y)
+					if err != nil {
+						// NOTE: This should not happen if the canvas is correctly implemented
+						panic(err)
+					}
+					rgba, err := rdr.palette.ToRGBA(c)
+					if err != nil {
+						return err
+					}
+					if rgba.A == 0 || rgba == rdr.background {
+						continue
+					}
+					pattern |= brailleDots[y-j][x-i]
+					counts[rgba]++
+					// NOTE: The color reaching the highest count first wins on ties
+					if counts[rgba] > counts[fgColor] {
+						fgColor = rgba
+					}
+				}
+			}
+			if pattern != brailleBase {
+				// NOTE: The color is kept if no dots are raised
+				state.update(buf, colorParams(rdr.mode, fgColor, false), "")
+			}
+			buf.WriteRune(pattern)
+		}
+		state.reset(buf)
+		buf.WriteString("\n")
+	}
+	buf.WriteString("\n")
+	_, err := rdr.writer.Write(buf.Bytes())
+	return err
+}
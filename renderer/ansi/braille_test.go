@@ -0,0 +1,129 @@
+package ansi
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	bc "github.com/asukakenji/drawing-challenge/canvas/bytecolor"
+	"github.com/asukakenji/drawing-challenge/color"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+func TestNewBrailleRenderer(t *testing.T) {
+	_, err := NewBrailleRenderer(new(bytes.Buffer), ColorModeTrueColor, bytecolor.DefaultPalette(), bytecolor.Color(' '))
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+
+	casesNeg := []struct {
+		writer     io.Writer
+		mode       ColorMode
+		palette    bytecolor.Palette
+		background color.Color
+		err        error
+	}{
+		{nil, ColorMode16, bytecolor.DefaultPalette(), bytecolor.Color(' '), common.ErrNilPointer},
+		{new(bytes.Buffer), ColorMode16, nil, bytecolor.Color(' '), common.ErrNilPointer},
+		{new(bytes.Buffer), ColorMode(-1), bytecolor.DefaultPalette(), bytecolor.Color(' '), common.ErrColorModeNotSupported},
+		{new(bytes.Buffer), ColorMode(3), bytecolor.DefaultPalette(), bytecolor.Color(' '), common.ErrColorModeNotSupported},
+		{new(bytes.Buffer), ColorMode16, bytecolor.Palette{}, bytecolor.Color(' '), common.ErrColorNotSupported},
+		{new(bytes.Buffer), ColorMode16, bytecolor.DefaultPalette(), nil, common.ErrColorNotSupported},
+	}
+	for _, c := range casesNeg {
+		_, err := NewBrailleRenderer(c.writer, c.mode, c.palette, c.background)
+		if err != c.err {
+			t.Errorf("Case: (%#v, %#v, %#v), Expected: %#v, Got: %#v", c.mode, c.palette == nil, c.background, c.err, err)
+		}
+	}
+}
+
+func TestBrailleRenderer_Render(t *testing.T) {
+	cnv, err := bc.NewBuffer(4, 5, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.DrawLine(0, 0, 3, 0)
+	if err != nil {
+		panic(err)
+	}
+	for _, p := range [][2]int{{1, 1}, {3, 3}, {0, 4}} {
+		err = cnv.Set(p[0], p[1], bytecolor.Color('R'))
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	cases := []struct {
+		mode     ColorMode
+		expected string
+	}{
+		{
+			ColorModeTrueColor,
+			"\x1b[38;2;0;0;0m⠙⢉\x1b[0m\n" +
+				"\x1b[38;2;255;0;0m⠁⠀\x1b[0m\n\n",
+		},
+		{
+			ColorMode16,
+			"\x1b[30m⠙⢉\x1b[0m\n" +
+				"\x1b[91m⠁⠀\x1b[0m\n\n",
+		},
+	}
+	for _, c := range cases {
+		writer := new(bytes.Buffer)
+		renderer, err := NewBrailleRenderer(writer, c.mode, bytecolor.DefaultPalette(), bytecolor.Color(' '))
+		if err != nil {
+			panic(err)
+		}
+		err = renderer.Render(cnv)
+		if err != nil {
+			t.Errorf("Case: %#v, Expected: err == nil, Got: %#v", c.mode, err)
+		}
+		if writer.String() != c.expected {
+			t.Errorf("Case: %#v, Expected: %q, Got: %q", c.mode, c.expected, writer.String())
+		}
+	}
+}
+
+func TestBrailleRenderer_Render_Neg(t *testing.T) {
+	cnv, err := bc.NewBuffer(3, 2, bytecolor.Color(' '), bytecolor.Color('%'))
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.DrawLine(0, 0, 2, 0)
+	if err != nil {
+		panic(err)
+	}
+
+	writer := new(bytes.Buffer)
+	renderer, err := NewBrailleRenderer(writer, ColorMode16, bytecolor.DefaultPalette(), bytecolor.Color(' '))
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(nil)
+	if err != common.ErrCanvasNotSupported {
+		t.Errorf("Expected: err == %#v, Got: %#v", common.ErrCanvasNotSupported, err)
+	}
+
+	renderer, err = NewBrailleRenderer(writer, ColorMode16, bytecolor.Palette{' ': bytecolor.DefaultPalette()[' ']}, bytecolor.Color(' '))
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != common.ErrColorNotSupported {
+		t.Errorf("Expected: err == %#v, Got: %#v", common.ErrColorNotSupported, err)
+	}
+	if writer.Len() != 0 {
+		t.Errorf("Expected: nothing written, Got: %q", writer.String())
+	}
+
+	renderer, err = NewBrailleRenderer(errWriter{}, ColorMode16, bytecolor.DefaultPalette(), bytecolor.Color(' '))
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != errWrite {
+		t.Errorf("Expected: err == %#v, Got: %#v", errWrite, err)
+	}
+}
@@ -0,0 +1,146 @@
+package ansi
+
+import (
+	"bytes"
+	imagecolor "image/color"
+	"io"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/common"
+	"github.com/asukakenji/drawing-challenge/renderer"
+)
+
+const (
+	// upperHalfBlock is the character used to draw the upper pixel,
+	// or both pixels of a pair.
+	upperHalfBlock = "▀"
+
+	// lowerHalfBlock is the character used to draw the lower pixel
+	// when the upper pixel is fully transparent.
+	lowerHalfBlock = "▄"
+)
+
+// HalfBlockRenderer is a renderer drawing each pair of vertically adjacent
+// pixels as a half block character colored with ANSI escape codes.
+// The upper pixel is drawn with the foreground color,
+// and the lower pixel is drawn with the background color.
+// It implements the renderer.Renderer interface.
+type HalfBlockRenderer struct {
+	writer  io.Writer
+	mode    ColorMode
+	palette bytecolor.Palette
+}
+
+// Ensure that HalfBlockRenderer implements the renderer.Renderer interface.
+var (
+	_ renderer.Renderer = &HalfBlockRenderer{}
+)
+
+// NewHalfBlockRenderer returns a new HalfBlockRenderer.
+// The escape codes are chosen according to mode.
+// The byte colors are mapped to RGBA colors by palette.
+//
+// Errors
+//
+// common.ErrNilPointer:
+// Will be returned if writer == nil or palette == nil.
+//
+// common.ErrColorModeNotSupported:
+// Will be returned if mode is not supported by this renderer.
+//
+func NewHalfBlockRenderer(writer io.Writer, mode ColorMode, palette bytecolor.Palette) (*HalfBlockRenderer, error) {
+	if writer == nil {
+		return nil, common.ErrNilPointer
+	}
+	if palette == nil {
+		return nil, common.ErrNilPointer
+	}
+	switch mode {
+	case ColorMode16, ColorMode256, ColorModeTrueColor:
+		// OK
+	default:
+		return nil, common.ErrColorModeNotSupported
+	}
+	return &HalfBlockRenderer{
+		writer:  writer,
+		mode:    mode,
+		palette: palette,
+	}, nil
+}
+
+// Render renders cnv.
+//
+// cnv must implement the canvas.BufferBasedCanvas interface.
+// The pixels of cnv must be bytecolor.Color in the palette,
+// or implement the image/color.Color interface.
+// Fully transparent pixels are not drawn.
+// If the height of cnv is odd, the lower pixels of the last row are
+// treated as fully transparent.
+// No borders are drawn, so that a canvas (w x h) fits in
+// w columns and ceil(h / 2) rows.
+//
+// Errors
+//
+// common.ErrCanvasNotSupported:
+// Will be returned if cnv is not supported by this renderer.
+//
+// common.ErrColorNotSupported:
+// Will be returned if a color inside cnv is not supported by this renderer.
+//
+// Errors returned from the writer are returned without modifications.
+//
+func (rdr *HalfBlockRenderer) Render(cnv canvas.Canvas) error {
+	bbcnv, ok := cnv.(canvas.BufferBasedCanvas)
+	if !ok {
+		return common.ErrCanvasNotSupported
+	}
+	width, height := bbcnv.Dimensions()
+	// NOTE: The output is buffered, so that nothing is written on errors
+	buf := new(bytes.Buffer)
+	for j := 0; j < height; j += 2 {
+		state := colorState{}
+		for i := 0; i < width; i++ {
+			upper, err := rdr.rgbaAt(bbcnv, i, j)
+			if err != nil {
+				return err
+			}
+			lower := imagecolor.RGBA{}
+			if j+1 < height {
+				lower, err = rdr.rgbaAt(bbcnv, i, j+1)
+				if err != nil {
+					return err
+				}
+			}
+			fg := colorParams(rdr.mode, upper, false)
+			bg := colorParams(rdr.mode, lower, true)
+			switch {
+			case fg == "" && bg == "":
+				state.reset(buf)
+				buf.WriteString(" ")
+			case fg == "":
+				state.update(buf, colorParams(rdr.mode, lower, false), "")
+				buf.WriteString(lowerHalfBlock)
+			default:
+				state.update(buf, fg, bg)
+				buf.WriteString(upperHalfBlock)
+			}
+		}
+		state.reset(buf)
+		buf.WriteString("\n")
+	}
+	buf.WriteString("\n")
+	_, err := rdr.writer.Write(buf.Bytes())
+	return err
+}
+
+// rgbaAt returns the color of the pixel at (x, y) of cnv,
+// mapped to an RGBA color by the palette.
+func (rdr *HalfBlockRenderer) rgbaAt(cnv canvas.BufferBasedCanvas, x, y int) (imagecolor.RGBA, error) {
+	c, err := cnv.At(x, y)
+	if err != nil {
+		// NOTE: This should not happen if the canvas is correctly implemented
+		panic(err)
+	}
+	return rdr.palette.ToRGBA(c)
+}
@@ -0,0 +1,157 @@
+package ansi
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	bc "github.com/asukakenji/drawing-challenge/canvas/bytecolor"
+	rc "github.com/asukakenji/drawing-challenge/canvas/rgba"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/color/rgba"
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+func TestNewHalfBlockRenderer(t *testing.T) {
+	_, err := NewHalfBlockRenderer(new(bytes.Buffer), ColorModeTrueColor, bytecolor.DefaultPalette())
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+
+	casesNeg := []struct {
+		writer  io.Writer
+		mode    ColorMode
+		palette bytecolor.Palette
+		err     error
+	}{
+		{nil, ColorMode16, bytecolor.DefaultPalette(), common.ErrNilPointer},
+		{new(bytes.Buffer), ColorMode16, nil, common.ErrNilPointer},
+		{new(bytes.Buffer), ColorMode(-1), bytecolor.DefaultPalette(), common.ErrColorModeNotSupported},
+		{new(bytes.Buffer), ColorMode(3), bytecolor.DefaultPalette(), common.ErrColorModeNotSupported},
+	}
+	for _, c := range casesNeg {
+		_, err := NewHalfBlockRenderer(c.writer, c.mode, c.palette)
+		if err != c.err {
+			t.Errorf("Case: (%#v, %#v), Expected: %#v, Got: %#v", c.mode, c.palette == nil, c.err, err)
+		}
+	}
+}
+
+func TestHalfBlockRenderer_Render(t *testing.T) {
+	cnv, err := bc.NewBuffer(3, 3, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.DrawLine(0, 1, 2, 1)
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.Set(1, 0, bytecolor.Color('R'))
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.Set(0, 2, bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+
+	cases := []struct {
+		mode     ColorMode
+		expected string
+	}{
+		{
+			ColorModeTrueColor,
+			"\x1b[38;2;255;255;255;48;2;0;0;0m▀\x1b[38;2;255;0;0m▀\x1b[38;2;255;255;255m▀\x1b[0m\n" +
+				"\x1b[38;2;0;0;0m▀\x1b[38;2;255;255;255m▀▀\x1b[0m\n\n",
+		},
+		{
+			ColorMode256,
+			"\x1b[38;5;231;48;5;16m▀\x1b[38;5;196m▀\x1b[38;5;231m▀\x1b[0m\n" +
+				"\x1b[38;5;16m▀\x1b[38;5;231m▀▀\x1b[0m\n\n",
+		},
+		{
+			ColorMode16,
+			"\x1b[97;40m▀\x1b[91m▀\x1b[97m▀\x1b[0m\n" +
+				"\x1b[30m▀\x1b[97m▀▀\x1b[0m\n\n",
+		},
+	}
+	for _, c := range cases {
+		writer := new(bytes.Buffer)
+		renderer, err := NewHalfBlockRenderer(writer, c.mode, bytecolor.DefaultPalette())
+		if err != nil {
+			panic(err)
+		}
+		err = renderer.Render(cnv)
+		if err != nil {
+			t.Errorf("Case: %#v, Expected: err == nil, Got: %#v", c.mode, err)
+		}
+		if writer.String() != c.expected {
+			t.Errorf("Case: %#v, Expected: %q, Got: %q", c.mode, c.expected, writer.String())
+		}
+	}
+}
+
+func TestHalfBlockRenderer_Render_RGBA(t *testing.T) {
+	transparent := rgba.Color{R: 0xff, G: 0xff, B: 0xff, A: 0x00}
+	orange := rgba.Color{R: 0xff, G: 0x80, B: 0x00, A: 0xff}
+	cnv, err := rc.NewBuffer(2, 2, transparent, orange)
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.DrawLine(0, 1, 0, 1)
+	if err != nil {
+		panic(err)
+	}
+
+	writer := new(bytes.Buffer)
+	renderer, err := NewHalfBlockRenderer(writer, ColorModeTrueColor, bytecolor.Palette{})
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	expected := "\x1b[38;2;255;128;0m▄\x1b[0m \n\n"
+	if writer.String() != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, writer.String())
+	}
+}
+
+func TestHalfBlockRenderer_Render_Neg(t *testing.T) {
+	cnv, err := bc.NewBuffer(3, 2, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+
+	writer := new(bytes.Buffer)
+	renderer, err := NewHalfBlockRenderer(writer, ColorMode16, bytecolor.DefaultPalette())
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(nil)
+	if err != common.ErrCanvasNotSupported {
+		t.Errorf("Expected: err == %#v, Got: %#v", common.ErrCanvasNotSupported, err)
+	}
+
+	renderer, err = NewHalfBlockRenderer(writer, ColorMode16, bytecolor.Palette{})
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != common.ErrColorNotSupported {
+		t.Errorf("Expected: err == %#v, Got: %#v", common.ErrColorNotSupported, err)
+	}
+	if writer.Len() != 0 {
+		t.Errorf("Expected: nothing written, Got: %q", writer.String())
+	}
+
+	renderer, err = NewHalfBlockRenderer(errWriter{}, ColorMode16, bytecolor.DefaultPalette())
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != errWrite {
+		t.Errorf("Expected: err == %#v, Got: %#v", errWrite, err)
+	}
+}
@@ -0,0 +1,161 @@
+package ansi
+
+import (
+	"bytes"
+	"fmt"
+	imagecolor "image/color"
+	"strings"
+)
+
+// colors16 contains the 16 standard colors, as defined by xterm.
+var colors16 = [16]imagecolor.NRGBA{
+	{0x00, 0x00, 0x00, 0xff}, // Black
+	{0xcd, 0x00, 0x00, 0xff}, // Red
+	{0x00, 0xcd, 0x00, 0xff}, // Green
+	{0xcd, 0xcd, 0x00, 0xff}, // Yellow
+	{0x00, 0x00, 0xee, 0xff}, // Blue
+	{0xcd, 0x00, 0xcd, 0xff}, // Magenta
+	{0x00, 0xcd, 0xcd, 0xff}, // Cyan
+	{0xe5, 0xe5, 0xe5, 0xff}, // White
+	{0x7f, 0x7f, 0x7f, 0xff}, // Bright Black
+	{0xff, 0x00, 0x00, 0xff}, // Bright Red
+	{0x00, 0xff, 0x00, 0xff}, // Bright Green
+	{0xff, 0xff, 0x00, 0xff}, // Bright Yellow
+	{0x5c, 0x5c, 0xff, 0xff}, // Bright Blue
+	{0xff, 0x00, 0xff, 0xff}, // Bright Magenta
+	{0x00, 0xff, 0xff, 0xff}, // Bright Cyan
+	{0xff, 0xff, 0xff, 0xff}, // Bright White
+}
+
+// cubeLevels contains the levels of each component
+// in the 6x6x6 color cube of the 256 colors.
+var cubeLevels = [6]int{0x00, 0x5f, 0x87, 0xaf, 0xd7, 0xff}
+
+// distance returns the squared Euclidean distance between
+// (r1, g1, b1) and (r2, g2, b2).
+func distance(r1, g1, b1, r2, g2, b2 int) int {
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+	return dr*dr + dg*dg + db*db
+}
+
+// toColor16 returns the index of the standard color nearest to c.
+func toColor16(c imagecolor.NRGBA) int {
+	best, bestDistance := 0, -1
+	for i, c16 := range colors16 {
+		d := distance(int(c.R), int(c.G), int(c.B), int(c16.R), int(c16.G), int(c16.B))
+		if bestDistance < 0 || d < bestDistance {
+			best, bestDistance = i, d
+		}
+	}
+	return best
+}
+
+// nearestCubeLevel returns the index of the cube level nearest to v.
+func nearestCubeLevel(v int) int {
+	best := 0
+	for i, level := range cubeLevels {
+		if abs(level-v) < abs(cubeLevels[best]-v) {
+			best = i
+		}
+	}
+	return best
+}
+
+// toColor256 returns the index of the color nearest to c
+// in the color cube (16-231) or the grayscale ramp (232-255).
+// The standard colors (0-15) are not used,
+// since they are usually customized by the terminal.
+func toColor256(c imagecolor.NRGBA) int {
+	r, g, b := int(c.R), int(c.G), int(c.B)
+
+	// The nearest color in the color cube
+	ri, gi, bi := nearestCubeLevel(r), nearestCubeLevel(g), nearestCubeLevel(b)
+	cubeIndex := 16 + 36*ri + 6*gi + bi
+	cubeDistance := distance(r, g, b, cubeLevels[ri], cubeLevels[gi], cubeLevels[bi])
+
+	// The nearest color in the grayscale ramp, whose levels are 8, 18, ..., 238
+	gray := (r + g + b) / 3
+	gi = (gray - 8 + 5) / 10
+	if gi < 0 {
+		gi = 0
+	} else if gi > 23 {
+		gi = 23
+	}
+	level := 8 + 10*gi
+	grayDistance := distance(r, g, b, level, level, level)
+
+	if grayDistance < cubeDistance {
+		return 232 + gi
+	}
+	return cubeIndex
+}
+
+// colorParams returns the parameters of the SGR escape code
+// which sets the foreground (or background) color to c in mode.
+// It returns an empty string if c is fully transparent.
+func colorParams(mode ColorMode, c imagecolor.RGBA, background bool) string {
+	if c.A == 0 {
+		return ""
+	}
+	nrgba := imagecolor.NRGBAModel.Convert(c).(imagecolor.NRGBA)
+	base := 30
+	if background {
+		base = 40
+	}
+	switch mode {
+	case ColorModeTrueColor:
+		return fmt.Sprintf("%d;2;%d;%d;%d", base+8, nrgba.R, nrgba.G, nrgba.B)
+	case ColorMode256:
+		return fmt.Sprintf("%d;5;%d", base+8, toColor256(nrgba))
+	default:
+		index := toColor16(nrgba)
+		if index < 8 {
+			return fmt.Sprintf("%d", base+index)
+		}
+		return fmt.Sprintf("%d", base+60+index-8)
+	}
+}
+
+// colorState keeps track of the colors set by the escape codes written,
+// so that an escape code is written only when the colors change.
+type colorState struct {
+	fg string
+	bg string
+}
+
+// update writes the escape code which changes the foreground and background
+// colors to the ones specified by fg and bg, which are returned by colorParams.
+// Nothing is written if the colors are not changed.
+func (state *colorState) update(buf *bytes.Buffer, fg, bg string) {
+	if fg == state.fg && bg == state.bg {
+		return
+	}
+	var params []string
+	if (fg == "" && state.fg != "") || (bg == "" && state.bg != "") {
+		// Reset the colors and set the remaining ones again
+		params = append(params, "0")
+		state.fg, state.bg = "", ""
+	}
+	if fg != state.fg {
+		params = append(params, fg)
+	}
+	if bg != state.bg {
+		params = append(params, bg)
+	}
+	fmt.Fprintf(buf, "\x1b[%sm", strings.Join(params, ";"))
+	state.fg, state.bg = fg, bg
+}
+
+// reset writes the escape code which resets the colors,
+// if they are not already reset.
+func (state *colorState) reset(buf *bytes.Buffer) {
+	state.update(buf, "", "")
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
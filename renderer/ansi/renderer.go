@@ -0,0 +1,173 @@
+// Package ansi defines the Renderer type, the HalfBlockRenderer type,
+// and the BrailleRenderer type,
+// which implement the renderer.Renderer interface,
+// and the ColorMode type,
+// which represents the colors supported by a terminal.
+package ansi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/common"
+	"github.com/asukakenji/drawing-challenge/renderer"
+)
+
+// ColorMode represents the colors supported by a terminal.
+type ColorMode int
+
+const (
+	// ColorMode16 represents the 16 standard colors.
+	ColorMode16 ColorMode = iota
+
+	// ColorMode256 represents the 256 colors of xterm.
+	ColorMode256
+
+	// ColorModeTrueColor represents the 24-bit colors.
+	ColorModeTrueColor
+)
+
+// ParseColorMode parses s, which is "16", "256", or "truecolor",
+// and returns a ColorMode.
+//
+// Errors
+//
+// common.ErrColorModeNotSupported:
+// Will be returned if s is not in the above values.
+//
+func ParseColorMode(s string) (ColorMode, error) {
+	switch s {
+	case "16":
+		return ColorMode16, nil
+	case "256":
+		return ColorMode256, nil
+	case "truecolor":
+		return ColorModeTrueColor, nil
+	default:
+		return ColorMode16, common.ErrColorModeNotSupported
+	}
+}
+
+// DetectColorMode returns the color mode supported by the terminal,
+// according to the values of the COLORTERM and TERM environment variables.
+func DetectColorMode(colorTerm, term string) ColorMode {
+	switch {
+	case colorTerm == "truecolor" || colorTerm == "24bit":
+		return ColorModeTrueColor
+	case strings.Contains(term, "256color"):
+		return ColorMode256
+	default:
+		return ColorMode16
+	}
+}
+
+const (
+	// block is the character used to draw a pixel.
+	block = "█"
+)
+
+// Renderer is a renderer drawing each pixel as a full block character
+// colored with ANSI escape codes.
+// It implements the renderer.Renderer interface.
+type Renderer struct {
+	writer  io.Writer
+	mode    ColorMode
+	palette bytecolor.Palette
+}
+
+// Ensure that Renderer implements the renderer.Renderer interface.
+var (
+	_ renderer.Renderer = &Renderer{}
+)
+
+// NewRenderer returns a new Renderer.
+// The escape codes are chosen according to mode.
+// The byte colors are mapped to RGBA colors by palette.
+//
+// Errors
+//
+// common.ErrNilPointer:
+// Will be returned if writer == nil or palette == nil.
+//
+// common.ErrColorModeNotSupported:
+// Will be returned if mode is not supported by this renderer.
+//
+func NewRenderer(writer io.Writer, mode ColorMode, palette bytecolor.Palette) (*Renderer, error) {
+	if writer == nil {
+		return nil, common.ErrNilPointer
+	}
+	if palette == nil {
+		return nil, common.ErrNilPointer
+	}
+	switch mode {
+	case ColorMode16, ColorMode256, ColorModeTrueColor:
+		// OK
+	default:
+		return nil, common.ErrColorModeNotSupported
+	}
+	return &Renderer{
+		writer:  writer,
+		mode:    mode,
+		palette: palette,
+	}, nil
+}
+
+// Render renders cnv.
+//
+// cnv must implement the canvas.BufferBasedCanvas interface.
+// The pixels of cnv must be bytecolor.Color in the palette,
+// or implement the image/color.Color interface.
+// Fully transparent pixels are drawn as spaces.
+//
+// Errors
+//
+// common.ErrCanvasNotSupported:
+// Will be returned if cnv is not supported by this renderer.
+//
+// common.ErrColorNotSupported:
+// Will be returned if a color inside cnv is not supported by this renderer.
+//
+// Errors returned from the writer are returned without modifications.
+//
+func (rdr *Renderer) Render(cnv canvas.Canvas) error {
+	bbcnv, ok := cnv.(canvas.BufferBasedCanvas)
+	if !ok {
+		return common.ErrCanvasNotSupported
+	}
+	width, height := bbcnv.Dimensions()
+	border := strings.Repeat("─", width)
+	// NOTE: The output is buffered, so that nothing is written on errors
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "┌%s┐\n", border)
+	for j := 0; j < height; j++ {
+		buf.WriteString("│")
+		state := colorState{}
+		for i := 0; i < width; i++ {
+			c, err := bbcnv.At(i, j)
+			if err != nil {
+				// NOTE: This should not happen if the canvas is correctly implemented
+				panic(err)
+			}
+			rgba, err := rdr.palette.ToRGBA(c)
+			if err != nil {
+				return err
+			}
+			fg := colorParams(rdr.mode, rgba, false)
+			state.update(buf, fg, "")
+			if fg == "" {
+				buf.WriteString(" ")
+			} else {
+				buf.WriteString(block)
+			}
+		}
+		state.reset(buf)
+		buf.WriteString("│\n")
+	}
+	fmt.Fprintf(buf, "└%s┘\n\n", border)
+	_, err := rdr.writer.Write(buf.Bytes())
+	return err
+}
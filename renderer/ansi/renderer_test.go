@@ -0,0 +1,237 @@
+package ansi
+
+import (
+	"bytes"
+	"errors"
+	imagecolor "image/color"
+	"io"
+	"testing"
+
+	bc "github.com/asukakenji/drawing-challenge/canvas/bytecolor"
+	rc "github.com/asukakenji/drawing-challenge/canvas/rgba"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/color/rgba"
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+func TestParseColorMode(t *testing.T) {
+	casesPos := []struct {
+		s    string
+		mode ColorMode
+	}{
+		{"16", ColorMode16},
+		{"256", ColorMode256},
+		{"truecolor", ColorModeTrueColor},
+	}
+	for _, c := range casesPos {
+		mode, err := ParseColorMode(c.s)
+		if err != nil {
+			t.Errorf("Case: %s, Expected: err == nil, Got: %#v", c.s, err)
+		}
+		if mode != c.mode {
+			t.Errorf("Case: %s, Expected: %#v, Got: %#v", c.s, c.mode, mode)
+		}
+	}
+
+	for _, s := range []string{"", "8", "24bit"} {
+		_, err := ParseColorMode(s)
+		if err != common.ErrColorModeNotSupported {
+			t.Errorf("Case: %s, Expected: %#v, Got: %#v", s, common.ErrColorModeNotSupported, err)
+		}
+	}
+}
+
+func TestDetectColorMode(t *testing.T) {
+	cases := []struct {
+		colorTerm string
+		term      string
+		mode      ColorMode
+	}{
+		{"truecolor", "xterm-256color", ColorModeTrueColor},
+		{"24bit", "xterm", ColorModeTrueColor},
+		{"", "xterm-256color", ColorMode256},
+		{"", "xterm", ColorMode16},
+		{"", "", ColorMode16},
+	}
+	for _, c := range cases {
+		mode := DetectColorMode(c.colorTerm, c.term)
+		if mode != c.mode {
+			t.Errorf("Case: (%q, %q), Expected: %#v, Got: %#v", c.colorTerm, c.term, c.mode, mode)
+		}
+	}
+}
+
+func TestNewRenderer(t *testing.T) {
+	_, err := NewRenderer(new(bytes.Buffer), ColorModeTrueColor, bytecolor.DefaultPalette())
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+
+	casesNeg := []struct {
+		writer  io.Writer
+		mode    ColorMode
+		palette bytecolor.Palette
+		err     error
+	}{
+		{nil, ColorMode16, bytecolor.DefaultPalette(), common.ErrNilPointer},
+		{new(bytes.Buffer), ColorMode16, nil, common.ErrNilPointer},
+		{new(bytes.Buffer), ColorMode(-1), bytecolor.DefaultPalette(), common.ErrColorModeNotSupported},
+		{new(bytes.Buffer), ColorMode(3), bytecolor.DefaultPalette(), common.ErrColorModeNotSupported},
+	}
+	for _, c := range casesNeg {
+		_, err := NewRenderer(c.writer, c.mode, c.palette)
+		if err != c.err {
+			t.Errorf("Case: (%#v, %#v), Expected: %#v, Got: %#v", c.mode, c.palette == nil, c.err, err)
+		}
+	}
+}
+
+func TestRenderer_Render(t *testing.T) {
+	cnv, err := bc.NewBuffer(3, 2, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.DrawLine(0, 1, 2, 1)
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.Set(1, 0, bytecolor.Color('R'))
+	if err != nil {
+		panic(err)
+	}
+
+	cases := []struct {
+		mode     ColorMode
+		expected string
+	}{
+		{
+			ColorModeTrueColor,
+			"┌───┐\n" +
+				"│\x1b[38;2;255;255;255m█\x1b[38;2;255;0;0m█\x1b[38;2;255;255;255m█\x1b[0m│\n" +
+				"│\x1b[38;2;0;0;0m███\x1b[0m│\n" +
+				"└───┘\n\n",
+		},
+		{
+			ColorMode256,
+			"┌───┐\n" +
+				"│\x1b[38;5;231m█\x1b[38;5;196m█\x1b[38;5;231m█\x1b[0m│\n" +
+				"│\x1b[38;5;16m███\x1b[0m│\n" +
+				"└───┘\n\n",
+		},
+		{
+			ColorMode16,
+			"┌───┐\n" +
+				"│\x1b[97m█\x1b[91m█\x1b[97m█\x1b[0m│\n" +
+				"│\x1b[30m███\x1b[0m│\n" +
+				"└───┘\n\n",
+		},
+	}
+	for _, c := range cases {
+		writer := new(bytes.Buffer)
+		renderer, err := NewRenderer(writer, c.mode, bytecolor.DefaultPalette())
+		if err != nil {
+			panic(err)
+		}
+		err = renderer.Render(cnv)
+		if err != nil {
+			t.Errorf("Case: %#v, Expected: err == nil, Got: %#v", c.mode, err)
+		}
+		if writer.String() != c.expected {
+			t.Errorf("Case: %#v, Expected: %q, Got: %q", c.mode, c.expected, writer.String())
+		}
+	}
+}
+
+func TestRenderer_Render_RGBA(t *testing.T) {
+	transparent := rgba.Color{R: 0xff, G: 0xff, B: 0xff, A: 0x00}
+	orange := rgba.Color{R: 0xff, G: 0x80, B: 0x00, A: 0xff}
+	cnv, err := rc.NewBuffer(3, 1, transparent, orange)
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.DrawLine(1, 0, 1, 0)
+	if err != nil {
+		panic(err)
+	}
+
+	writer := new(bytes.Buffer)
+	renderer, err := NewRenderer(writer, ColorModeTrueColor, bytecolor.Palette{})
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	expected := "┌───┐\n│ \x1b[38;2;255;128;0m█\x1b[0m │\n└───┘\n\n"
+	if writer.String() != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, writer.String())
+	}
+}
+
+func TestToColor256(t *testing.T) {
+	cases := []struct {
+		c     imagecolor.NRGBA
+		index int
+	}{
+		{imagecolor.NRGBA{0x00, 0x00, 0x00, 0xff}, 16},
+		{imagecolor.NRGBA{0xff, 0xff, 0xff, 0xff}, 231},
+		{imagecolor.NRGBA{0xff, 0x00, 0x00, 0xff}, 196},
+		{imagecolor.NRGBA{0x5f, 0x87, 0xaf, 0xff}, 67},
+		{imagecolor.NRGBA{0x80, 0x80, 0x80, 0xff}, 244},
+		{imagecolor.NRGBA{0x08, 0x08, 0x08, 0xff}, 232},
+	}
+	for _, c := range cases {
+		index := toColor256(c.c)
+		if index != c.index {
+			t.Errorf("Case: %#v, Expected: %d, Got: %d", c.c, c.index, index)
+		}
+	}
+}
+
+// This type is created for testing purpose only
+type errWriter struct{}
+
+var errWrite = errors.New("write error")
+
+func (w errWriter) Write(p []byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestRenderer_Render_Neg(t *testing.T) {
+	cnv, err := bc.NewBuffer(3, 2, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+
+	writer := new(bytes.Buffer)
+	renderer, err := NewRenderer(writer, ColorMode16, bytecolor.DefaultPalette())
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(nil)
+	if err != common.ErrCanvasNotSupported {
+		t.Errorf("Expected: err == %#v, Got: %#v", common.ErrCanvasNotSupported, err)
+	}
+
+	renderer, err = NewRenderer(writer, ColorMode16, bytecolor.Palette{})
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != common.ErrColorNotSupported {
+		t.Errorf("Expected: err == %#v, Got: %#v", common.ErrColorNotSupported, err)
+	}
+	if writer.Len() != 0 {
+		t.Errorf("Expected: nothing written, Got: %q", writer.String())
+	}
+
+	renderer, err = NewRenderer(errWriter{}, ColorMode16, bytecolor.DefaultPalette())
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != errWrite {
+		t.Errorf("Expected: err == %#v, Got: %#v", errWrite, err)
+	}
+}
@@ -0,0 +1,126 @@
+// Package json defines the Renderer type,
+// which implements the renderer.Renderer interface.
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	imagecolor "image/color"
+	"io"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/common"
+	"github.com/asukakenji/drawing-challenge/renderer"
+)
+
+// Renderer is a renderer encoding canvases to JSON documents.
+// It implements the renderer.Renderer interface.
+//
+// The documents are in the following form:
+//
+//     {"width":2,"height":1,"pixels":[["#ffffff","#ff800080"]]}
+//
+// where pixels contains the rows of the canvas, and each pixel is in the form
+// "#RRGGBB" if it is opaque, or "#RRGGBBAA" otherwise.
+type Renderer struct {
+	writer  io.Writer
+	palette bytecolor.Palette
+}
+
+// Ensure that Renderer implements the renderer.Renderer interface.
+var (
+	_ renderer.Renderer = &Renderer{}
+)
+
+// document is the structure of the JSON documents.
+type document struct {
+	Width  int        `json:"width"`
+	Height int        `json:"height"`
+	Pixels [][]string `json:"pixels"`
+}
+
+// NewRenderer returns a new Renderer.
+// The JSON documents are written to writer.
+// The byte colors are mapped to RGBA colors by palette.
+//
+// Errors
+//
+// common.ErrNilPointer:
+// Will be returned if writer == nil or palette == nil.
+//
+func NewRenderer(writer io.Writer, palette bytecolor.Palette) (*Renderer, error) {
+	if writer == nil {
+		return nil, common.ErrNilPointer
+	}
+	if palette == nil {
+		return nil, common.ErrNilPointer
+	}
+	return &Renderer{
+		writer:  writer,
+		palette: palette,
+	}, nil
+}
+
+// Render renders cnv.
+//
+// cnv must implement the canvas.BufferBasedCanvas interface.
+// The pixels of cnv must be bytecolor.Color in the palette,
+// or implement the image/color.Color interface.
+//
+// Errors
+//
+// common.ErrCanvasNotSupported:
+// Will be returned if cnv is not supported by this renderer.
+//
+// common.ErrColorNotSupported:
+// Will be returned if a color inside cnv is not supported by this renderer.
+//
+// Errors returned from the writer are returned without modifications.
+//
+func (rdr *Renderer) Render(cnv canvas.Canvas) error {
+	bbcnv, ok := cnv.(canvas.BufferBasedCanvas)
+	if !ok {
+		return common.ErrCanvasNotSupported
+	}
+	width, height := bbcnv.Dimensions()
+	doc := document{
+		Width:  width,
+		Height: height,
+		Pixels: make([][]string, height),
+	}
+	for j := 0; j < height; j++ {
+		row := make([]string, width)
+		for i := 0; i < width; i++ {
+			c, err := bbcnv.At(i, j)
+			if err != nil {
+				// NOTE: This should not happen if the canvas is correctly implemented
+				panic(err)
+			}
+			rgba, err := rdr.palette.ToRGBA(c)
+			if err != nil {
+				return err
+			}
+			row[i] = hex(rgba)
+		}
+		doc.Pixels[j] = row
+	}
+	// NOTE: The document is encoded before written, so that nothing is written on errors
+	data, err := json.Marshal(doc)
+	if err != nil {
+		// NOTE: This should not happen since document contains only ints and strings
+		panic(err)
+	}
+	_, err = rdr.writer.Write(append(data, '\n'))
+	return err
+}
+
+// hex returns c in the form "#RRGGBB" if it is opaque,
+// or "#RRGGBBAA" otherwise.
+func hex(c imagecolor.RGBA) string {
+	nrgba := imagecolor.NRGBAModel.Convert(c).(imagecolor.NRGBA)
+	if nrgba.A == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", nrgba.R, nrgba.G, nrgba.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", nrgba.R, nrgba.G, nrgba.B, nrgba.A)
+}
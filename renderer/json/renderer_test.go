@@ -0,0 +1,139 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	bc "github.com/asukakenji/drawing-challenge/canvas/bytecolor"
+	rc "github.com/asukakenji/drawing-challenge/canvas/rgba"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/color/rgba"
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+func TestNewRenderer(t *testing.T) {
+	_, err := NewRenderer(new(bytes.Buffer), bytecolor.DefaultPalette())
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+
+	casesNeg := []struct {
+		writer  io.Writer
+		palette bytecolor.Palette
+		err     error
+	}{
+		{nil, bytecolor.DefaultPalette(), common.ErrNilPointer},
+		{new(bytes.Buffer), nil, common.ErrNilPointer},
+	}
+	for _, c := range casesNeg {
+		_, err := NewRenderer(c.writer, c.palette)
+		if err != c.err {
+			t.Errorf("Case: %#v, Expected: %#v, Got: %#v", c.palette == nil, c.err, err)
+		}
+	}
+}
+
+func TestRenderer_Render(t *testing.T) {
+	cnv, err := bc.NewBuffer(3, 2, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.DrawLine(0, 1, 2, 1)
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.Set(1, 0, bytecolor.Color('R'))
+	if err != nil {
+		panic(err)
+	}
+
+	writer := new(bytes.Buffer)
+	renderer, err := NewRenderer(writer, bytecolor.DefaultPalette())
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	expected := `{"width":3,"height":2,"pixels":[["#ffffff","#ff0000","#ffffff"],["#000000","#000000","#000000"]]}` + "\n"
+	if writer.String() != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, writer.String())
+	}
+}
+
+func TestRenderer_Render_RGBA(t *testing.T) {
+	transparent := rgba.Color{R: 0xff, G: 0xff, B: 0xff, A: 0x00}
+	orange := rgba.Color{R: 0xff, G: 0x80, B: 0x00, A: 0xff}
+	cnv, err := rc.NewBuffer(2, 1, transparent, orange)
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.DrawLine(1, 0, 1, 0)
+	if err != nil {
+		panic(err)
+	}
+
+	writer := new(bytes.Buffer)
+	renderer, err := NewRenderer(writer, bytecolor.Palette{})
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	expected := `{"width":2,"height":1,"pixels":[["#00000000","#ff8000"]]}` + "\n"
+	if writer.String() != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, writer.String())
+	}
+}
+
+// This type is created for testing purpose only
+type errWriter struct{}
+
+var errWrite = errors.New("write error")
+
+func (w errWriter) Write(p []byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestRenderer_Render_Neg(t *testing.T) {
+	cnv, err := bc.NewBuffer(3, 2, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+
+	writer := new(bytes.Buffer)
+	renderer, err := NewRenderer(writer, bytecolor.DefaultPalette())
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(nil)
+	if err != common.ErrCanvasNotSupported {
+		t.Errorf("Expected: err == %#v, Got: %#v", common.ErrCanvasNotSupported, err)
+	}
+
+	renderer, err = NewRenderer(writer, bytecolor.Palette{})
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != common.ErrColorNotSupported {
+		t.Errorf("Expected: err == %#v, Got: %#v", common.ErrColorNotSupported, err)
+	}
+	if writer.Len() != 0 {
+		t.Errorf("Expected: nothing written, Got: %q", writer.String())
+	}
+
+	renderer, err = NewRenderer(errWriter{}, bytecolor.DefaultPalette())
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != errWrite {
+		t.Errorf("Expected: err == %#v, Got: %#v", errWrite, err)
+	}
+}
@@ -0,0 +1,113 @@
+// Package png defines the Renderer type,
+// which implements the renderer.Renderer interface.
+package png
+
+import (
+	"image"
+	"image/png"
+	"io"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	bc "github.com/asukakenji/drawing-challenge/canvas/bytecolor"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/common"
+	"github.com/asukakenji/drawing-challenge/renderer"
+)
+
+// Renderer is a renderer encoding canvases to PNG images.
+// It implements the renderer.Renderer interface.
+type Renderer struct {
+	writer  io.Writer
+	palette bytecolor.Palette
+	scale   int
+}
+
+// Ensure that Renderer implements the renderer.Renderer interface.
+var (
+	_ renderer.Renderer = &Renderer{}
+)
+
+// NewRenderer returns a new Renderer.
+// The PNG images are written to writer.
+// The byte colors are mapped to RGBA colors by palette.
+// Each pixel of the canvas is rendered as a (scale x scale) square.
+//
+// Errors
+//
+// common.ErrNilPointer:
+// Will be returned if writer == nil or palette == nil.
+//
+// common.ErrScaleNotPositive:
+// Will be returned if scale <= 0.
+//
+func NewRenderer(writer io.Writer, palette bytecolor.Palette, scale int) (*Renderer, error) {
+	if writer == nil {
+		return nil, common.ErrNilPointer
+	}
+	if palette == nil {
+		return nil, common.ErrNilPointer
+	}
+	if scale <= 0 {
+		return nil, common.ErrScaleNotPositive
+	}
+	return &Renderer{
+		writer:  writer,
+		palette: palette,
+		scale:   scale,
+	}, nil
+}
+
+// Render renders cnv.
+//
+// cnv must implement the canvas.BufferBasedCanvas interface.
+// The pixels of cnv must be bytecolor.Color in the palette,
+// or implement the image/color.Color interface.
+//
+// Errors
+//
+// common.ErrCanvasNotSupported:
+// Will be returned if cnv is not supported by this renderer.
+//
+// common.ErrColorNotSupported:
+// Will be returned if a color inside cnv is not supported by this renderer.
+//
+// Errors returned from the writer are returned without modifications.
+//
+func (rdr *Renderer) Render(cnv canvas.Canvas) error {
+	bbcnv, ok := cnv.(canvas.BufferBasedCanvas)
+	if !ok {
+		return common.ErrCanvasNotSupported
+	}
+	width, height := bbcnv.Dimensions()
+	var pixels []bytecolor.Color
+	if bcbcnv, ok := bbcnv.(*bc.Buffer); ok {
+		// Read the pixels without calling At for each of them if it is a bytecolor buffer.
+		pixels = bcbcnv.Pixels()
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width*rdr.scale, height*rdr.scale))
+	for j := 0; j < height; j++ {
+		for i := 0; i < width; i++ {
+			var c interface{}
+			if pixels != nil {
+				c = pixels[j*width+i]
+			} else {
+				var err error
+				c, err = bbcnv.At(i, j)
+				if err != nil {
+					// NOTE: This should not happen if the canvas is correctly implemented
+					panic(err)
+				}
+			}
+			rgba, err := rdr.palette.ToRGBA(c)
+			if err != nil {
+				return err
+			}
+			for y := j * rdr.scale; y < (j+1)*rdr.scale; y++ {
+				for x := i * rdr.scale; x < (i+1)*rdr.scale; x++ {
+					img.SetRGBA(x, y, rgba)
+				}
+			}
+		}
+	}
+	return png.Encode(rdr.writer, img)
+}
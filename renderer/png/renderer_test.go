@@ -0,0 +1,176 @@
+package png
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"image/png"
+	"io"
+	"testing"
+
+	bc "github.com/asukakenji/drawing-challenge/canvas/bytecolor"
+	rc "github.com/asukakenji/drawing-challenge/canvas/rgba"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/color/rgba"
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+func TestNewRenderer(t *testing.T) {
+	_, err := NewRenderer(new(bytes.Buffer), bytecolor.DefaultPalette(), 1)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+
+	casesNeg := []struct {
+		writer  io.Writer
+		palette bytecolor.Palette
+		scale   int
+		err     error
+	}{
+		{nil, bytecolor.DefaultPalette(), 1, common.ErrNilPointer},
+		{new(bytes.Buffer), nil, 1, common.ErrNilPointer},
+		{new(bytes.Buffer), bytecolor.DefaultPalette(), 0, common.ErrScaleNotPositive},
+		{new(bytes.Buffer), bytecolor.DefaultPalette(), -1, common.ErrScaleNotPositive},
+	}
+	for _, c := range casesNeg {
+		_, err := NewRenderer(c.writer, c.palette, c.scale)
+		if err != c.err {
+			t.Errorf("Case: (%#v, %d), Expected: %#v, Got: %#v", c.palette == nil, c.scale, c.err, err)
+		}
+	}
+}
+
+func TestRenderer_Render(t *testing.T) {
+	cnv, err := bc.NewBuffer(3, 2, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.DrawLine(0, 0, 2, 0)
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.BucketFill(1, 1, bytecolor.Color('R'))
+	if err != nil {
+		panic(err)
+	}
+
+	writer := new(bytes.Buffer)
+	renderer, err := NewRenderer(writer, bytecolor.DefaultPalette(), 2)
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+
+	img, err := png.Decode(writer)
+	if err != nil {
+		t.Fatalf("Expected: err == nil, Got: %#v", err)
+	}
+	bounds := img.Bounds()
+	if bounds.Dx() != 6 || bounds.Dy() != 4 {
+		t.Errorf("Expected: 6x4, Got: %dx%d", bounds.Dx(), bounds.Dy())
+	}
+	black := color.RGBA{0x00, 0x00, 0x00, 0xff}
+	red := color.RGBA{0xff, 0x00, 0x00, 0xff}
+	cases := []struct {
+		x, y int
+		rgba color.RGBA
+	}{
+		{0, 0, black},
+		{5, 1, black},
+		{0, 2, red},
+		{5, 3, red},
+	}
+	for _, c := range cases {
+		rgba := color.RGBAModel.Convert(img.At(c.x, c.y)).(color.RGBA)
+		if rgba != c.rgba {
+			t.Errorf("Case: (%d, %d), Expected: %#v, Got: %#v", c.x, c.y, c.rgba, rgba)
+		}
+	}
+}
+
+// This type is created for testing purpose only
+type errWriter struct{}
+
+var errWrite = errors.New("write error")
+
+func (w errWriter) Write(p []byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestRenderer_Render_Neg(t *testing.T) {
+	cnv, err := bc.NewBuffer(3, 2, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+
+	renderer, err := NewRenderer(new(bytes.Buffer), bytecolor.DefaultPalette(), 1)
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(nil)
+	if err != common.ErrCanvasNotSupported {
+		t.Errorf("Expected: err == %#v, Got: %#v", common.ErrCanvasNotSupported, err)
+	}
+
+	renderer, err = NewRenderer(new(bytes.Buffer), bytecolor.Palette{}, 1)
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != common.ErrColorNotSupported {
+		t.Errorf("Expected: err == %#v, Got: %#v", common.ErrColorNotSupported, err)
+	}
+
+	renderer, err = NewRenderer(errWriter{}, bytecolor.DefaultPalette(), 1)
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != errWrite {
+		t.Errorf("Expected: err == %#v, Got: %#v", errWrite, err)
+	}
+}
+
+func TestRenderer_Render_RGBA(t *testing.T) {
+	bgColor := rgba.Color{R: 0xff, G: 0xff, B: 0xff, A: 0x00}
+	fgColor := rgba.Color{R: 0x00, G: 0x80, B: 0xff, A: 0xff}
+	cnv, err := rc.NewBuffer(2, 1, bgColor, fgColor)
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.DrawLine(1, 0, 1, 0)
+	if err != nil {
+		panic(err)
+	}
+
+	writer := new(bytes.Buffer)
+	renderer, err := NewRenderer(writer, bytecolor.Palette{}, 1)
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+
+	img, err := png.Decode(writer)
+	if err != nil {
+		t.Fatalf("Expected: err == nil, Got: %#v", err)
+	}
+	cases := []struct {
+		x, y  int
+		nrgba color.NRGBA
+	}{
+		{0, 0, color.NRGBA{0x00, 0x00, 0x00, 0x00}},
+		{1, 0, color.NRGBA{0x00, 0x80, 0xff, 0xff}},
+	}
+	for _, c := range cases {
+		nrgba := color.NRGBAModel.Convert(img.At(c.x, c.y)).(color.NRGBA)
+		if nrgba != c.nrgba {
+			t.Errorf("Case: (%d, %d), Expected: %#v, Got: %#v", c.x, c.y, c.nrgba, nrgba)
+		}
+	}
+}
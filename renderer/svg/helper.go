@@ -0,0 +1,113 @@
+package svg
+
+import (
+	"fmt"
+	imagecolor "image/color"
+	"strconv"
+	"strings"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+)
+
+// rect represents a rectangle of pixels of the same color.
+type rect struct {
+	x      int
+	y      int
+	width  int
+	height int
+	c      imagecolor.RGBA
+}
+
+// mergeRuns returns the rectangles covering the pixels of cnv.
+// Each run of horizontally adjacent pixels of the same color is a rectangle,
+// which is extended downwards while the same run is found in the next row.
+// Fully transparent pixels are not covered.
+// The rectangles are sorted by the top-left corners, row by row.
+func mergeRuns(cnv canvas.BufferBasedCanvas, palette bytecolor.Palette) ([]rect, error) {
+	width, height := cnv.Dimensions()
+	var rects []rect
+	// open maps the x coordinate of a run to the index of the rectangle
+	// ending at the previous row
+	open := map[int]int{}
+	for j := 0; j < height; j++ {
+		nextOpen := map[int]int{}
+		for i := 0; i < width; {
+			c, err := rgbaAt(cnv, palette, i, j)
+			if err != nil {
+				return nil, err
+			}
+			runWidth := 1
+			for ; i+runWidth < width; runWidth++ {
+				c2, err := rgbaAt(cnv, palette, i+runWidth, j)
+				if err != nil {
+					return nil, err
+				}
+				if c2 != c {
+					break
+				}
+			}
+			if c.A != 0 {
+				if index, ok := open[i]; ok && rects[index].width == runWidth && rects[index].c == c {
+					rects[index].height++
+					nextOpen[i] = index
+				} else {
+					nextOpen[i] = len(rects)
+					rects = append(rects, rect{i, j, runWidth, 1, c})
+				}
+			}
+			i += runWidth
+		}
+		open = nextOpen
+	}
+	return rects, nil
+}
+
+// rgbaAt returns the color of the pixel at (x, y) of cnv,
+// mapped to an RGBA color by palette.
+func rgbaAt(cnv canvas.BufferBasedCanvas, palette bytecolor.Palette, x, y int) (imagecolor.RGBA, error) {
+	c, err := cnv.At(x, y)
+	if err != nil {
+		// NOTE: This should not happen if the canvas is correctly implemented
+		panic(err)
+	}
+	return palette.ToRGBA(c)
+}
+
+// paint returns the attribute named name which paints with c,
+// and the opacity attribute if c is not opaque.
+// The result starts with a space.
+func paint(name string, c imagecolor.RGBA) string {
+	if c.A == 0 {
+		return fmt.Sprintf(` %s="none"`, name)
+	}
+	nrgba := imagecolor.NRGBAModel.Convert(c).(imagecolor.NRGBA)
+	attr := fmt.Sprintf(` %s="#%02x%02x%02x"`, name, nrgba.R, nrgba.G, nrgba.B)
+	if nrgba.A != 0xff {
+		attr += fmt.Sprintf(` %s-opacity="%s"`, name, strconv.FormatFloat(float64(nrgba.A)/0xff, 'g', 3, 64))
+	}
+	return attr
+}
+
+// center returns the coordinate of the center of the pixel at v.
+func center(v int) string {
+	return strconv.Itoa(v) + ".5"
+}
+
+// pointsAttr returns the value of the points attribute
+// passing through the centers of the pixels at points.
+func pointsAttr(points []canvas.Point) string {
+	coords := make([]string, len(points))
+	for i, p := range points {
+		coords[i] = center(p.X) + "," + center(p.Y)
+	}
+	return strings.Join(coords, " ")
+}
+
+// minMax returns a and b in ascending order.
+func minMax(a, b int) (int, int) {
+	if a > b {
+		return b, a
+	}
+	return a, b
+}
@@ -0,0 +1,545 @@
+package svg
+
+import (
+	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/color"
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+// elementKind specifies the kind of an element.
+type elementKind int
+
+const (
+	elementLine elementKind = iota
+	elementRect
+	elementEllipse
+	elementPolyline
+	elementPolygon
+)
+
+// element represents a drawing operation recorded by Recorder.
+type element struct {
+	kind   elementKind
+	points []canvas.Point
+	rx     int
+	ry     int
+	stroke color.Color
+	fill   color.Color // nil if the element is not filled
+	rule   canvas.FillRule
+}
+
+// Recorder is a canvas recording the drawing operations,
+// so that they could be rendered as vector elements by Renderer.
+// The operations are also forwarded to the underlying canvas.
+// It implements the canvas.BufferBasedCanvas interface,
+// the canvas.ResizableCanvas interface,
+// and the canvas.TransformableCanvas interface.
+//
+// DrawText, BucketFill, FloodFill, Set, Crop, Extend, Scale, Transform, and
+// TransformRect could not be represented as vector elements. Once any of them
+// is called, the canvas is rendered pixel by pixel.
+type Recorder struct {
+	cnv        canvas.Canvas
+	base       canvas.Canvas
+	elements   []element
+	rasterOnly bool
+}
+
+// Ensure that Recorder implements the canvas.BufferBasedCanvas interface,
+// the canvas.ResizableCanvas interface,
+// and the canvas.TransformableCanvas interface.
+var (
+	_ canvas.BufferBasedCanvas   = &Recorder{}
+	_ canvas.ResizableCanvas     = &Recorder{}
+	_ canvas.TransformableCanvas = &Recorder{}
+)
+
+// NewRecorder returns a new Recorder forwarding the operations to cnv.
+// The current content of cnv is kept as the background of the vector elements.
+//
+// Errors
+//
+// common.ErrNilPointer:
+// Will be returned if cnv == nil.
+//
+func NewRecorder(cnv canvas.Canvas) (*Recorder, error) {
+	if cnv == nil {
+		return nil, common.ErrNilPointer
+	}
+	return &Recorder{
+		cnv:  cnv,
+		base: cnv.Clone(),
+	}, nil
+}
+
+// record appends an element.
+// The foreground color is used if the stroke of the element is nil.
+func (rec *Recorder) record(e element) {
+	if e.stroke == nil {
+		e.stroke = rec.cnv.ForegroundColor()
+	}
+	rec.elements = append(rec.elements, e)
+}
+
+// Dimensions returns the width and height.
+func (rec *Recorder) Dimensions() (int, int) {
+	return rec.cnv.Dimensions()
+}
+
+// Clone returns a deep copy of the canvas,
+// which is not affected by the subsequent changes of the canvas.
+func (rec *Recorder) Clone() canvas.Canvas {
+	clone := *rec
+	clone.cnv = rec.cnv.Clone()
+	// NOTE: The base and the elements are never modified, so they are shared
+	clone.elements = rec.elements[:len(rec.elements):len(rec.elements)]
+	return &clone
+}
+
+// At returns the color of the pixel at (x, y).
+//
+// Errors
+//
+// common.ErrCanvasNotSupported:
+// Will be returned if the underlying canvas does not implement
+// the canvas.BufferBasedCanvas interface.
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x, y) is outside the canvas.
+//
+func (rec *Recorder) At(x, y int) (color.Color, error) {
+	bbcnv, ok := rec.cnv.(canvas.BufferBasedCanvas)
+	if !ok {
+		return nil, common.ErrCanvasNotSupported
+	}
+	return bbcnv.At(x, y)
+}
+
+// Set sets the color of the pixel at (x, y).
+//
+// Errors
+//
+// common.ErrCanvasNotSupported:
+// Will be returned if the underlying canvas does not implement
+// the canvas.BufferBasedCanvas interface.
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x, y) is outside the canvas.
+//
+// common.ErrColorTypeNotSupported:
+// Will be returned if c is not supported by the canvas.
+//
+func (rec *Recorder) Set(x, y int, c color.Color) error {
+	bbcnv, ok := rec.cnv.(canvas.BufferBasedCanvas)
+	if !ok {
+		return common.ErrCanvasNotSupported
+	}
+	err := bbcnv.Set(x, y, c)
+	if err != nil {
+		return err
+	}
+	rec.rasterOnly = true
+	return nil
+}
+
+// ForegroundColor returns the foreground color,
+// which is used to draw lines and shapes.
+func (rec *Recorder) ForegroundColor() color.Color {
+	return rec.cnv.ForegroundColor()
+}
+
+// SetForegroundColor sets the foreground color,
+// which is used to draw lines and shapes.
+//
+// Errors
+//
+// common.ErrColorTypeNotSupported:
+// Will be returned if c is not supported by the canvas.
+//
+func (rec *Recorder) SetForegroundColor(c color.Color) error {
+	return rec.cnv.SetForegroundColor(c)
+}
+
+// SetLineMode sets the line mode used by DrawLine.
+//
+// Errors
+//
+// common.ErrLineModeNotSupported:
+// Will be returned if mode is not supported by the canvas.
+//
+func (rec *Recorder) SetLineMode(mode canvas.LineMode) error {
+	return rec.cnv.SetLineMode(mode)
+}
+
+// DrawLine draws a line from (x1, y1) to (x2, y2).
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x1, y1) or (x2, y2) is outside the canvas.
+//
+// common.ErrLineNotHorizontalOrVertical:
+// Will be returned if the line mode is LineModeHorizontalOrVertical,
+// and the line is not horizontal or vertical.
+//
+func (rec *Recorder) DrawLine(x1, y1, x2, y2 int) error {
+	err := rec.cnv.DrawLine(x1, y1, x2, y2)
+	if err != nil {
+		return err
+	}
+	rec.record(element{
+		kind:   elementLine,
+		points: []canvas.Point{{X: x1, Y: y1}, {X: x2, Y: y2}},
+	})
+	return nil
+}
+
+// DrawRect draws a rectangle.
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x1, y1) or (x2, y2) is outside the canvas.
+//
+func (rec *Recorder) DrawRect(x1, y1, x2, y2 int) error {
+	err := rec.cnv.DrawRect(x1, y1, x2, y2)
+	if err != nil {
+		return err
+	}
+	rec.record(element{
+		kind:   elementRect,
+		points: []canvas.Point{{X: x1, Y: y1}, {X: x2, Y: y2}},
+	})
+	return nil
+}
+
+// FillRect draws a rectangle with stroke,
+// and paints the interior of the rectangle with fill.
+// The foreground color is used if stroke is nil.
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x1, y1) or (x2, y2) is outside the canvas.
+//
+// common.ErrColorTypeNotSupported:
+// Will be returned if stroke or fill is not supported by the canvas.
+//
+func (rec *Recorder) FillRect(x1, y1, x2, y2 int, stroke, fill color.Color) error {
+	err := rec.cnv.FillRect(x1, y1, x2, y2, stroke, fill)
+	if err != nil {
+		return err
+	}
+	rec.record(element{
+		kind:   elementRect,
+		points: []canvas.Point{{X: x1, Y: y1}, {X: x2, Y: y2}},
+		stroke: stroke,
+		fill:   fill,
+	})
+	return nil
+}
+
+// DrawCircle draws a circle centered at (xc, yc) with radius r.
+// The interior of the circle is also painted if filled is true.
+//
+// Errors
+//
+// common.ErrRadiusNegative:
+// Will be returned if r < 0.
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if any part of the circle is outside the canvas.
+//
+func (rec *Recorder) DrawCircle(xc, yc, r int, filled bool) error {
+	return rec.DrawEllipse(xc, yc, r, r, filled)
+}
+
+// DrawEllipse draws an axis-aligned ellipse centered at (xc, yc)
+// with horizontal radius rx and vertical radius ry.
+// The interior of the ellipse is also painted if filled is true.
+//
+// Errors
+//
+// common.ErrRadiusNegative:
+// Will be returned if rx < 0, or ry < 0.
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if any part of the ellipse is outside the canvas.
+//
+func (rec *Recorder) DrawEllipse(xc, yc, rx, ry int, filled bool) error {
+	err := rec.cnv.DrawEllipse(xc, yc, rx, ry, filled)
+	if err != nil {
+		return err
+	}
+	e := element{
+		kind:   elementEllipse,
+		points: []canvas.Point{{X: xc, Y: yc}},
+		rx:     rx,
+		ry:     ry,
+	}
+	if filled {
+		e.fill = rec.cnv.ForegroundColor()
+	}
+	rec.record(e)
+	return nil
+}
+
+// DrawPolyline draws connected line segments through points.
+// The line mode does not apply to polylines.
+//
+// Errors
+//
+// common.ErrTooFewPoints:
+// Will be returned if len(points) < 2.
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if any of points is outside the canvas.
+//
+func (rec *Recorder) DrawPolyline(points []canvas.Point) error {
+	err := rec.cnv.DrawPolyline(points)
+	if err != nil {
+		return err
+	}
+	rec.record(element{
+		kind:   elementPolyline,
+		points: append([]canvas.Point(nil), points...),
+	})
+	return nil
+}
+
+// DrawPolygon draws a closed polygon with vertices points.
+// The interior of the polygon is also painted according to rule
+// if filled is true. The line mode does not apply to polygons.
+//
+// Errors
+//
+// common.ErrTooFewPoints:
+// Will be returned if len(points) < 3.
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if any of points is outside the canvas.
+//
+// common.ErrFillRuleNotSupported:
+// Will be returned if rule is not supported by the canvas.
+//
+func (rec *Recorder) DrawPolygon(points []canvas.Point, filled bool, rule canvas.FillRule) error {
+	err := rec.cnv.DrawPolygon(points, filled, rule)
+	if err != nil {
+		return err
+	}
+	e := element{
+		kind:   elementPolygon,
+		points: append([]canvas.Point(nil), points...),
+		rule:   rule,
+	}
+	if filled {
+		e.fill = rec.cnv.ForegroundColor()
+	}
+	rec.record(e)
+	return nil
+}
+
+// SetTextMode sets the text mode used by DrawText.
+//
+// Errors
+//
+// common.ErrTextModeNotSupported:
+// Will be returned if mode is not supported by the canvas.
+//
+func (rec *Recorder) SetTextMode(mode canvas.TextMode) error {
+	return rec.cnv.SetTextMode(mode)
+}
+
+// DrawText draws text. (x, y) is the top-left corner of the text.
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if any part of the text is outside the canvas.
+//
+func (rec *Recorder) DrawText(x, y int, text string) error {
+	err := rec.cnv.DrawText(x, y, text)
+	if err != nil {
+		return err
+	}
+	rec.rasterOnly = true
+	return nil
+}
+
+// BucketFill fills the area enclosing (x, y). The pixels connecting to
+// (x, y) having the same color as that at (x, y) are replaced by c.
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x, y) is outside the canvas.
+//
+// common.ErrColorTypeNotSupported:
+// Will be returned if c is not supported by the canvas.
+//
+func (rec *Recorder) BucketFill(x, y int, c color.Color) error {
+	err := rec.cnv.BucketFill(x, y, c)
+	if err != nil {
+		return err
+	}
+	rec.rasterOnly = true
+	return nil
+}
+
+// FloodFill fills the area enclosing (x, y) like BucketFill,
+// but the area is determined according to opts.
+//
+// Errors
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x, y) is outside the canvas.
+//
+// common.ErrColorTypeNotSupported:
+// Will be returned if c is not supported by the canvas.
+//
+// common.ErrToleranceNegative:
+// Will be returned if opts.Tolerance < 0.
+//
+// common.ErrToleranceNotSupported:
+// Will be returned if opts.Tolerance > 0, but it is not supported by the canvas.
+//
+func (rec *Recorder) FloodFill(x, y int, c color.Color, opts canvas.FillOptions) error {
+	err := rec.cnv.FloodFill(x, y, c, opts)
+	if err != nil {
+		return err
+	}
+	rec.rasterOnly = true
+	return nil
+}
+
+// Crop crops the canvas to the rectangle with corners (x1, y1) and (x2, y2).
+// The pixels on the edges of the rectangle are kept.
+//
+// Errors
+//
+// common.ErrCanvasNotSupported:
+// Will be returned if the underlying canvas does not implement
+// the canvas.ResizableCanvas interface.
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x1, y1) or (x2, y2) is outside the canvas.
+//
+func (rec *Recorder) Crop(x1, y1, x2, y2 int) error {
+	rcnv, ok := rec.cnv.(canvas.ResizableCanvas)
+	if !ok {
+		return common.ErrCanvasNotSupported
+	}
+	err := rcnv.Crop(x1, y1, x2, y2)
+	if err != nil {
+		return err
+	}
+	rec.rasterOnly = true
+	return nil
+}
+
+// Extend extends the canvas by left, top, right, and bottom pixels
+// on the respective sides. The new pixels are in the background color.
+//
+// Errors
+//
+// common.ErrCanvasNotSupported:
+// Will be returned if the underlying canvas does not implement
+// the canvas.ResizableCanvas interface.
+//
+// common.ErrExtentNegative:
+// Will be returned if left < 0, top < 0, right < 0, or bottom < 0.
+//
+func (rec *Recorder) Extend(left, top, right, bottom int) error {
+	rcnv, ok := rec.cnv.(canvas.ResizableCanvas)
+	if !ok {
+		return common.ErrCanvasNotSupported
+	}
+	err := rcnv.Extend(left, top, right, bottom)
+	if err != nil {
+		return err
+	}
+	rec.rasterOnly = true
+	return nil
+}
+
+// Scale scales the canvas to width x height.
+// Each pixel takes the color of the nearest pixel of the original canvas.
+//
+// Errors
+//
+// common.ErrCanvasNotSupported:
+// Will be returned if the underlying canvas does not implement
+// the canvas.ResizableCanvas interface.
+//
+// common.ErrWidthOrHeightNotPositive:
+// Will be returned if width <= 0, or height <= 0.
+//
+func (rec *Recorder) Scale(width, height int) error {
+	rcnv, ok := rec.cnv.(canvas.ResizableCanvas)
+	if !ok {
+		return common.ErrCanvasNotSupported
+	}
+	err := rcnv.Scale(width, height)
+	if err != nil {
+		return err
+	}
+	rec.rasterOnly = true
+	return nil
+}
+
+// Transform flips or rotates the whole canvas.
+// The width and height are swapped by canvas.TransformRotate90
+// and canvas.TransformRotate270.
+//
+// Errors
+//
+// common.ErrCanvasNotSupported:
+// Will be returned if the underlying canvas does not implement
+// the canvas.TransformableCanvas interface.
+//
+// common.ErrTransformNotSupported:
+// Will be returned if t is not supported by the canvas.
+//
+func (rec *Recorder) Transform(t canvas.Transform) error {
+	tcnv, ok := rec.cnv.(canvas.TransformableCanvas)
+	if !ok {
+		return common.ErrCanvasNotSupported
+	}
+	err := tcnv.Transform(t)
+	if err != nil {
+		return err
+	}
+	rec.rasterOnly = true
+	return nil
+}
+
+// TransformRect flips or rotates the rectangle with corners (x1, y1)
+// and (x2, y2). The top-left corner of the rectangle is kept. If the width
+// and height are swapped, the pixels of the rectangle not covered by the
+// transformed one are painted with the background color.
+//
+// Errors
+//
+// common.ErrCanvasNotSupported:
+// Will be returned if the underlying canvas does not implement
+// the canvas.TransformableCanvas interface.
+//
+// common.ErrPointOutsideCanvas:
+// Will be returned if (x1, y1) or (x2, y2) is outside the canvas,
+// or any part of the transformed rectangle is outside the canvas.
+//
+// common.ErrTransformNotSupported:
+// Will be returned if t is not supported by the canvas.
+//
+func (rec *Recorder) TransformRect(x1, y1, x2, y2 int, t canvas.Transform) error {
+	tcnv, ok := rec.cnv.(canvas.TransformableCanvas)
+	if !ok {
+		return common.ErrCanvasNotSupported
+	}
+	err := tcnv.TransformRect(x1, y1, x2, y2, t)
+	if err != nil {
+		return err
+	}
+	rec.rasterOnly = true
+	return nil
+}
@@ -0,0 +1,131 @@
+package svg
+
+import (
+	"testing"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	bc "github.com/asukakenji/drawing-challenge/canvas/bytecolor"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+// This type is created for testing purpose only
+type nonBufferBasedCanvas struct {
+	canvas.Canvas
+}
+
+func TestNewRecorder(t *testing.T) {
+	_, err := NewRecorder(nil)
+	if err != common.ErrNilPointer {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrNilPointer, err)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	cnv, err := bc.NewBuffer(3, 3, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+	rec, err := NewRecorder(cnv)
+	if err != nil {
+		panic(err)
+	}
+
+	// The operations are forwarded and recorded
+	err = rec.DrawLine(0, 0, 2, 0)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	c, err := rec.At(1, 0)
+	if err != nil || c != bytecolor.Color('x') {
+		t.Errorf("Expected: %#v, Got: %#v, %#v", bytecolor.Color('x'), c, err)
+	}
+	if len(rec.elements) != 1 || rec.elements[0].stroke != bytecolor.Color('x') {
+		t.Errorf("Expected: 1 element drawn with 'x', Got: %#v", rec.elements)
+	}
+
+	// Failed operations are not recorded
+	err = rec.DrawLine(0, 0, 3, 0)
+	if err != common.ErrPointOutsideCanvas {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrPointOutsideCanvas, err)
+	}
+	err = rec.DrawPolygon([]canvas.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}, false, canvas.FillRuleEvenOdd)
+	if err != common.ErrTooFewPoints {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrTooFewPoints, err)
+	}
+	if len(rec.elements) != 1 {
+		t.Errorf("Expected: 1 element, Got: %d", len(rec.elements))
+	}
+
+	// The clone is not affected by the subsequent changes
+	clone := rec.Clone().(*Recorder)
+	err = rec.DrawRect(0, 0, 2, 2)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	err = rec.Set(1, 1, bytecolor.Color('R'))
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	if len(rec.elements) != 2 || !rec.rasterOnly {
+		t.Errorf("Expected: 2 elements and rasterOnly, Got: %d, %#v", len(rec.elements), rec.rasterOnly)
+	}
+	if len(clone.elements) != 1 || clone.rasterOnly {
+		t.Errorf("Expected: 1 element and !rasterOnly, Got: %d, %#v", len(clone.elements), clone.rasterOnly)
+	}
+	c, err = clone.At(1, 1)
+	if err != nil || c != bytecolor.Color(' ') {
+		t.Errorf("Expected: %#v, Got: %#v, %#v", bytecolor.Color(' '), c, err)
+	}
+
+	// The dimensions of the clone are not affected by the resize operations
+	err = rec.Extend(1, 0, 0, 0)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	err = rec.Crop(0, 0, 3, 0)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	err = rec.Scale(2, 1)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	if width, height := rec.Dimensions(); width != 2 || height != 1 {
+		t.Errorf("Expected: 2 x 1, Got: %d x %d", width, height)
+	}
+	if width, height := clone.Dimensions(); width != 3 || height != 3 {
+		t.Errorf("Expected: 3 x 3, Got: %d x %d", width, height)
+	}
+	err = rec.Transform(canvas.TransformRotate90)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	if width, height := rec.Dimensions(); width != 1 || height != 2 {
+		t.Errorf("Expected: 1 x 2, Got: %d x %d", width, height)
+	}
+
+	// At and Set are not supported by non-buffer-based canvases
+	rec, err = NewRecorder(nonBufferBasedCanvas{cnv})
+	if err != nil {
+		panic(err)
+	}
+	_, err = rec.At(0, 0)
+	if err != common.ErrCanvasNotSupported {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrCanvasNotSupported, err)
+	}
+	err = rec.Set(0, 0, bytecolor.Color('x'))
+	if err != common.ErrCanvasNotSupported {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrCanvasNotSupported, err)
+	}
+
+	// Transform and TransformRect are not supported by non-transformable canvases
+	err = rec.Transform(canvas.TransformFlipHorizontal)
+	if err != common.ErrCanvasNotSupported {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrCanvasNotSupported, err)
+	}
+	err = rec.TransformRect(0, 0, 0, 0, canvas.TransformFlipHorizontal)
+	if err != common.ErrCanvasNotSupported {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrCanvasNotSupported, err)
+	}
+}
@@ -0,0 +1,192 @@
+// Package svg defines the Renderer type,
+// which implements the renderer.Renderer interface,
+// and the Recorder type,
+// which implements the canvas.BufferBasedCanvas interface.
+package svg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/common"
+	"github.com/asukakenji/drawing-challenge/renderer"
+)
+
+// Renderer is a renderer encoding canvases to SVG documents.
+// It implements the renderer.Renderer interface.
+type Renderer struct {
+	writer  io.Writer
+	palette bytecolor.Palette
+	scale   int
+}
+
+// Ensure that Renderer implements the renderer.Renderer interface.
+var (
+	_ renderer.Renderer = &Renderer{}
+)
+
+// NewRenderer returns a new Renderer.
+// The SVG documents are written to writer.
+// The byte colors are mapped to RGBA colors by palette.
+// The width and height of the documents are scale times
+// those of the canvases.
+//
+// Errors
+//
+// common.ErrNilPointer:
+// Will be returned if writer == nil or palette == nil.
+//
+// common.ErrScaleNotPositive:
+// Will be returned if scale <= 0.
+//
+func NewRenderer(writer io.Writer, palette bytecolor.Palette, scale int) (*Renderer, error) {
+	if writer == nil {
+		return nil, common.ErrNilPointer
+	}
+	if palette == nil {
+		return nil, common.ErrNilPointer
+	}
+	if scale <= 0 {
+		return nil, common.ErrScaleNotPositive
+	}
+	return &Renderer{
+		writer:  writer,
+		palette: palette,
+		scale:   scale,
+	}, nil
+}
+
+// Render renders cnv.
+//
+// If cnv is a Recorder, the recorded operations are rendered as
+// <line>, <rect>, <ellipse>, <polyline>, and <polygon> elements,
+// unless any of them could not be represented as vector elements.
+// Otherwise, cnv must implement the canvas.BufferBasedCanvas interface,
+// and each run of horizontally adjacent pixels of the same color
+// is rendered as a <rect> element.
+// Runs at the same position of adjacent rows are merged.
+//
+// The pixels of cnv must be bytecolor.Color in the palette,
+// or implement the image/color.Color interface.
+// Fully transparent pixels are not drawn.
+//
+// Errors
+//
+// common.ErrCanvasNotSupported:
+// Will be returned if cnv is not supported by this renderer.
+//
+// common.ErrColorNotSupported:
+// Will be returned if a color inside cnv is not supported by this renderer.
+//
+// Errors returned from the writer are returned without modifications.
+//
+func (rdr *Renderer) Render(cnv canvas.Canvas) error {
+	width, height := 0, 0
+	// NOTE: The output is buffered, so that nothing is written on errors
+	body := new(bytes.Buffer)
+	if rec, ok := cnv.(*Recorder); ok && !rec.rasterOnly {
+		width, height = rec.Dimensions()
+		if bbcnv, ok := rec.base.(canvas.BufferBasedCanvas); ok {
+			err := rdr.renderRuns(body, bbcnv)
+			if err != nil {
+				return err
+			}
+		}
+		for _, e := range rec.elements {
+			err := rdr.renderElement(body, e)
+			if err != nil {
+				return err
+			}
+		}
+	} else {
+		bbcnv, ok := cnv.(canvas.BufferBasedCanvas)
+		if !ok {
+			return common.ErrCanvasNotSupported
+		}
+		width, height = bbcnv.Dimensions()
+		err := rdr.renderRuns(body, bbcnv)
+		if err != nil {
+			return err
+		}
+	}
+	buf := new(bytes.Buffer)
+	fmt.Fprintln(buf, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width*rdr.scale, height*rdr.scale, width, height)
+	buf.Write(body.Bytes())
+	fmt.Fprintln(buf, `</svg>`)
+	_, err := rdr.writer.Write(buf.Bytes())
+	return err
+}
+
+// renderRuns renders the pixels of cnv as <rect> elements.
+func (rdr *Renderer) renderRuns(buf *bytes.Buffer, cnv canvas.BufferBasedCanvas) error {
+	rects, err := mergeRuns(cnv, rdr.palette)
+	if err != nil {
+		return err
+	}
+	if len(rects) == 0 {
+		return nil
+	}
+	fmt.Fprintln(buf, `<g shape-rendering="crispEdges">`)
+	for _, r := range rects {
+		fmt.Fprintf(buf, `<rect x="%d" y="%d" width="%d" height="%d"%s/>`+"\n", r.x, r.y, r.width, r.height, paint("fill", r.c))
+	}
+	fmt.Fprintln(buf, `</g>`)
+	return nil
+}
+
+// renderElement renders e.
+// The coordinates are shifted by half a pixel,
+// so that the strokes lie on the centers of the pixels.
+func (rdr *Renderer) renderElement(buf *bytes.Buffer, e element) error {
+	stroke, err := rdr.palette.ToRGBA(e.stroke)
+	if err != nil {
+		return err
+	}
+	fill := ` fill="none"`
+	if e.fill != nil {
+		c, err := rdr.palette.ToRGBA(e.fill)
+		if err != nil {
+			return err
+		}
+		fill = paint("fill", c)
+	}
+	strokeAttrs := paint("stroke", stroke) + ` stroke-width="1" stroke-linecap="square"`
+	switch e.kind {
+	case elementLine:
+		p1, p2 := e.points[0], e.points[1]
+		fmt.Fprintf(buf, `<line x1="%s" y1="%s" x2="%s" y2="%s"%s/>`+"\n", center(p1.X), center(p1.Y), center(p2.X), center(p2.Y), strokeAttrs)
+	case elementRect:
+		x1, x2 := minMax(e.points[0].X, e.points[1].X)
+		y1, y2 := minMax(e.points[0].Y, e.points[1].Y)
+		if e.fill != nil {
+			fmt.Fprintf(buf, `<rect x="%d" y="%d" width="%d" height="%d"%s/>`+"\n", x1, y1, x2-x1+1, y2-y1+1, fill)
+		}
+		if x1 == x2 || y1 == y2 {
+			// NOTE: A rect with zero width or height is not rendered
+			fmt.Fprintf(buf, `<line x1="%s" y1="%s" x2="%s" y2="%s"%s/>`+"\n", center(x1), center(y1), center(x2), center(y2), strokeAttrs)
+		} else {
+			fmt.Fprintf(buf, `<rect x="%s" y="%s" width="%d" height="%d" fill="none"%s/>`+"\n", center(x1), center(y1), x2-x1, y2-y1, strokeAttrs)
+		}
+	case elementEllipse:
+		p := e.points[0]
+		if e.rx == 0 || e.ry == 0 {
+			// NOTE: An ellipse with zero radius is not rendered
+			fmt.Fprintf(buf, `<line x1="%s" y1="%s" x2="%s" y2="%s"%s/>`+"\n", center(p.X-e.rx), center(p.Y-e.ry), center(p.X+e.rx), center(p.Y+e.ry), strokeAttrs)
+		} else {
+			fmt.Fprintf(buf, `<ellipse cx="%s" cy="%s" rx="%d" ry="%d"%s%s/>`+"\n", center(p.X), center(p.Y), e.rx, e.ry, fill, strokeAttrs)
+		}
+	case elementPolyline:
+		fmt.Fprintf(buf, `<polyline points="%s" fill="none"%s/>`+"\n", pointsAttr(e.points), strokeAttrs)
+	case elementPolygon:
+		rule := "evenodd"
+		if e.rule == canvas.FillRuleNonZero {
+			rule = "nonzero"
+		}
+		fmt.Fprintf(buf, `<polygon points="%s"%s fill-rule="%s"%s/>`+"\n", pointsAttr(e.points), fill, rule, strokeAttrs)
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package svg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	bc "github.com/asukakenji/drawing-challenge/canvas/bytecolor"
+	rc "github.com/asukakenji/drawing-challenge/canvas/rgba"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/color/rgba"
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+const (
+	header = `<?xml version="1.0" encoding="UTF-8"?>` + "\n"
+	footer = `</svg>` + "\n"
+)
+
+func TestNewRenderer(t *testing.T) {
+	_, err := NewRenderer(new(bytes.Buffer), bytecolor.DefaultPalette(), 1)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+
+	casesNeg := []struct {
+		writer  io.Writer
+		palette bytecolor.Palette
+		scale   int
+		err     error
+	}{
+		{nil, bytecolor.DefaultPalette(), 1, common.ErrNilPointer},
+		{new(bytes.Buffer), nil, 1, common.ErrNilPointer},
+		{new(bytes.Buffer), bytecolor.DefaultPalette(), 0, common.ErrScaleNotPositive},
+		{new(bytes.Buffer), bytecolor.DefaultPalette(), -1, common.ErrScaleNotPositive},
+	}
+	for _, c := range casesNeg {
+		_, err := NewRenderer(c.writer, c.palette, c.scale)
+		if err != c.err {
+			t.Errorf("Case: (%#v, %d), Expected: %#v, Got: %#v", c.palette == nil, c.scale, c.err, err)
+		}
+	}
+}
+
+func TestRenderer_Render(t *testing.T) {
+	cnv, err := bc.NewBuffer(4, 4, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.DrawLine(0, 1, 2, 1)
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.DrawLine(3, 2, 3, 3)
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.Set(1, 3, bytecolor.Color('R'))
+	if err != nil {
+		panic(err)
+	}
+
+	writer := new(bytes.Buffer)
+	renderer, err := NewRenderer(writer, bytecolor.DefaultPalette(), 10)
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	expected := header +
+		`<svg xmlns="http://www.w3.org/2000/svg" width="40" height="40" viewBox="0 0 4 4">` + "\n" +
+		`<g shape-rendering="crispEdges">` + "\n" +
+		`<rect x="0" y="0" width="4" height="1" fill="#ffffff"/>` + "\n" +
+		`<rect x="0" y="1" width="3" height="1" fill="#000000"/>` + "\n" +
+		`<rect x="3" y="1" width="1" height="1" fill="#ffffff"/>` + "\n" +
+		`<rect x="0" y="2" width="3" height="1" fill="#ffffff"/>` + "\n" +
+		`<rect x="3" y="2" width="1" height="2" fill="#000000"/>` + "\n" +
+		`<rect x="0" y="3" width="1" height="1" fill="#ffffff"/>` + "\n" +
+		`<rect x="1" y="3" width="1" height="1" fill="#ff0000"/>` + "\n" +
+		`<rect x="2" y="3" width="1" height="1" fill="#ffffff"/>` + "\n" +
+		`</g>` + "\n" +
+		footer
+	if writer.String() != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, writer.String())
+	}
+}
+
+func TestRenderer_Render_RGBA(t *testing.T) {
+	transparent := rgba.Color{R: 0xff, G: 0xff, B: 0xff, A: 0x00}
+	translucent := rgba.Color{R: 0xff, G: 0x80, B: 0x00, A: 0x80}
+	cnv, err := rc.NewBuffer(3, 2, transparent, translucent)
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.DrawLine(1, 0, 1, 1)
+	if err != nil {
+		panic(err)
+	}
+
+	writer := new(bytes.Buffer)
+	renderer, err := NewRenderer(writer, bytecolor.Palette{}, 1)
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	expected := header +
+		`<svg xmlns="http://www.w3.org/2000/svg" width="3" height="2" viewBox="0 0 3 2">` + "\n" +
+		`<g shape-rendering="crispEdges">` + "\n" +
+		`<rect x="1" y="0" width="1" height="2" fill="#ff7f00" fill-opacity="0.502"/>` + "\n" +
+		`</g>` + "\n" +
+		footer
+	if writer.String() != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, writer.String())
+	}
+}
+
+func TestRenderer_Render_Recorder(t *testing.T) {
+	cnv, err := bc.NewBuffer(5, 5, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+	rec, err := NewRecorder(cnv)
+	if err != nil {
+		panic(err)
+	}
+	err = rec.DrawLine(0, 0, 4, 0)
+	if err != nil {
+		panic(err)
+	}
+	err = rec.DrawRect(3, 3, 1, 1)
+	if err != nil {
+		panic(err)
+	}
+	err = rec.SetForegroundColor(bytecolor.Color('B'))
+	if err != nil {
+		panic(err)
+	}
+	err = rec.FillRect(0, 4, 4, 4, nil, bytecolor.Color('R'))
+	if err != nil {
+		panic(err)
+	}
+	err = rec.DrawCircle(2, 2, 2, true)
+	if err != nil {
+		panic(err)
+	}
+	err = rec.DrawEllipse(2, 2, 2, 0, false)
+	if err != nil {
+		panic(err)
+	}
+	err = rec.DrawPolyline([]canvas.Point{{X: 0, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}})
+	if err != nil {
+		panic(err)
+	}
+	err = rec.DrawPolygon([]canvas.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 2, Y: 4}}, true, canvas.FillRuleNonZero)
+	if err != nil {
+		panic(err)
+	}
+
+	writer := new(bytes.Buffer)
+	renderer, err := NewRenderer(writer, bytecolor.DefaultPalette(), 1)
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(rec)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	expected := header +
+		`<svg xmlns="http://www.w3.org/2000/svg" width="5" height="5" viewBox="0 0 5 5">` + "\n" +
+		`<g shape-rendering="crispEdges">` + "\n" +
+		`<rect x="0" y="0" width="5" height="5" fill="#ffffff"/>` + "\n" +
+		`</g>` + "\n" +
+		`<line x1="0.5" y1="0.5" x2="4.5" y2="0.5" stroke="#000000" stroke-width="1" stroke-linecap="square"/>` + "\n" +
+		`<rect x="1.5" y="1.5" width="2" height="2" fill="none" stroke="#000000" stroke-width="1" stroke-linecap="square"/>` + "\n" +
+		`<rect x="0" y="4" width="5" height="1" fill="#ff0000"/>` + "\n" +
+		`<line x1="0.5" y1="4.5" x2="4.5" y2="4.5" stroke="#0000ff" stroke-width="1" stroke-linecap="square"/>` + "\n" +
+		`<ellipse cx="2.5" cy="2.5" rx="2" ry="2" fill="#0000ff" stroke="#0000ff" stroke-width="1" stroke-linecap="square"/>` + "\n" +
+		`<line x1="0.5" y1="2.5" x2="4.5" y2="2.5" stroke="#0000ff" stroke-width="1" stroke-linecap="square"/>` + "\n" +
+		`<polyline points="0.5,0.5 4.5,4.5 0.5,4.5" fill="none" stroke="#0000ff" stroke-width="1" stroke-linecap="square"/>` + "\n" +
+		`<polygon points="0.5,0.5 4.5,0.5 2.5,4.5" fill="#0000ff" fill-rule="nonzero" stroke="#0000ff" stroke-width="1" stroke-linecap="square"/>` + "\n" +
+		footer
+	if writer.String() != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, writer.String())
+	}
+
+	// Falls back to the pixels once an operation could not be recorded
+	err = rec.BucketFill(0, 1, bytecolor.Color('G'))
+	if err != nil {
+		panic(err)
+	}
+	writer.Reset()
+	err = renderer.Render(rec)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	writer2 := new(bytes.Buffer)
+	renderer2, err := NewRenderer(writer2, bytecolor.DefaultPalette(), 1)
+	if err != nil {
+		panic(err)
+	}
+	err = renderer2.Render(cnv)
+	if err != nil {
+		panic(err)
+	}
+	if writer.String() != writer2.String() {
+		t.Errorf("Expected: %q, Got: %q", writer2.String(), writer.String())
+	}
+}
+
+// This type is created for testing purpose only
+type errWriter struct{}
+
+var errWrite = errors.New("write error")
+
+func (w errWriter) Write(p []byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestRenderer_Render_Neg(t *testing.T) {
+	cnv, err := bc.NewBuffer(3, 2, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+
+	writer := new(bytes.Buffer)
+	renderer, err := NewRenderer(writer, bytecolor.DefaultPalette(), 1)
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(nil)
+	if err != common.ErrCanvasNotSupported {
+		t.Errorf("Expected: err == %#v, Got: %#v", common.ErrCanvasNotSupported, err)
+	}
+
+	renderer, err = NewRenderer(writer, bytecolor.Palette{}, 1)
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != common.ErrColorNotSupported {
+		t.Errorf("Expected: err == %#v, Got: %#v", common.ErrColorNotSupported, err)
+	}
+	rec, err := NewRecorder(cnv)
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(rec)
+	if err != common.ErrColorNotSupported {
+		t.Errorf("Expected: err == %#v, Got: %#v", common.ErrColorNotSupported, err)
+	}
+	if writer.Len() != 0 {
+		t.Errorf("Expected: nothing written, Got: %q", writer.String())
+	}
+
+	renderer, err = NewRenderer(errWriter{}, bytecolor.DefaultPalette(), 1)
+	if err != nil {
+		panic(err)
+	}
+	err = renderer.Render(cnv)
+	if err != errWrite {
+		t.Errorf("Expected: err == %#v, Got: %#v", errWrite, err)
+	}
+}
@@ -85,7 +85,7 @@ func (rdr *Renderer) Render(cnv canvas.Canvas) error {
 			ToByte() byte
 		}
 		for j := 0; j < height; j++ {
-			fmt.Fprint(rdr.writer, '|')
+			fmt.Fprint(rdr.writer, "|")
 			for i := 0; i < width; i++ {
 				c, err := bbcnv.At(i, j)
 				if err != nil {
@@ -93,14 +93,14 @@ func (rdr *Renderer) Render(cnv canvas.Canvas) error {
 					panic(err)
 				}
 				if c2, ok := c.(bytecolor.Color); ok {
-					fmt.Fprint(rdr.writer, c2)
+					fmt.Fprintf(rdr.writer, "%c", byte(c2))
 				} else if c3, ok := c.(toByter); ok {
-					fmt.Fprint(rdr.writer, c3.ToByte())
+					fmt.Fprintf(rdr.writer, "%c", c3.ToByte())
 				} else {
 					return common.ErrColorNotSupported
 				}
 			}
-			fmt.Fprintln(rdr.writer, '|')
+			fmt.Fprintln(rdr.writer, "|")
 		}
 	}
 	rdr.renderTopBottomBorder(width)
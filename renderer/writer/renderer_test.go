@@ -6,10 +6,12 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/asukakenji/drawing-challenge/canvas"
 	bc "github.com/asukakenji/drawing-challenge/canvas/bytecolor"
 	"github.com/asukakenji/drawing-challenge/color"
 	"github.com/asukakenji/drawing-challenge/color/bytecolor"
 	"github.com/asukakenji/drawing-challenge/common"
+	"github.com/asukakenji/drawing-challenge/renderer/svg"
 )
 
 func TestNewRenderer(t *testing.T) {
@@ -100,6 +102,22 @@ func (dc dummyCanvas) Dimensions() (int, int) {
 	return 0, 0
 }
 
+func (dc dummyCanvas) Clone() canvas.Canvas {
+	return dc
+}
+
+func (dc dummyCanvas) ForegroundColor() color.Color {
+	return nil
+}
+
+func (dc dummyCanvas) SetForegroundColor(c color.Color) error {
+	return nil
+}
+
+func (dc dummyCanvas) SetLineMode(mode canvas.LineMode) error {
+	return nil
+}
+
 func (dc dummyCanvas) DrawLine(x1, y1, x2, y2 int) error {
 	return nil
 }
@@ -108,10 +126,42 @@ func (dc dummyCanvas) DrawRect(x1, y1, x2, y2 int) error {
 	return nil
 }
 
+func (dc dummyCanvas) FillRect(x1, y1, x2, y2 int, stroke, fill color.Color) error {
+	return nil
+}
+
+func (dc dummyCanvas) DrawCircle(xc, yc, r int, filled bool) error {
+	return nil
+}
+
+func (dc dummyCanvas) DrawEllipse(xc, yc, rx, ry int, filled bool) error {
+	return nil
+}
+
+func (dc dummyCanvas) DrawPolyline(points []canvas.Point) error {
+	return nil
+}
+
+func (dc dummyCanvas) DrawPolygon(points []canvas.Point, filled bool, rule canvas.FillRule) error {
+	return nil
+}
+
+func (dc dummyCanvas) SetTextMode(mode canvas.TextMode) error {
+	return nil
+}
+
+func (dc dummyCanvas) DrawText(x, y int, text string) error {
+	return nil
+}
+
 func (dc dummyCanvas) BucketFill(x, y int, c color.Color) error {
 	return nil
 }
 
+func (dc dummyCanvas) FloodFill(x, y int, c color.Color, opts canvas.FillOptions) error {
+	return nil
+}
+
 func TestRenderer_Render_2(t *testing.T) {
 	cnv := dummyCanvas(0)
 
@@ -158,6 +208,23 @@ func (abbc *anotherBufferBasedCanvas) Dimensions() (int, int) {
 	return abbc.width, abbc.height
 }
 
+func (abbc *anotherBufferBasedCanvas) Clone() canvas.Canvas {
+	clone := *abbc
+	return &clone
+}
+
+func (abbc *anotherBufferBasedCanvas) ForegroundColor() color.Color {
+	return nil
+}
+
+func (abbc *anotherBufferBasedCanvas) SetForegroundColor(c color.Color) error {
+	return nil
+}
+
+func (abbc *anotherBufferBasedCanvas) SetLineMode(mode canvas.LineMode) error {
+	return nil
+}
+
 func (abbc *anotherBufferBasedCanvas) DrawLine(x1, y1, x2, y2 int) error {
 	return nil
 }
@@ -166,10 +233,42 @@ func (abbc *anotherBufferBasedCanvas) DrawRect(x1, y1, x2, y2 int) error {
 	return nil
 }
 
+func (abbc *anotherBufferBasedCanvas) FillRect(x1, y1, x2, y2 int, stroke, fill color.Color) error {
+	return nil
+}
+
+func (abbc *anotherBufferBasedCanvas) DrawCircle(xc, yc, r int, filled bool) error {
+	return nil
+}
+
+func (abbc *anotherBufferBasedCanvas) DrawEllipse(xc, yc, rx, ry int, filled bool) error {
+	return nil
+}
+
+func (abbc *anotherBufferBasedCanvas) DrawPolyline(points []canvas.Point) error {
+	return nil
+}
+
+func (abbc *anotherBufferBasedCanvas) DrawPolygon(points []canvas.Point, filled bool, rule canvas.FillRule) error {
+	return nil
+}
+
+func (abbc *anotherBufferBasedCanvas) SetTextMode(mode canvas.TextMode) error {
+	return nil
+}
+
+func (abbc *anotherBufferBasedCanvas) DrawText(x, y int, text string) error {
+	return nil
+}
+
 func (abbc *anotherBufferBasedCanvas) BucketFill(x, y int, c color.Color) error {
 	return nil
 }
 
+func (abbc *anotherBufferBasedCanvas) FloodFill(x, y int, c color.Color, opts canvas.FillOptions) error {
+	return nil
+}
+
 func (abbc *anotherBufferBasedCanvas) At(x, y int) (color.Color, error) {
 	b := abbc.pixels[y*abbc.width+x]
 	switch abbc.mode {
@@ -202,16 +301,32 @@ func TestRenderer_Render_3(t *testing.T) {
 		panic(err)
 	}
 
+	expectedBytes := ([]byte)("-----\n|   |\n|   |\n-----\n\n")
+
 	err = renderer.Render(cnv)
 	if err != nil {
 		t.Errorf("Expected: err == nil, Got: %#v", err)
 	}
+	// The side borders are printed as characters, not as numbers
+	lines := bytes.Split(writer.Bytes(), []byte("\n"))
+	for _, line := range lines[1:3] {
+		if !bytes.HasPrefix(line, []byte("|")) || !bytes.HasSuffix(line, []byte("|")) {
+			t.Errorf("Expected: %q, Got: %q", "|...|", line)
+		}
+	}
+	if !reflect.DeepEqual(writer.Bytes(), expectedBytes) {
+		t.Errorf("Expected: %#v, Got: %#v", expectedBytes, writer.Bytes())
+	}
 
+	writer.Reset()
 	cnv.mode = 1
 	err = renderer.Render(cnv)
 	if err != nil {
 		t.Errorf("Expected: err == nil, Got: %#v", err)
 	}
+	if !reflect.DeepEqual(writer.Bytes(), expectedBytes) {
+		t.Errorf("Expected: %#v, Got: %#v", expectedBytes, writer.Bytes())
+	}
 
 	cnv.mode = 2
 	err = renderer.Render(cnv)
@@ -227,3 +342,33 @@ func TestRenderer_Render_3(t *testing.T) {
 	}()
 	renderer.Render(cnv)
 }
+
+func TestRenderer_Render_Recorder(t *testing.T) {
+	buf, err := bc.NewBuffer(4, 2, bytecolor.Color(' '), bytecolor.Color('x'))
+	if err != nil {
+		panic(err)
+	}
+	cnv, err := svg.NewRecorder(buf)
+	if err != nil {
+		panic(err)
+	}
+	err = cnv.DrawLine(0, 0, 3, 0)
+	if err != nil {
+		panic(err)
+	}
+
+	writer := new(bytes.Buffer)
+	renderer, err := NewRenderer(writer)
+	if err != nil {
+		panic(err)
+	}
+
+	err = renderer.Render(cnv)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	expectedBytes := ([]byte)("------\n|xxxx|\n|    |\n------\n\n")
+	if !reflect.DeepEqual(writer.Bytes(), expectedBytes) {
+		t.Errorf("Expected: %#v, Got: %#v", expectedBytes, writer.Bytes())
+	}
+}
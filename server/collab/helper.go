@@ -0,0 +1,164 @@
+package collab
+
+import (
+	"fmt"
+	imagecolor "image/color"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	bc "github.com/asukakenji/drawing-challenge/canvas/bytecolor"
+	rc "github.com/asukakenji/drawing-challenge/canvas/rgba"
+	"github.com/asukakenji/drawing-challenge/color"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/renderer"
+)
+
+// discardRenderer is the renderer of the environment of the hub.
+// The canvas is not rendered after each command,
+// since the changed regions are sent to the clients instead.
+type discardRenderer struct{}
+
+// Ensure that discardRenderer implements the renderer.Renderer interface.
+var (
+	_ renderer.Renderer = discardRenderer{}
+)
+
+// Render does nothing.
+func (rdr discardRenderer) Render(cnv canvas.Canvas) error {
+	return nil
+}
+
+// sameDimensions returns whether cnv1 and cnv2 have the same dimensions.
+func sameDimensions(cnv1, cnv2 canvas.Canvas) bool {
+	width1, height1 := cnv1.Dimensions()
+	width2, height2 := cnv2.Dimensions()
+	return width1 == width2 && height1 == height2
+}
+
+// changedRegion returns the smallest region containing all pixels
+// which are different in before and after, which have the same dimensions.
+// width == 0 is returned if there is no difference.
+// The whole canvas is returned if the pixels could not be compared.
+func changedRegion(before, after canvas.Canvas) (x, y, width, height int) {
+	w, h := after.Dimensions()
+	changed, ok := pixelChanged(before, after)
+	if !ok {
+		return 0, 0, w, h
+	}
+	minX, minY, maxX, maxY := w, h, -1, -1
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			if !changed(j*w + i) {
+				continue
+			}
+			if i < minX {
+				minX = i
+			}
+			if i > maxX {
+				maxX = i
+			}
+			if j < minY {
+				minY = j
+			}
+			if j > maxY {
+				maxY = j
+			}
+		}
+	}
+	if maxX < 0 {
+		return 0, 0, 0, 0
+	}
+	return minX, minY, maxX - minX + 1, maxY - minY + 1
+}
+
+// pixelChanged returns a function which returns whether the pixel
+// at the index (y * width + x) is different in before and after,
+// which have the same dimensions.
+// false is returned if the pixels could not be compared.
+func pixelChanged(before, after canvas.Canvas) (func(index int) bool, bool) {
+	switch b := before.(type) {
+	case *bc.Buffer:
+		if a, ok := after.(*bc.Buffer); ok {
+			// Read the pixels without calling At for each of them if they are bytecolor buffers.
+			pixels1, pixels2 := b.Pixels(), a.Pixels()
+			return func(index int) bool {
+				return pixels1[index] != pixels2[index]
+			}, true
+		}
+	case *rc.Buffer:
+		if a, ok := after.(*rc.Buffer); ok {
+			// Read the pixels without calling At for each of them if they are RGBA buffers.
+			pixels1, pixels2 := b.Pixels(), a.Pixels()
+			return func(index int) bool {
+				return pixels1[index] != pixels2[index]
+			}, true
+		}
+	}
+	bbBefore, ok1 := before.(canvas.BufferBasedCanvas)
+	bbAfter, ok2 := after.(canvas.BufferBasedCanvas)
+	if !ok1 || !ok2 {
+		return nil, false
+	}
+	width, _ := after.Dimensions()
+	return func(index int) bool {
+		c1, _ := bbBefore.At(index%width, index/width)
+		c2, _ := bbAfter.At(index%width, index/width)
+		return c1 != c2
+	}, true
+}
+
+// unsupportedHex is the form of the colors not supported by the palette.
+const unsupportedHex = "#00000000"
+
+// hexPixels returns the colors of the pixels inside the region of cnv
+// with top-left corner (x, y), width and height,
+// in the form returned by hex.
+// The colors not supported by palette are returned as unsupportedHex,
+// so that the region is always complete.
+//
+// Errors
+//
+// common.ErrColorNotSupported:
+// Will be returned, together with the pixels,
+// if a color inside the region is not supported by palette.
+//
+func hexPixels(cnv canvas.BufferBasedCanvas, palette bytecolor.Palette, x, y, width, height int) ([][]string, error) {
+	var result error
+	// NOTE: The canvases have few distinct colors,
+	// so each of them is converted once only
+	hexes := map[color.Color]string{}
+	pixels := make([][]string, height)
+	for j := range pixels {
+		row := make([]string, width)
+		for i := range row {
+			c, err := cnv.At(x+i, y+j)
+			if err != nil {
+				// NOTE: This should not happen since the region is inside the canvas
+				panic(err)
+			}
+			h, ok := hexes[c]
+			if !ok {
+				rgba, err := palette.ToRGBA(c)
+				if err != nil {
+					row[i] = unsupportedHex
+					result = err
+					continue
+				}
+				h = hex(rgba)
+				hexes[c] = h
+			}
+			row[i] = h
+		}
+		pixels[j] = row
+	}
+	return pixels, result
+}
+
+// hex returns c in the form "#RRGGBB" if it is opaque,
+// or "#RRGGBBAA" otherwise.
+func hex(c imagecolor.RGBA) string {
+	nrgba := imagecolor.NRGBAModel.Convert(c).(imagecolor.NRGBA)
+	if nrgba.A == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", nrgba.R, nrgba.G, nrgba.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", nrgba.R, nrgba.G, nrgba.B, nrgba.A)
+}
@@ -0,0 +1,413 @@
+// Package collab defines the Hub type,
+// which shares a canvas among multiple WebSocket clients.
+//
+// The clients send text messages containing commands, one command per line,
+// in the same syntax as those entered in the console.
+// The commands from all clients are interpreted in a single serialized order.
+// The hub sends the following JSON messages to the clients:
+//
+//     {"type":"canvas","seq":n,"x":0,"y":0,"width":w,"height":h,"pixels":[...]}
+//     {"type":"update","seq":n,"x":x,"y":y,"width":w,"height":h,"pixels":[...]}
+//     {"type":"error","line":k,"error":"..."}
+//
+// A "canvas" message contains the whole canvas. It is sent to a client
+// when it attaches, and to all clients when a canvas is created.
+// An "update" message contains the region changed by a command.
+// It is sent to all clients. seq is the number of commands which have
+// changed the canvas, so that the clients could check the order.
+// pixels contains the rows of the region, and each pixel is in the form
+// "#RRGGBB" if it is opaque, or "#RRGGBBAA" otherwise.
+// A color not supported by the palette is sent as "#00000000",
+// and an "error" message is sent to the client changing it.
+// An "error" message is sent to the client sending the failed command only.
+// The remaining commands in the same message are not interpreted.
+//
+// The quit command detaches the client sending it.
+// A client is detached if it sends nothing, not even a pong frame,
+// within websocket.DefaultReadTimeout.
+package collab
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/command"
+	"github.com/asukakenji/drawing-challenge/command/basic"
+	"github.com/asukakenji/drawing-challenge/common"
+	"github.com/asukakenji/drawing-challenge/interpreter"
+	"github.com/asukakenji/drawing-challenge/interpreter/simple"
+	"github.com/asukakenji/drawing-challenge/server/websocket"
+)
+
+const (
+	// SendQueueSize is the maximum number of messages queued for a client.
+	// A client is detached if it could not keep up with the updates.
+	SendQueueSize = 256
+
+	// PingPeriod is the period of pinging the clients.
+	// It is shorter than websocket.DefaultReadTimeout,
+	// so that the idle clients answering the pings are not detached.
+	PingPeriod = websocket.DefaultReadTimeout / 2
+
+	// DefaultMaxCanvasWidth is the default maximum width of the canvas.
+	DefaultMaxCanvasWidth = 1000
+
+	// DefaultMaxCanvasHeight is the default maximum height of the canvas.
+	DefaultMaxCanvasHeight = 1000
+)
+
+// client is a WebSocket client attached to the hub.
+type client struct {
+	conn *websocket.Conn
+	send chan string
+}
+
+// region is the content of a "canvas" or "update" message.
+type region struct {
+	Type   string     `json:"type"`
+	Seq    int        `json:"seq"`
+	X      int        `json:"x"`
+	Y      int        `json:"y"`
+	Width  int        `json:"width"`
+	Height int        `json:"height"`
+	Pixels [][]string `json:"pixels"`
+}
+
+// failure is the content of an "error" message.
+type failure struct {
+	Type  string `json:"type"`
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// Hub is a hub sharing a canvas among multiple WebSocket clients.
+// It implements the net/http.Handler interface.
+type Hub struct {
+	parser  command.Parser
+	interp  interpreter.Interpreter
+	palette bytecolor.Palette
+
+	// NOTE: The mutex serializes the commands,
+	// and sendMutex serializes the messages sent.
+	// sendMutex is locked before the mutex is unlocked,
+	// so that the messages are sent in the order of the commands,
+	// while the next command is interpreted.
+	mutex     sync.Mutex
+	env       *simple.Environment
+	sendMutex sync.Mutex
+	seq       int
+	last      canvas.Canvas
+	clients   map[*client]bool
+}
+
+// Ensure that Hub implements the net/http.Handler interface.
+var (
+	_ http.Handler = &Hub{}
+)
+
+// NewHub returns a new Hub.
+// The canvas is created by newCanvasFunc.
+// The commands are parsed by parser, and interpreted by interp.
+// The byte colors are mapped to RGBA colors by palette.
+// The dimensions of the canvas are limited by DefaultMaxCanvasWidth
+// and DefaultMaxCanvasHeight until SetMaxCanvasSize is called.
+//
+// Errors
+//
+// common.ErrNilPointer:
+// Will be returned if newCanvasFunc == nil, parser == nil, interp == nil,
+// or palette == nil.
+//
+// common.ErrCanvasNotSupported:
+// Will be returned if the canvas created by newCanvasFunc
+// is not a canvas.BufferBasedCanvas.
+//
+func NewHub(newCanvasFunc func(int, int) (canvas.Canvas, error), parser command.Parser, interp interpreter.Interpreter, palette bytecolor.Palette) (*Hub, error) {
+	if newCanvasFunc == nil {
+		return nil, common.ErrNilPointer
+	}
+	if parser == nil {
+		return nil, common.ErrNilPointer
+	}
+	if interp == nil {
+		return nil, common.ErrNilPointer
+	}
+	if palette == nil {
+		return nil, common.ErrNilPointer
+	}
+	// NOTE: The regions are read from the canvas after it is changed,
+	// so the canvas is checked beforehand
+	cnv, err := newCanvasFunc(1, 1)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := cnv.(canvas.BufferBasedCanvas); !ok {
+		return nil, common.ErrCanvasNotSupported
+	}
+	env, err := simple.NewEnvironment(newCanvasFunc, discardRenderer{})
+	if err != nil {
+		return nil, err
+	}
+	err = env.SetMaxCanvasSize(DefaultMaxCanvasWidth, DefaultMaxCanvasHeight)
+	if err != nil {
+		return nil, err
+	}
+	return &Hub{
+		parser:  parser,
+		interp:  interp,
+		palette: palette,
+		env:     env,
+		clients: map[*client]bool{},
+	}, nil
+}
+
+// SetHistoryDepth sets the maximum number of changes which could be undone.
+//
+// Errors
+//
+// common.ErrHistoryDepthNegative:
+// Will be returned if depth < 0.
+//
+func (hub *Hub) SetHistoryDepth(depth int) error {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	return hub.env.SetHistoryDepth(depth)
+}
+
+// SetMaxCanvasSize limits the width and height of the canvas
+// created or resized hereafter.
+//
+// Errors
+//
+// common.ErrWidthOrHeightNotPositive:
+// Will be returned if width <= 0, or height <= 0.
+//
+func (hub *Hub) SetMaxCanvasSize(width, height int) error {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	return hub.env.SetMaxCanvasSize(width, height)
+}
+
+// ServeHTTP attaches the client of r to the hub,
+// until the connection is closed.
+func (hub *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		return
+	}
+	c := &client{
+		conn: conn,
+		send: make(chan string, SendQueueSize),
+	}
+	go c.writeLoop()
+
+	hub.sendMutex.Lock()
+	hub.clients[c] = true
+	if hub.last != nil {
+		width, height := hub.last.Dimensions()
+		message, _ := hub.region("canvas", hub.last, 0, 0, width, height)
+		if message != "" {
+			hub.sendTo(c, message)
+		}
+	}
+	hub.sendMutex.Unlock()
+
+	for {
+		message, err := conn.ReadMessage()
+		if err != nil || !hub.execute(c, message) {
+			break
+		}
+	}
+	hub.sendMutex.Lock()
+	hub.detach(c)
+	hub.sendMutex.Unlock()
+}
+
+// execute interprets the commands in message sent by c.
+// It returns false if c should be detached.
+func (hub *Hub) execute(c *client, message string) bool {
+	scanner := bufio.NewScanner(strings.NewReader(message))
+	scanner.Split(bufio.ScanLines)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		cmd, err := hub.parser.ParseCommand(scanner.Text())
+		if err == nil {
+			if _, ok := cmd.(basic.QuitCommand); ok {
+				return false
+			}
+			err = hub.interpretAndBroadcast(cmd)
+		}
+		if err != nil {
+			hub.sendMutex.Lock()
+			hub.sendTo(c, encode(failure{"error", lineNumber, err.Error()}))
+			hub.sendMutex.Unlock()
+			break
+		}
+	}
+	return true
+}
+
+// interpretAndBroadcast interprets cmd,
+// and sends the changed region to all clients.
+// The canvas is copied after cmd is interpreted,
+// and the changed region is found and encoded
+// after the next command is allowed to be interpreted.
+func (hub *Hub) interpretAndBroadcast(cmd command.Command) error {
+	hub.mutex.Lock()
+	err := hub.interp.Interpret(hub.env, cmd)
+	if err != nil {
+		hub.mutex.Unlock()
+		return err
+	}
+	var after canvas.Canvas
+	if cnv := hub.env.Canvas(); cnv != nil {
+		after = cnv.Clone()
+	}
+	hub.sendMutex.Lock()
+	hub.mutex.Unlock()
+	defer hub.sendMutex.Unlock()
+	_, created := cmd.(basic.NewCanvasCommand)
+	return hub.broadcast(after, created)
+}
+
+// broadcast sends the region changed from the last canvas to after
+// to all clients, and makes after the last canvas.
+// The whole canvas is sent if created is true.
+// The region is sent even if it could not be encoded completely,
+// so that the clients are kept in sync with the canvas.
+// It must be called with sendMutex locked.
+func (hub *Hub) broadcast(after canvas.Canvas, created bool) error {
+	before := hub.last
+	hub.last = after
+	if after == nil {
+		if before == nil {
+			return nil
+		}
+		// NOTE: The creation of the canvas is undone, so the clients are
+		// sent an empty canvas
+		hub.seq++
+		message := encode(region{"canvas", hub.seq, 0, 0, 0, 0, [][]string{}})
+		for c := range hub.clients {
+			hub.sendTo(c, message)
+		}
+		return nil
+	}
+	typ := "update"
+	x, y, width, height := 0, 0, 0, 0
+	if created || before == nil || !sameDimensions(before, after) {
+		typ = "canvas"
+		width, height = after.Dimensions()
+	} else {
+		x, y, width, height = changedRegion(before, after)
+		if width == 0 {
+			return nil
+		}
+	}
+	hub.seq++
+	message, err := hub.region(typ, after, x, y, width, height)
+	if message != "" {
+		for c := range hub.clients {
+			hub.sendTo(c, message)
+		}
+	}
+	return err
+}
+
+// region returns the encoded message of type typ containing the region
+// of cnv with top-left corner (x, y), width and height.
+// The colors not supported by the palette are encoded as "#00000000".
+// It must be called with sendMutex locked.
+//
+// Errors
+//
+// common.ErrCanvasNotSupported:
+// Will be returned, together with an empty message,
+// if cnv is not a canvas.BufferBasedCanvas.
+//
+// common.ErrColorNotSupported:
+// Will be returned, together with the message,
+// if a color inside the region is not supported by the palette.
+//
+func (hub *Hub) region(typ string, cnv canvas.Canvas, x, y, width, height int) (string, error) {
+	bbcnv, ok := cnv.(canvas.BufferBasedCanvas)
+	if !ok {
+		return "", common.ErrCanvasNotSupported
+	}
+	pixels, err := hexPixels(bbcnv, hub.palette, x, y, width, height)
+	return encode(region{typ, hub.seq, x, y, width, height, pixels}), err
+}
+
+// sendTo queues message to be sent to c.
+// c is detached if its queue is full.
+// It must be called with sendMutex locked.
+func (hub *Hub) sendTo(c *client, message string) {
+	if !hub.clients[c] {
+		return
+	}
+	select {
+	case c.send <- message:
+	default:
+		hub.detach(c)
+	}
+}
+
+// detach detaches c from the hub, and closes the connection
+// after the queued messages are sent.
+// It must be called with sendMutex locked.
+func (hub *Hub) detach(c *client) {
+	if !hub.clients[c] {
+		return
+	}
+	delete(hub.clients, c)
+	close(c.send)
+}
+
+// writeLoop sends the queued messages to the client,
+// and closes the connection when the queue is closed.
+func (c *client) writeLoop() {
+	c.writeMessages()
+	c.conn.Close()
+	// NOTE: Drain the queue, so that the hub is never blocked
+	for _ = range c.send {
+	}
+}
+
+// writeMessages sends the queued messages to the client,
+// and pings it every PingPeriod,
+// until the queue is closed or the connection fails.
+func (c *client) writeMessages() {
+	ticker := time.NewTicker(PingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case message, ok := <-c.send:
+			if !ok {
+				return
+			}
+			err := c.conn.WriteMessage(message)
+			if err != nil {
+				return
+			}
+		case <-ticker.C:
+			err := c.conn.Ping()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// encode returns v encoded in JSON.
+func encode(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		// NOTE: This should not happen since the messages contain only ints and strings
+		panic(err)
+	}
+	return string(data)
+}
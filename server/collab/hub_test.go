@@ -0,0 +1,387 @@
+package collab
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	bc "github.com/asukakenji/drawing-challenge/canvas/bytecolor"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/command/basic"
+	"github.com/asukakenji/drawing-challenge/common"
+	"github.com/asukakenji/drawing-challenge/interpreter/simple"
+	"github.com/asukakenji/drawing-challenge/server/websocket"
+)
+
+func newCanvas(width, height int) (canvas.Canvas, error) {
+	return bc.NewBuffer(width, height, bytecolor.Color(' '), bytecolor.Color('x'))
+}
+
+// plainCanvas is a canvas which is not a canvas.BufferBasedCanvas.
+type plainCanvas struct {
+	canvas.Canvas
+}
+
+func newPlainCanvas(width, height int) (canvas.Canvas, error) {
+	cnv, err := newCanvas(width, height)
+	return plainCanvas{cnv}, err
+}
+
+// wrappedCanvas is a canvas.BufferBasedCanvas which is not a buffer.
+type wrappedCanvas struct {
+	canvas.BufferBasedCanvas
+}
+
+func newTestHub() *Hub {
+	parser, err := basic.NewParser(new(bytecolor.Parser).ParseColor)
+	if err != nil {
+		panic(err)
+	}
+	interp, err := simple.NewInterpreter()
+	if err != nil {
+		panic(err)
+	}
+	hub, err := NewHub(newCanvas, parser, interp, bytecolor.DefaultPalette())
+	if err != nil {
+		panic(err)
+	}
+	return hub
+}
+
+// message is the content of any message sent by the hub.
+type message struct {
+	Type   string     `json:"type"`
+	Seq    int        `json:"seq"`
+	X      int        `json:"x"`
+	Y      int        `json:"y"`
+	Width  int        `json:"width"`
+	Height int        `json:"height"`
+	Pixels [][]string `json:"pixels"`
+	Line   int        `json:"line"`
+	Error  string     `json:"error"`
+}
+
+// dial attaches a client to ts.
+func dial(ts *httptest.Server) *websocket.Conn {
+	conn, err := websocket.Dial("ws" + strings.TrimPrefix(ts.URL, "http"))
+	if err != nil {
+		panic(err)
+	}
+	return conn
+}
+
+// receive receives a message from conn.
+func receive(conn *websocket.Conn) message {
+	data, err := conn.ReadMessage()
+	if err != nil {
+		panic(err)
+	}
+	var msg message
+	err = json.Unmarshal([]byte(data), &msg)
+	if err != nil {
+		panic(err)
+	}
+	return msg
+}
+
+func TestNewHub(t *testing.T) {
+	parser, _ := basic.NewParser(new(bytecolor.Parser).ParseColor)
+	interp, _ := simple.NewInterpreter()
+	palette := bytecolor.DefaultPalette()
+	_, err := NewHub(newCanvas, parser, interp, palette)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+
+	_, err = NewHub(nil, parser, interp, palette)
+	if err != common.ErrNilPointer {
+		t.Errorf("Case: newCanvasFunc == nil, Expected: %#v, Got: %#v", common.ErrNilPointer, err)
+	}
+	_, err = NewHub(newCanvas, nil, interp, palette)
+	if err != common.ErrNilPointer {
+		t.Errorf("Case: parser == nil, Expected: %#v, Got: %#v", common.ErrNilPointer, err)
+	}
+	_, err = NewHub(newCanvas, parser, nil, palette)
+	if err != common.ErrNilPointer {
+		t.Errorf("Case: interp == nil, Expected: %#v, Got: %#v", common.ErrNilPointer, err)
+	}
+	_, err = NewHub(newCanvas, parser, interp, nil)
+	if err != common.ErrNilPointer {
+		t.Errorf("Case: palette == nil, Expected: %#v, Got: %#v", common.ErrNilPointer, err)
+	}
+	_, err = NewHub(newPlainCanvas, parser, interp, palette)
+	if err != common.ErrCanvasNotSupported {
+		t.Errorf("Case: Not buffer based, Expected: %#v, Got: %#v", common.ErrCanvasNotSupported, err)
+	}
+}
+
+func TestHub_SetHistoryDepth(t *testing.T) {
+	hub := newTestHub()
+	err := hub.SetHistoryDepth(-1)
+	if err != common.ErrHistoryDepthNegative {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrHistoryDepthNegative, err)
+	}
+	err = hub.SetHistoryDepth(0)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+}
+
+func TestHub_SetMaxCanvasSize(t *testing.T) {
+	hub := newTestHub()
+	err := hub.SetMaxCanvasSize(0, 1)
+	if err != common.ErrWidthOrHeightNotPositive {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrWidthOrHeightNotPositive, err)
+	}
+	err = hub.SetMaxCanvasSize(4, 2)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+
+	ts := httptest.NewServer(hub)
+	defer ts.Close()
+
+	alice := dial(ts)
+	defer alice.Close()
+
+	// Canvases larger than the limit are rejected
+	alice.WriteMessage("C 5 2")
+	expected := message{Type: "error", Line: 1, Error: common.ErrCanvasTooLarge.Error()}
+	got := receive(alice)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Case: C 5 2, Expected: %#v, Got: %#v", expected, got)
+	}
+
+	// Canvases within the limit are accepted
+	alice.WriteMessage("C 4 2")
+	got = receive(alice)
+	if got.Type != "canvas" || got.Width != 4 || got.Height != 2 {
+		t.Errorf("Case: C 4 2, Expected: 4x2 canvas, Got: %#v", got)
+	}
+}
+
+func TestHub(t *testing.T) {
+	ts := httptest.NewServer(newTestHub())
+	defer ts.Close()
+
+	alice := dial(ts)
+	defer alice.Close()
+	bob := dial(ts)
+	defer bob.Close()
+
+	const k, r = "#000000", "#ff0000"
+
+	// A new canvas is sent to all clients
+	alice.WriteMessage("C 4 2")
+	expected := message{Type: "canvas", Seq: 1, Width: 4, Height: 2, Pixels: [][]string{
+		{"#ffffff", "#ffffff", "#ffffff", "#ffffff"},
+		{"#ffffff", "#ffffff", "#ffffff", "#ffffff"},
+	}}
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		got := receive(conn)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Case: C, Expected: %#v, Got: %#v", expected, got)
+		}
+	}
+
+	// Only the changed regions are sent to all clients
+	bob.WriteMessage("L 2 1 3 1\n\nB 4 2 R")
+	expecteds := []message{
+		{Type: "update", Seq: 2, X: 1, Y: 0, Width: 2, Height: 1, Pixels: [][]string{{k, k}}},
+		{Type: "update", Seq: 3, X: 0, Y: 0, Width: 4, Height: 2, Pixels: [][]string{{r, k, k, r}, {r, r, r, r}}},
+	}
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		for _, expected := range expecteds {
+			got := receive(conn)
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("Case: L, B, Expected: %#v, Got: %#v", expected, got)
+			}
+		}
+	}
+
+	// Errors are sent to the sender only, and the remaining commands are skipped
+	alice.WriteMessage("L 1 1 1 1\nL 9 9 9 9\nL 1 2 1 2")
+	expected = message{Type: "update", Seq: 4, X: 0, Y: 0, Width: 1, Height: 1, Pixels: [][]string{{k}}}
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		got := receive(conn)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Case: L, Expected: %#v, Got: %#v", expected, got)
+		}
+	}
+	expected = message{Type: "error", Line: 2, Error: common.ErrPointOutsideCanvas.Error()}
+	got := receive(alice)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Case: Error, Expected: %#v, Got: %#v", expected, got)
+	}
+
+	// Commands not changing the canvas are not sent
+	bob.WriteMessage("L 1 1 1 1\nU\nU")
+	expected = message{Type: "update", Seq: 5, X: 0, Y: 0, Width: 1, Height: 1, Pixels: [][]string{{r}}}
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		got := receive(conn)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Case: U, Expected: %#v, Got: %#v", expected, got)
+		}
+	}
+
+	// A new client receives the whole canvas
+	carol := dial(ts)
+	defer carol.Close()
+	expected = message{Type: "canvas", Seq: 5, Width: 4, Height: 2, Pixels: [][]string{{r, k, k, r}, {r, r, r, r}}}
+	got = receive(carol)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Case: Attach, Expected: %#v, Got: %#v", expected, got)
+	}
+
+	// Quitting detaches the sender only
+	carol.WriteMessage("Q")
+	_, err := carol.ReadMessage()
+	if err == nil {
+		t.Errorf("Case: Q, Expected: err != nil, Got: err == nil")
+	}
+	alice.WriteMessage("C 1 1")
+	expected = message{Type: "canvas", Seq: 6, Width: 1, Height: 1, Pixels: [][]string{{"#ffffff"}}}
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		got := receive(conn)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Case: C after Q, Expected: %#v, Got: %#v", expected, got)
+		}
+	}
+}
+
+func TestHub_UndoNewCanvas(t *testing.T) {
+	ts := httptest.NewServer(newTestHub())
+	defer ts.Close()
+
+	alice := dial(ts)
+	defer alice.Close()
+
+	// Undoing the creation of the canvas sends an empty canvas
+	alice.WriteMessage("C 1 1\nU")
+	expecteds := []message{
+		{Type: "canvas", Seq: 1, Width: 1, Height: 1, Pixels: [][]string{{"#ffffff"}}},
+		{Type: "canvas", Seq: 2, Pixels: [][]string{}},
+	}
+	for _, expected := range expecteds {
+		got := receive(alice)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Case: C, U, Expected: %#v, Got: %#v", expected, got)
+		}
+	}
+}
+
+func TestHub_ColorNotSupported(t *testing.T) {
+	parser, _ := basic.NewParser(new(bytecolor.Parser).ParseColor)
+	interp, _ := simple.NewInterpreter()
+	palette := bytecolor.DefaultPalette()
+	delete(palette, 'Z')
+	hub, _ := NewHub(newCanvas, parser, interp, palette)
+	ts := httptest.NewServer(hub)
+	defer ts.Close()
+
+	alice := dial(ts)
+	defer alice.Close()
+	bob := dial(ts)
+	defer bob.Close()
+
+	// The change is sent to all clients, even if its color is not supported
+	alice.WriteMessage("C 2 1\nB 1 1 Z\nL 1 1 1 1")
+	expecteds := []message{
+		{Type: "canvas", Seq: 1, Width: 2, Height: 1, Pixels: [][]string{{"#ffffff", "#ffffff"}}},
+		{Type: "update", Seq: 2, X: 0, Y: 0, Width: 2, Height: 1, Pixels: [][]string{{"#00000000", "#00000000"}}},
+	}
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		for _, expected := range expecteds {
+			got := receive(conn)
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("Case: C, B, Expected: %#v, Got: %#v", expected, got)
+			}
+		}
+	}
+	expected := message{Type: "error", Line: 2, Error: common.ErrColorNotSupported.Error()}
+	got := receive(alice)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Case: Error, Expected: %#v, Got: %#v", expected, got)
+	}
+
+	// A new client receives the same canvas
+	carol := dial(ts)
+	defer carol.Close()
+	expected = message{Type: "canvas", Seq: 2, Width: 2, Height: 1, Pixels: [][]string{{"#00000000", "#00000000"}}}
+	got = receive(carol)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Case: Attach, Expected: %#v, Got: %#v", expected, got)
+	}
+}
+
+func TestHub_Concurrent(t *testing.T) {
+	ts := httptest.NewServer(newTestHub())
+	defer ts.Close()
+
+	alice := dial(ts)
+	defer alice.Close()
+	bob := dial(ts)
+	defer bob.Close()
+
+	alice.WriteMessage("C 20 2")
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		receive(conn)
+	}
+
+	// The updates are sent to all clients in the same order
+	done := make(chan bool)
+	for j, conn := range []*websocket.Conn{alice, bob} {
+		go func(conn *websocket.Conn, y int) {
+			for x := 1; x <= 20; x++ {
+				conn.WriteMessage(fmt.Sprintf("L %d %d %d %d", x, y, x, y))
+			}
+			done <- true
+		}(conn, j+1)
+	}
+	<-done
+	<-done
+	var updates [2][]message
+	for i, conn := range []*websocket.Conn{alice, bob} {
+		for seq := 2; seq <= 41; seq++ {
+			got := receive(conn)
+			if got.Seq != seq {
+				t.Errorf("Case: Concurrent, Expected: seq == %d, Got: %#v", seq, got)
+			}
+			updates[i] = append(updates[i], got)
+		}
+	}
+	if !reflect.DeepEqual(updates[0], updates[1]) {
+		t.Errorf("Case: Concurrent, Expected: the same updates, Got: %#v, %#v", updates[0], updates[1])
+	}
+}
+
+func TestChangedRegion(t *testing.T) {
+	cnv, _ := newCanvas(5, 4)
+	cases := []struct {
+		name   string
+		before canvas.BufferBasedCanvas
+	}{
+		{"Buffer", cnv.(canvas.BufferBasedCanvas)},
+		{"Wrapped", wrappedCanvas{cnv.(canvas.BufferBasedCanvas)}},
+	}
+	for _, c := range cases {
+		after := c.before.Clone().(canvas.BufferBasedCanvas)
+		if _, ok := c.before.(wrappedCanvas); ok {
+			after = wrappedCanvas{after}
+		}
+		x, y, width, height := changedRegion(c.before, after)
+		if width != 0 {
+			t.Errorf("Case: %s, Unchanged, Expected: width == 0, Got: %d, %d, %d, %d", c.name, x, y, width, height)
+		}
+		after.Set(3, 1, bytecolor.Color('x'))
+		after.Set(1, 2, bytecolor.Color('x'))
+		x, y, width, height = changedRegion(c.before, after)
+		if x != 1 || y != 1 || width != 3 || height != 2 {
+			t.Errorf("Case: %s, Changed, Expected: 1, 1, 3, 2, Got: %d, %d, %d, %d", c.name, x, y, width, height)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package collab
+
+import (
+	"net/http"
+)
+
+// page is a minimal web client of the hub.
+// It connects to the path "ws" relative to itself.
+const page = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Drawing Challenge</title>
+<style>
+canvas { border: 1px solid #888; image-rendering: pixelated; width: 100%; max-width: 800px; }
+#log { color: #c00; font-family: monospace; }
+</style>
+</head>
+<body>
+<canvas id="canvas" width="0" height="0"></canvas>
+<form id="form"><input id="command" size="40" autofocus placeholder="enter command"></form>
+<div id="log"></div>
+<script>
+var canvas = document.getElementById("canvas");
+var ctx = canvas.getContext("2d");
+var url = new URL("ws", location.href);
+url.protocol = url.protocol.replace("http", "ws");
+var ws = new WebSocket(url);
+ws.onmessage = function (e) {
+	var msg = JSON.parse(e.data);
+	if (msg.type === "error") {
+		document.getElementById("log").textContent = "line " + msg.line + ": " + msg.error;
+		return;
+	}
+	if (msg.type === "canvas") {
+		canvas.width = msg.width;
+		canvas.height = msg.height;
+	}
+	ctx.clearRect(msg.x, msg.y, msg.width, msg.height);
+	for (var j = 0; j < msg.height; j++) {
+		for (var i = 0; i < msg.width; i++) {
+			ctx.fillStyle = msg.pixels[j][i];
+			ctx.fillRect(msg.x + i, msg.y + j, 1, 1);
+		}
+	}
+};
+ws.onclose = function () {
+	document.getElementById("log").textContent = "disconnected";
+};
+document.getElementById("form").onsubmit = function (e) {
+	e.preventDefault();
+	var input = document.getElementById("command");
+	document.getElementById("log").textContent = "";
+	ws.send(input.value);
+	input.value = "";
+};
+</script>
+</body>
+</html>
+`
+
+// PageHandler is a net/http.Handler serving a minimal web client of the hub.
+// The hub should be served at the path "ws" relative to the page.
+type PageHandler struct{}
+
+// Ensure that PageHandler implements the net/http.Handler interface.
+var (
+	_ http.Handler = PageHandler{}
+)
+
+// ServeHTTP serves the web client.
+func (h PageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(page))
+}
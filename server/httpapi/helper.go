@@ -0,0 +1,37 @@
+package httpapi
+
+import (
+	"strings"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/renderer"
+)
+
+// discardRenderer is the renderer of the environments of the sessions.
+// The canvases are not rendered after each command,
+// since they are retrieved by the clients on demand.
+type discardRenderer struct{}
+
+// Ensure that discardRenderer implements the renderer.Renderer interface.
+var (
+	_ renderer.Renderer = discardRenderer{}
+)
+
+// Render does nothing.
+func (rdr discardRenderer) Render(cnv canvas.Canvas) error {
+	return nil
+}
+
+// splitLines splits s into lines like bufio.ScanLines.
+// A trailing "\r" is removed from each line,
+// and there is no empty line after the final "\n".
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
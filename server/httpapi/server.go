@@ -0,0 +1,513 @@
+// Package httpapi defines the Server type,
+// which exposes an interpreter over HTTP.
+//
+// The following endpoints are supported:
+//
+//     POST   /sessions                 Creates a session.
+//     DELETE /sessions/{id}            Deletes a session.
+//     POST   /sessions/{id}/commands   Executes the commands in the body.
+//     GET    /sessions/{id}/canvas     Returns the canvas.
+//
+// Each session owns its own simple.SyncEnvironment. The dimensions of the
+// canvases, the pixels in the history, the commands in the macros,
+// and the number of sessions are limited, and the sessions which
+// have not been used for a while are deleted.
+// The commands are in the same syntax as those entered in the console,
+// one command per line. The format of the canvas is specified
+// by the "format" query parameter, like "/sessions/{id}/canvas?format=png".
+package httpapi
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	"github.com/asukakenji/drawing-challenge/command"
+	"github.com/asukakenji/drawing-challenge/common"
+	"github.com/asukakenji/drawing-challenge/interpreter"
+	"github.com/asukakenji/drawing-challenge/interpreter/simple"
+	"github.com/asukakenji/drawing-challenge/renderer"
+)
+
+const (
+	// DefaultFormat is the format of the canvas
+	// if the "format" query parameter is not specified.
+	DefaultFormat = "text"
+
+	// MaxCommandsSize is the maximum size of the body
+	// of a request executing commands.
+	MaxCommandsSize = 1 << 20
+
+	// DefaultMaxCanvasWidth is the default maximum width of the canvases.
+	DefaultMaxCanvasWidth = 1000
+
+	// DefaultMaxCanvasHeight is the default maximum height of the canvases.
+	DefaultMaxCanvasHeight = 1000
+
+	// DefaultMaxHistoryPixels is the default maximum number of pixels
+	// in the history of undo, and in the history of redo, of each session.
+	// It allows only a few changes of the largest canvases to be undone,
+	// but many more changes of the smaller ones.
+	DefaultMaxHistoryPixels = 2 * DefaultMaxCanvasWidth * DefaultMaxCanvasHeight
+
+	// DefaultMaxMacroCommands is the default maximum number of commands
+	// in the macros of each session.
+	DefaultMaxMacroCommands = 10000
+
+	// DefaultMaxSessions is the default maximum number of sessions.
+	DefaultMaxSessions = 1000
+
+	// DefaultSessionTimeout is the default duration after which
+	// a session not being used is deleted.
+	DefaultSessionTimeout = 30 * time.Minute
+
+	// statusUnprocessableEntity is the status responded
+	// if a command could not be executed.
+	statusUnprocessableEntity = 422
+)
+
+// format is a format of the canvas.
+type format struct {
+	contentType     string
+	newRendererFunc func(io.Writer) (renderer.Renderer, error)
+}
+
+// session is a session of the server.
+type session struct {
+	senv     *simple.SyncEnvironment
+	lastUsed time.Time
+}
+
+// Server is a server exposing an interpreter over HTTP.
+// It implements the net/http.Handler interface.
+type Server struct {
+	newCanvasFunc    func(int, int) (canvas.Canvas, error)
+	parser           command.Parser
+	interp           interpreter.Interpreter
+	historyDepth     int
+	maxHistoryPixels int
+	maxMacroCommands int
+	maxWidth         int
+	maxHeight        int
+	maxSessions      int
+	sessionTimeout   time.Duration
+	formats          map[string]format
+	now              func() time.Time
+	mutex            sync.Mutex
+	sessions         map[string]*session
+}
+
+// Ensure that Server implements the net/http.Handler interface.
+var (
+	_ http.Handler = &Server{}
+)
+
+// NewServer returns a new Server.
+// The canvases of the sessions are created by newCanvasFunc.
+// The commands are parsed by parser, and interpreted by interp.
+// No formats of the canvas are supported until SetFormat is called.
+// The limits are DefaultMaxCanvasWidth, DefaultMaxCanvasHeight,
+// DefaultMaxHistoryPixels, DefaultMaxMacroCommands, DefaultMaxSessions,
+// and DefaultSessionTimeout until they are set.
+//
+// Errors
+//
+// common.ErrNilPointer:
+// Will be returned if newCanvasFunc == nil, parser == nil, or interp == nil.
+//
+func NewServer(newCanvasFunc func(int, int) (canvas.Canvas, error), parser command.Parser, interp interpreter.Interpreter) (*Server, error) {
+	if newCanvasFunc == nil {
+		return nil, common.ErrNilPointer
+	}
+	if parser == nil {
+		return nil, common.ErrNilPointer
+	}
+	if interp == nil {
+		return nil, common.ErrNilPointer
+	}
+	return &Server{
+		newCanvasFunc:    newCanvasFunc,
+		parser:           parser,
+		interp:           interp,
+		historyDepth:     simple.DefaultHistoryDepth,
+		maxHistoryPixels: DefaultMaxHistoryPixels,
+		maxMacroCommands: DefaultMaxMacroCommands,
+		maxWidth:         DefaultMaxCanvasWidth,
+		maxHeight:        DefaultMaxCanvasHeight,
+		maxSessions:      DefaultMaxSessions,
+		sessionTimeout:   DefaultSessionTimeout,
+		formats:          map[string]format{},
+		now:              time.Now,
+		sessions:         map[string]*session{},
+	}, nil
+}
+
+// SetHistoryDepth sets the maximum number of changes which could be undone
+// in the sessions created hereafter.
+// It should not be called while the server is serving requests.
+//
+// Errors
+//
+// common.ErrHistoryDepthNegative:
+// Will be returned if depth < 0.
+//
+func (srv *Server) SetHistoryDepth(depth int) error {
+	if depth < 0 {
+		return common.ErrHistoryDepthNegative
+	}
+	srv.historyDepth = depth
+	return nil
+}
+
+// SetMaxHistoryPixels limits the total number of pixels of the canvases
+// in the history of undo, and in the history of redo,
+// in the sessions created hereafter.
+// It should not be called while the server is serving requests.
+//
+// Errors
+//
+// common.ErrHistoryPixelsNotPositive:
+// Will be returned if pixels <= 0.
+//
+func (srv *Server) SetMaxHistoryPixels(pixels int) error {
+	if pixels <= 0 {
+		return common.ErrHistoryPixelsNotPositive
+	}
+	srv.maxHistoryPixels = pixels
+	return nil
+}
+
+// SetMaxMacroCommands limits the total number of commands in the macros
+// in the sessions created hereafter.
+// It should not be called while the server is serving requests.
+//
+// Errors
+//
+// common.ErrMacroLimitNotPositive:
+// Will be returned if n <= 0.
+//
+func (srv *Server) SetMaxMacroCommands(n int) error {
+	if n <= 0 {
+		return common.ErrMacroLimitNotPositive
+	}
+	srv.maxMacroCommands = n
+	return nil
+}
+
+// SetMaxCanvasSize limits the width and height of the canvases
+// in the sessions created hereafter.
+// It should not be called while the server is serving requests.
+//
+// Errors
+//
+// common.ErrWidthOrHeightNotPositive:
+// Will be returned if width <= 0, or height <= 0.
+//
+func (srv *Server) SetMaxCanvasSize(width, height int) error {
+	if width <= 0 || height <= 0 {
+		return common.ErrWidthOrHeightNotPositive
+	}
+	srv.maxWidth = width
+	srv.maxHeight = height
+	return nil
+}
+
+// SetMaxSessions sets the maximum number of sessions.
+// It should not be called while the server is serving requests.
+//
+// Errors
+//
+// common.ErrSessionLimitNotPositive:
+// Will be returned if n <= 0.
+//
+func (srv *Server) SetMaxSessions(n int) error {
+	if n <= 0 {
+		return common.ErrSessionLimitNotPositive
+	}
+	srv.maxSessions = n
+	return nil
+}
+
+// SetSessionTimeout sets the duration after which a session not being used
+// is deleted.
+// It should not be called while the server is serving requests.
+//
+// Errors
+//
+// common.ErrTimeoutNotPositive:
+// Will be returned if timeout <= 0.
+//
+func (srv *Server) SetSessionTimeout(timeout time.Duration) error {
+	if timeout <= 0 {
+		return common.ErrTimeoutNotPositive
+	}
+	srv.sessionTimeout = timeout
+	return nil
+}
+
+// SetFormat sets the function which creates the renderer
+// for the format of the canvas called name.
+// The responses are sent with contentType as the Content-Type header.
+// If newRendererFunc is nil, the format is no longer supported.
+// It should not be called while the server is serving requests.
+func (srv *Server) SetFormat(name, contentType string, newRendererFunc func(io.Writer) (renderer.Renderer, error)) {
+	if newRendererFunc == nil {
+		delete(srv.formats, name)
+		return
+	}
+	srv.formats[name] = format{
+		contentType:     contentType,
+		newRendererFunc: newRendererFunc,
+	}
+}
+
+// ServeHTTP serves the requests.
+func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "sessions" {
+		writeError(w, http.StatusNotFound, common.ErrResourceNotFound)
+		return
+	}
+	switch len(parts) {
+	case 1:
+		if r.Method != "POST" {
+			writeError(w, http.StatusMethodNotAllowed, common.ErrMethodNotAllowed)
+			return
+		}
+		srv.createSession(w, r)
+	case 2:
+		if r.Method != "DELETE" {
+			writeError(w, http.StatusMethodNotAllowed, common.ErrMethodNotAllowed)
+			return
+		}
+		srv.deleteSession(w, r, parts[1])
+	case 3:
+		switch parts[2] {
+		case "commands":
+			if r.Method != "POST" {
+				writeError(w, http.StatusMethodNotAllowed, common.ErrMethodNotAllowed)
+				return
+			}
+			srv.executeCommands(w, r, parts[1])
+		case "canvas":
+			if r.Method != "GET" {
+				writeError(w, http.StatusMethodNotAllowed, common.ErrMethodNotAllowed)
+				return
+			}
+			srv.getCanvas(w, r, parts[1])
+		default:
+			writeError(w, http.StatusNotFound, common.ErrResourceNotFound)
+		}
+	default:
+		writeError(w, http.StatusNotFound, common.ErrResourceNotFound)
+	}
+}
+
+// expireSessions deletes the sessions which have not been used
+// within the session timeout.
+// It must be called with the mutex locked.
+func (srv *Server) expireSessions(now time.Time) {
+	for id, s := range srv.sessions {
+		if now.Sub(s.lastUsed) >= srv.sessionTimeout {
+			delete(srv.sessions, id)
+		}
+	}
+}
+
+// session returns the environment of the session identified by id,
+// or nil if it does not exist. The session is marked as being used.
+func (srv *Server) session(id string) *simple.SyncEnvironment {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	now := srv.now()
+	srv.expireSessions(now)
+	s, ok := srv.sessions[id]
+	if !ok {
+		return nil
+	}
+	s.lastUsed = now
+	return s.senv
+}
+
+// createSession creates a session,
+// and responds with its ID in the form {"id":"..."}.
+func (srv *Server) createSession(w http.ResponseWriter, r *http.Request) {
+	env, err := simple.NewEnvironment(srv.newCanvasFunc, discardRenderer{})
+	if err != nil {
+		// NOTE: This should not happen since the arguments are not nil
+		panic(err)
+	}
+	err = env.SetHistoryDepth(srv.historyDepth)
+	if err != nil {
+		// NOTE: This should not happen since the depth is checked
+		panic(err)
+	}
+	err = env.SetMaxHistoryPixels(srv.maxHistoryPixels)
+	if err != nil {
+		// NOTE: This should not happen since the pixels are checked
+		panic(err)
+	}
+	err = env.SetMaxMacroCommands(srv.maxMacroCommands)
+	if err != nil {
+		// NOTE: This should not happen since the limit is checked
+		panic(err)
+	}
+	err = env.SetMaxCanvasSize(srv.maxWidth, srv.maxHeight)
+	if err != nil {
+		// NOTE: This should not happen since the size is checked
+		panic(err)
+	}
+	senv, err := simple.NewSyncEnvironment(env)
+	if err != nil {
+		// NOTE: This should not happen since env is not nil
+		panic(err)
+	}
+	id, err := newSessionID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	srv.mutex.Lock()
+	now := srv.now()
+	srv.expireSessions(now)
+	if len(srv.sessions) >= srv.maxSessions {
+		srv.mutex.Unlock()
+		writeError(w, http.StatusServiceUnavailable, common.ErrTooManySessions)
+		return
+	}
+	srv.sessions[id] = &session{senv, now}
+	srv.mutex.Unlock()
+	w.Header().Set("Location", "/sessions/"+id)
+	writeJSON(w, http.StatusCreated, map[string]string{"id": id})
+}
+
+// deleteSession deletes the session identified by id.
+func (srv *Server) deleteSession(w http.ResponseWriter, r *http.Request, id string) {
+	srv.mutex.Lock()
+	_, ok := srv.sessions[id]
+	delete(srv.sessions, id)
+	srv.mutex.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, common.ErrSessionNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// executeCommands executes the commands in the body of r, one per line,
+// in the session identified by id. It stops at the first failure.
+// The commands of concurrent requests to the same session may interleave.
+// It responds in the form {"executed":n} on success,
+// or {"executed":n,"line":k,"error":"..."} on failure.
+// The session is deleted if the environment should quit after the commands.
+func (srv *Server) executeCommands(w http.ResponseWriter, r *http.Request, id string) {
+	senv := srv.session(id)
+	if senv == nil {
+		writeError(w, http.StatusNotFound, common.ErrSessionNotFound)
+		return
+	}
+	// NOTE: The body is read entirely, so that nothing is executed if it is too large
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxCommandsSize))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	result := struct {
+		Executed int    `json:"executed"`
+		Line     int    `json:"line,omitempty"`
+		Error    string `json:"error,omitempty"`
+	}{}
+	status := http.StatusOK
+	lines := splitLines(string(body))
+	for i := 0; i < len(lines) && !senv.ShouldQuit(); i++ {
+		cmd, err := srv.parser.ParseCommand(lines[i])
+		if err == nil {
+			err = senv.Interpret(srv.interp, cmd)
+		}
+		if err != nil {
+			result.Line = i + 1
+			result.Error = err.Error()
+			status = statusUnprocessableEntity
+			break
+		}
+		result.Executed++
+	}
+	if senv.ShouldQuit() {
+		srv.mutex.Lock()
+		delete(srv.sessions, id)
+		srv.mutex.Unlock()
+	}
+	writeJSON(w, status, result)
+}
+
+// getCanvas responds with the canvas of the session identified by id,
+// in the format specified by the "format" query parameter.
+func (srv *Server) getCanvas(w http.ResponseWriter, r *http.Request, id string) {
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name = DefaultFormat
+	}
+	f, ok := srv.formats[name]
+	if !ok {
+		writeError(w, http.StatusBadRequest, common.ErrUnknownFormat)
+		return
+	}
+	senv := srv.session(id)
+	if senv == nil {
+		writeError(w, http.StatusNotFound, common.ErrSessionNotFound)
+		return
+	}
+	// NOTE: The output is buffered, so that the status could be changed on errors
+	buf := new(bytes.Buffer)
+	err := senv.View(func(cnv canvas.Canvas) error {
+		if cnv == nil {
+			return common.ErrCanvasNotCreated
+		}
+		rdr, err := f.newRendererFunc(buf)
+		if err != nil {
+			return err
+		}
+		return rdr.Render(cnv)
+	})
+	if err == common.ErrCanvasNotCreated {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", f.contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// newSessionID returns a new random session ID.
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// writeJSON responds with v encoded in JSON.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError responds with err in the form {"error":"..."}.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,469 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asukakenji/drawing-challenge/canvas"
+	bc "github.com/asukakenji/drawing-challenge/canvas/bytecolor"
+	"github.com/asukakenji/drawing-challenge/color/bytecolor"
+	"github.com/asukakenji/drawing-challenge/command/basic"
+	"github.com/asukakenji/drawing-challenge/common"
+	"github.com/asukakenji/drawing-challenge/interpreter/simple"
+	"github.com/asukakenji/drawing-challenge/renderer"
+	jsonrenderer "github.com/asukakenji/drawing-challenge/renderer/json"
+	pngrenderer "github.com/asukakenji/drawing-challenge/renderer/png"
+	"github.com/asukakenji/drawing-challenge/renderer/writer"
+)
+
+func newCanvas(width, height int) (canvas.Canvas, error) {
+	return bc.NewBuffer(width, height, bytecolor.Color(' '), bytecolor.Color('x'))
+}
+
+func newTestServer() *Server {
+	parser, err := basic.NewParser(new(bytecolor.Parser).ParseColor)
+	if err != nil {
+		panic(err)
+	}
+	interp, err := simple.NewInterpreter()
+	if err != nil {
+		panic(err)
+	}
+	srv, err := NewServer(newCanvas, parser, interp)
+	if err != nil {
+		panic(err)
+	}
+	srv.SetFormat("text", "text/plain; charset=utf-8", func(w io.Writer) (renderer.Renderer, error) {
+		return writer.NewRenderer(w)
+	})
+	srv.SetFormat("json", "application/json", func(w io.Writer) (renderer.Renderer, error) {
+		return jsonrenderer.NewRenderer(w, bytecolor.DefaultPalette())
+	})
+	srv.SetFormat("png", "image/png", func(w io.Writer) (renderer.Renderer, error) {
+		return pngrenderer.NewRenderer(w, bytecolor.DefaultPalette(), 1)
+	})
+	return srv
+}
+
+// do sends a request to ts, and returns the status code and the body.
+func do(ts *httptest.Server, method, path, body string) (int, string, http.Header) {
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		panic(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		panic(err)
+	}
+	return resp.StatusCode, string(data), resp.Header
+}
+
+// createSession creates a session on ts, and returns its ID.
+func createSession(ts *httptest.Server) string {
+	status, body, _ := do(ts, "POST", "/sessions", "")
+	if status != http.StatusCreated {
+		panic(body)
+	}
+	var result struct {
+		ID string `json:"id"`
+	}
+	err := json.Unmarshal([]byte(body), &result)
+	if err != nil {
+		panic(err)
+	}
+	return result.ID
+}
+
+func TestNewServer(t *testing.T) {
+	parser, _ := basic.NewParser(new(bytecolor.Parser).ParseColor)
+	interp, _ := simple.NewInterpreter()
+	_, err := NewServer(newCanvas, parser, interp)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+
+	_, err = NewServer(nil, parser, interp)
+	if err != common.ErrNilPointer {
+		t.Errorf("Case: newCanvasFunc == nil, Expected: %#v, Got: %#v", common.ErrNilPointer, err)
+	}
+	_, err = NewServer(newCanvas, nil, interp)
+	if err != common.ErrNilPointer {
+		t.Errorf("Case: parser == nil, Expected: %#v, Got: %#v", common.ErrNilPointer, err)
+	}
+	_, err = NewServer(newCanvas, parser, nil)
+	if err != common.ErrNilPointer {
+		t.Errorf("Case: interp == nil, Expected: %#v, Got: %#v", common.ErrNilPointer, err)
+	}
+}
+
+func TestServer_SetHistoryDepth(t *testing.T) {
+	srv := newTestServer()
+	err := srv.SetHistoryDepth(-1)
+	if err != common.ErrHistoryDepthNegative {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrHistoryDepthNegative, err)
+	}
+	err = srv.SetHistoryDepth(0)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	id := createSession(ts)
+	status, body, _ := do(ts, "POST", "/sessions/"+id+"/commands", "C 3 1\nL 1 1 3 1\nU\n")
+	if status != statusUnprocessableEntity || !strings.Contains(body, common.ErrNothingToUndo.Error()) {
+		t.Errorf("Expected: %d, %s, Got: %d, %s", statusUnprocessableEntity, common.ErrNothingToUndo, status, body)
+	}
+}
+
+func TestServer_SetMaxHistoryPixels(t *testing.T) {
+	srv := newTestServer()
+	err := srv.SetMaxHistoryPixels(0)
+	if err != common.ErrHistoryPixelsNotPositive {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrHistoryPixelsNotPositive, err)
+	}
+	err = srv.SetMaxHistoryPixels(6)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	id := createSession(ts)
+	status, body, _ := do(ts, "POST", "/sessions/"+id+"/commands", "C 3 1\nL 1 1 1 1\nL 2 1 2 1\nL 3 1 3 1\nU\nU\nU\n")
+	expected := `{"executed":6,"line":7,"error":"Nothing to undo"}` + "\n"
+	if status != statusUnprocessableEntity || body != expected {
+		t.Errorf("Expected: %d, %s, Got: %d, %s", statusUnprocessableEntity, expected, status, body)
+	}
+}
+
+func TestServer_SetMaxMacroCommands(t *testing.T) {
+	srv := newTestServer()
+	err := srv.SetMaxMacroCommands(0)
+	if err != common.ErrMacroLimitNotPositive {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrMacroLimitNotPositive, err)
+	}
+	err = srv.SetMaxMacroCommands(4)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	id := createSession(ts)
+	status, body, _ := do(ts, "POST", "/sessions/"+id+"/commands", "C 3 1\nMR m\nL 1 1 1 1\nL 2 1 2 1\nME\nMR n\nM m\n")
+	expected := `{"executed":6,"line":7,"error":"Too many macro commands"}` + "\n"
+	if status != statusUnprocessableEntity || body != expected {
+		t.Errorf("Expected: %d, %s, Got: %d, %s", statusUnprocessableEntity, expected, status, body)
+	}
+}
+
+func TestServer(t *testing.T) {
+	ts := httptest.NewServer(newTestServer())
+	defer ts.Close()
+
+	id := createSession(ts)
+	another := createSession(ts)
+	if id == another {
+		t.Errorf("Expected: different IDs, Got: %q", id)
+	}
+
+	// The canvas has not been created
+	status, body, _ := do(ts, "GET", "/sessions/"+id+"/canvas", "")
+	if status != http.StatusConflict || !strings.Contains(body, common.ErrCanvasNotCreated.Error()) {
+		t.Errorf("Expected: %d, Got: %d, %s", http.StatusConflict, status, body)
+	}
+
+	// Execute commands
+	status, body, _ = do(ts, "POST", "/sessions/"+id+"/commands", "C 4 2\nL 1 1 4 1\n\nB 1 2 R\n")
+	if status != http.StatusOK || body != `{"executed":4}`+"\n" {
+		t.Errorf("Expected: %d, Got: %d, %s", http.StatusOK, status, body)
+	}
+	status, body, _ = do(ts, "POST", "/sessions/"+id+"/commands", "L 1 1 1 2\nL 1 1 9 1\nL 1 1 1 2\n")
+	expected := `{"executed":1,"line":2,"error":"` + common.ErrPointOutsideCanvas.Error() + `"}` + "\n"
+	if status != statusUnprocessableEntity || body != expected {
+		t.Errorf("Expected: %d, %s, Got: %d, %s", statusUnprocessableEntity, expected, status, body)
+	}
+
+	// Get the canvas in different formats
+	cases := []struct {
+		query       string
+		contentType string
+		body        string
+	}{
+		{"", "text/plain; charset=utf-8", "------\n|xxxx|\n|xRRR|\n------\n\n"},
+		{"?format=text", "text/plain; charset=utf-8", "------\n|xxxx|\n|xRRR|\n------\n\n"},
+		{"?format=json", "application/json", `{"width":4,"height":2,"pixels":[["#000000","#000000","#000000","#000000"],["#000000","#ff0000","#ff0000","#ff0000"]]}` + "\n"},
+	}
+	for _, c := range cases {
+		status, body, header := do(ts, "GET", "/sessions/"+id+"/canvas"+c.query, "")
+		if status != http.StatusOK {
+			t.Errorf("Case: %q, Expected: %d, Got: %d", c.query, http.StatusOK, status)
+		}
+		if header.Get("Content-Type") != c.contentType {
+			t.Errorf("Case: %q, Expected: %q, Got: %q", c.query, c.contentType, header.Get("Content-Type"))
+		}
+		if body != c.body {
+			t.Errorf("Case: %q, Expected: %q, Got: %q", c.query, c.body, body)
+		}
+	}
+	status, body, header := do(ts, "GET", "/sessions/"+id+"/canvas?format=png", "")
+	if status != http.StatusOK || header.Get("Content-Type") != "image/png" {
+		t.Errorf("Expected: %d, image/png, Got: %d, %q", http.StatusOK, status, header.Get("Content-Type"))
+	}
+	img, err := png.Decode(bytes.NewReader([]byte(body)))
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	} else if img.Bounds().Dx() != 4 || img.Bounds().Dy() != 2 {
+		t.Errorf("Expected: 4x2, Got: %v", img.Bounds())
+	}
+
+	// The sessions are independent
+	status, body, _ = do(ts, "GET", "/sessions/"+another+"/canvas", "")
+	if status != http.StatusConflict {
+		t.Errorf("Expected: %d, Got: %d, %s", http.StatusConflict, status, body)
+	}
+
+	// The session is deleted after quitting
+	status, body, _ = do(ts, "POST", "/sessions/"+another+"/commands", "C 1 1\nQ\nC 2 2\n")
+	if status != http.StatusOK || body != `{"executed":2}`+"\n" {
+		t.Errorf("Expected: %d, Got: %d, %s", http.StatusOK, status, body)
+	}
+	status, _, _ = do(ts, "GET", "/sessions/"+another+"/canvas", "")
+	if status != http.StatusNotFound {
+		t.Errorf("Expected: %d, Got: %d", http.StatusNotFound, status)
+	}
+
+	// Delete the session
+	status, _, _ = do(ts, "DELETE", "/sessions/"+id, "")
+	if status != http.StatusNoContent {
+		t.Errorf("Expected: %d, Got: %d", http.StatusNoContent, status)
+	}
+	status, _, _ = do(ts, "DELETE", "/sessions/"+id, "")
+	if status != http.StatusNotFound {
+		t.Errorf("Expected: %d, Got: %d", http.StatusNotFound, status)
+	}
+}
+
+func TestServer_Neg(t *testing.T) {
+	srv := newTestServer()
+	srv.SetFormat("png", "", nil)
+	srv.SetFormat("broken", "text/plain", func(w io.Writer) (renderer.Renderer, error) {
+		return pngrenderer.NewRenderer(w, bytecolor.Palette{}, 1)
+	})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	id := createSession(ts)
+	status, body, _ := do(ts, "POST", "/sessions/"+id+"/commands", "C 4 2\n")
+	if status != http.StatusOK {
+		t.Errorf("Expected: %d, Got: %d, %s", http.StatusOK, status, body)
+	}
+
+	cases := []struct {
+		method string
+		path   string
+		body   string
+		status int
+		err    string
+	}{
+		{"GET", "/", "", http.StatusNotFound, common.ErrResourceNotFound.Error()},
+		{"GET", "/unknown", "", http.StatusNotFound, common.ErrResourceNotFound.Error()},
+		{"GET", "/sessions", "", http.StatusMethodNotAllowed, common.ErrMethodNotAllowed.Error()},
+		{"GET", "/sessions/" + id, "", http.StatusMethodNotAllowed, common.ErrMethodNotAllowed.Error()},
+		{"GET", "/sessions/" + id + "/commands", "", http.StatusMethodNotAllowed, common.ErrMethodNotAllowed.Error()},
+		{"POST", "/sessions/" + id + "/canvas", "", http.StatusMethodNotAllowed, common.ErrMethodNotAllowed.Error()},
+		{"GET", "/sessions/" + id + "/unknown", "", http.StatusNotFound, common.ErrResourceNotFound.Error()},
+		{"GET", "/sessions/" + id + "/canvas/unknown", "", http.StatusNotFound, common.ErrResourceNotFound.Error()},
+		{"DELETE", "/sessions/unknown", "", http.StatusNotFound, common.ErrSessionNotFound.Error()},
+		{"POST", "/sessions/unknown/commands", "C 1 1", http.StatusNotFound, common.ErrSessionNotFound.Error()},
+		{"GET", "/sessions/unknown/canvas", "", http.StatusNotFound, common.ErrSessionNotFound.Error()},
+		{"GET", "/sessions/" + id + "/canvas?format=png", "", http.StatusBadRequest, common.ErrUnknownFormat.Error()},
+		{"GET", "/sessions/" + id + "/canvas?format=broken", "", http.StatusInternalServerError, common.ErrColorNotSupported.Error()},
+		{"POST", "/sessions/" + id + "/commands", "Z", statusUnprocessableEntity, common.ErrUnknownCommand.Error()},
+		{"POST", "/sessions/" + id + "/commands", "S screen.png", statusUnprocessableEntity, common.ErrFileFormatNotSupported.Error()},
+		{"POST", "/sessions/" + id + "/commands", strings.Repeat("L 1 1 1 1\n", MaxCommandsSize/10+1), http.StatusRequestEntityTooLarge, ""},
+	}
+	for _, c := range cases {
+		status, body, _ := do(ts, c.method, c.path, c.body)
+		if status != c.status {
+			t.Errorf("Case: %s %s, Expected: %d, Got: %d", c.method, c.path, c.status, status)
+		}
+		if !strings.Contains(body, `"error":"`+c.err) {
+			t.Errorf("Case: %s %s, Expected: %q, Got: %q", c.method, c.path, c.err, body)
+		}
+	}
+}
+
+// TestServer_Concurrent should be run with the race detector enabled:
+//
+//     go test -race
+//
+func TestServer_Concurrent(t *testing.T) {
+	ts := httptest.NewServer(newTestServer())
+	defer ts.Close()
+	id := createSession(ts)
+	do(ts, "POST", "/sessions/"+id+"/commands", "C 4 2\n")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				status, body, _ := do(ts, "POST", "/sessions/"+id+"/commands", "FR 1 1 4 2 R R\nFR 1 1 4 2 G G\n")
+				if status != http.StatusOK {
+					t.Errorf("Expected: %d, Got: %d, %s", http.StatusOK, status, body)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				status, body, _ := do(ts, "GET", "/sessions/"+id+"/canvas?format=json", "")
+				if status != http.StatusOK || (strings.Contains(body, "#ff0000") && strings.Contains(body, "#00ff00")) {
+					t.Errorf("Expected: %d and a consistent canvas, Got: %d, %s", http.StatusOK, status, body)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestServer_SetMaxCanvasSize(t *testing.T) {
+	srv := newTestServer()
+	err := srv.SetMaxCanvasSize(0, 2)
+	if err != common.ErrWidthOrHeightNotPositive {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrWidthOrHeightNotPositive, err)
+	}
+	err = srv.SetMaxCanvasSize(4, 2)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	id := createSession(ts)
+	cases := []struct {
+		body     string
+		status   int
+		expected string
+	}{
+		{"C 5 2\n", statusUnprocessableEntity, `{"executed":0,"line":1,"error":"Canvas too large"}` + "\n"},
+		{"C 4 3\n", statusUnprocessableEntity, `{"executed":0,"line":1,"error":"Canvas too large"}` + "\n"},
+		{"C 4 2\nSC 8 2\n", statusUnprocessableEntity, `{"executed":1,"line":2,"error":"Canvas too large"}` + "\n"},
+		{"EX 0 0 1 0\n", statusUnprocessableEntity, `{"executed":0,"line":1,"error":"Canvas too large"}` + "\n"},
+		{"RO 90\n", statusUnprocessableEntity, `{"executed":0,"line":1,"error":"Canvas too large"}` + "\n"},
+		{"CR 1 1 2 2\nRO 90\nEX 1 0 1 0\n", http.StatusOK, `{"executed":3}` + "\n"},
+	}
+	for _, c := range cases {
+		status, body, _ := do(ts, "POST", "/sessions/"+id+"/commands", c.body)
+		if status != c.status || body != c.expected {
+			t.Errorf("Case: %q, Expected: %d, %s, Got: %d, %s", c.body, c.status, c.expected, status, body)
+		}
+	}
+}
+
+func TestServer_SetMaxSessions(t *testing.T) {
+	srv := newTestServer()
+	err := srv.SetMaxSessions(0)
+	if err != common.ErrSessionLimitNotPositive {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrSessionLimitNotPositive, err)
+	}
+	err = srv.SetMaxSessions(1)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	id := createSession(ts)
+	status, body, _ := do(ts, "POST", "/sessions", "")
+	if status != http.StatusServiceUnavailable || !strings.Contains(body, common.ErrTooManySessions.Error()) {
+		t.Errorf("Expected: %d, %s, Got: %d, %s", http.StatusServiceUnavailable, common.ErrTooManySessions, status, body)
+	}
+	status, _, _ = do(ts, "DELETE", "/sessions/"+id, "")
+	if status != http.StatusNoContent {
+		t.Errorf("Expected: %d, Got: %d", http.StatusNoContent, status)
+	}
+	createSession(ts)
+}
+
+func TestServer_SetSessionTimeout(t *testing.T) {
+	srv := newTestServer()
+	err := srv.SetSessionTimeout(0)
+	if err != common.ErrTimeoutNotPositive {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrTimeoutNotPositive, err)
+	}
+	err = srv.SetSessionTimeout(time.Minute)
+	if err != nil {
+		t.Errorf("Expected: err == nil, Got: %#v", err)
+	}
+	err = srv.SetMaxSessions(2)
+	if err != nil {
+		panic(err)
+	}
+	now := time.Now()
+	srv.now = func() time.Time {
+		return now
+	}
+
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	idle := createSession(ts)
+	now = now.Add(30 * time.Second)
+	used := createSession(ts)
+	now = now.Add(40 * time.Second)
+	status, _, _ := do(ts, "POST", "/sessions/"+used+"/commands", "C 1 1\n")
+	if status != http.StatusOK {
+		t.Errorf("Expected: %d, Got: %d", http.StatusOK, status)
+	}
+
+	// The idle session has expired, so there is room for another one
+	createSession(ts)
+	status, _, _ = do(ts, "GET", "/sessions/"+idle+"/canvas", "")
+	if status != http.StatusNotFound {
+		t.Errorf("Expected: %d, Got: %d", http.StatusNotFound, status)
+	}
+	now = now.Add(50 * time.Second)
+	status, _, _ = do(ts, "GET", "/sessions/"+used+"/canvas", "")
+	if status != http.StatusOK {
+		t.Errorf("Expected: %d, Got: %d", http.StatusOK, status)
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	cases := []struct {
+		s        string
+		expected []string
+	}{
+		{"", nil},
+		{"\n", []string{""}},
+		{"C 4 2", []string{"C 4 2"}},
+		{"C 4 2\n", []string{"C 4 2"}},
+		{"C 4 2\r\nL 1 1 4 1\r\n", []string{"C 4 2", "L 1 1 4 1"}},
+		{"C 4 2\n\nU", []string{"C 4 2", "", "U"}},
+	}
+	for _, c := range cases {
+		got := splitLines(c.s)
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("Case: %q, Expected: %#v, Got: %#v", c.s, c.expected, got)
+		}
+	}
+}
@@ -0,0 +1,471 @@
+// Package websocket defines the Conn type,
+// which is a minimal implementation of the WebSocket protocol (RFC 6455)
+// supporting text messages only.
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+const (
+	// MaxMessageSize is the maximum size of a message received.
+	MaxMessageSize = 1 << 20
+
+	// DefaultReadTimeout is the default maximum duration of reading a frame.
+	// The peer should send a frame, like a pong frame, within this duration,
+	// so that the connection is not considered dead.
+	DefaultReadTimeout = 60 * time.Second
+
+	// acceptGUID is the GUID used to compute Sec-WebSocket-Accept.
+	acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+)
+
+// The opcodes of the frames.
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+// The status codes of the close frames.
+const (
+	closeNormal          = 1000
+	closeProtocolError   = 1002
+	closeUnsupportedData = 1003
+	closeInvalidData     = 1007
+	closeMessageTooBig   = 1009
+)
+
+// Conn is a WebSocket connection.
+// ReadMessage should be called from one goroutine only,
+// while WriteMessage and Close could be called from any goroutines.
+type Conn struct {
+	conn        net.Conn
+	reader      *bufio.Reader
+	client      bool
+	readTimeout time.Duration
+
+	writeMutex sync.Mutex
+	closed     bool
+}
+
+// Upgrade upgrades the HTTP connection of r to a WebSocket connection.
+// A response is written to w if the handshake fails.
+//
+// The Origin header is sent by the browsers, and it is checked
+// to prevent the pages of other sites from connecting on behalf of the users.
+// r is accepted only if the header is absent,
+// or if its host is the same as that of r.
+//
+// Errors
+//
+// common.ErrBadHandshake:
+// Will be returned if r is not a valid WebSocket opening handshake.
+//
+// common.ErrOriginNotAllowed:
+// Will be returned if r is sent from a page of another origin.
+//
+// Errors returned from hijacking the connection are returned without modifications.
+//
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != "GET" ||
+		!headerContainsToken(r.Header, "Connection", "upgrade") ||
+		!headerContainsToken(r.Header, "Upgrade", "websocket") ||
+		r.Header.Get("Sec-WebSocket-Version") != "13" ||
+		key == "" {
+		http.Error(w, common.ErrBadHandshake.Error(), http.StatusBadRequest)
+		return nil, common.ErrBadHandshake
+	}
+	if !sameOrigin(r) {
+		http.Error(w, common.ErrOriginNotAllowed.Error(), http.StatusForbidden)
+		return nil, common.ErrOriginNotAllowed
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, common.ErrBadHandshake.Error(), http.StatusInternalServerError)
+		return nil, common.ErrBadHandshake
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	rw.WriteString("Upgrade: websocket\r\n")
+	rw.WriteString("Connection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n")
+	err = rw.Flush()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Conn{
+		conn:        conn,
+		reader:      rw.Reader,
+		readTimeout: DefaultReadTimeout,
+	}, nil
+}
+
+// Dial opens a WebSocket connection to rawURL, like "ws://localhost:8080/ws".
+//
+// Errors
+//
+// common.ErrBadHandshake:
+// Will be returned if rawURL is not a "ws" URL,
+// or if the server does not accept the opening handshake.
+//
+// Errors returned from parsing rawURL and connecting to the server
+// are returned without modifications.
+//
+func Dial(rawURL string) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "ws" {
+		return nil, common.ErrBadHandshake
+	}
+	host := u.Host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		// NOTE: The port is missing, and the brackets of IPv6 addresses
+		// are added back by net.JoinHostPort
+		host = net.JoinHostPort(strings.Trim(host, "[]"), "80")
+	}
+	conn, err := net.Dial("tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, 16)
+	_, err = rand.Read(nonce)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	req := &http.Request{
+		Method:     "GET",
+		URL:        u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: http.Header{
+			"Upgrade":               {"websocket"},
+			"Connection":            {"Upgrade"},
+			"Sec-WebSocket-Key":     {key},
+			"Sec-WebSocket-Version": {"13"},
+		},
+		Host: u.Host,
+	}
+	err = req.Write(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		conn.Close()
+		return nil, common.ErrBadHandshake
+	}
+	return &Conn{
+		conn:        conn,
+		reader:      reader,
+		client:      true,
+		readTimeout: DefaultReadTimeout,
+	}, nil
+}
+
+// SetReadTimeout sets the maximum duration of reading a frame.
+// There is no limit if timeout is 0.
+// It should be called from the goroutine calling ReadMessage.
+func (c *Conn) SetReadTimeout(timeout time.Duration) {
+	c.readTimeout = timeout
+}
+
+// ReadMessage reads a text message.
+// Ping frames are answered automatically, and pong frames are ignored.
+// Each frame must be read completely within the read timeout,
+// which is reset after each frame, including the pong frames.
+//
+// Errors
+//
+// io.EOF:
+// Will be returned if the connection is closed by the peer.
+//
+// common.ErrProtocolViolation:
+// Will be returned if a frame violates the protocol.
+//
+// common.ErrMessageNotText:
+// Will be returned if a binary message is received.
+//
+// common.ErrMessageTooBig:
+// Will be returned if the message is larger than MaxMessageSize.
+//
+// common.ErrMessageNotUTF8:
+// Will be returned if the message is not valid UTF-8.
+//
+// The connection is closed on the above errors, except io.EOF.
+// Errors returned from the underlying connection, including the timeout errors,
+// are returned without modifications.
+//
+func (c *Conn) ReadMessage() (string, error) {
+	var message []byte
+	started := false
+	for {
+		fin, opcode, payload, err := c.readFrame(MaxMessageSize - len(message))
+		if err != nil {
+			return "", err
+		}
+		switch opcode {
+		case opClose:
+			c.writeClose(closeNormal)
+			c.conn.Close()
+			return "", io.EOF
+		case opPing:
+			err = c.writeFrame(opPong, payload)
+			if err != nil {
+				return "", err
+			}
+			continue
+		case opPong:
+			// NOTE: The read timeout is reset when the next frame is read
+			continue
+		case opBinary:
+			c.fail(closeUnsupportedData)
+			return "", common.ErrMessageNotText
+		case opText:
+			if started {
+				c.fail(closeProtocolError)
+				return "", common.ErrProtocolViolation
+			}
+			started = true
+		case opContinuation:
+			if !started {
+				c.fail(closeProtocolError)
+				return "", common.ErrProtocolViolation
+			}
+		default:
+			c.fail(closeProtocolError)
+			return "", common.ErrProtocolViolation
+		}
+		message = append(message, payload...)
+		if fin {
+			if !utf8.Valid(message) {
+				c.fail(closeInvalidData)
+				return "", common.ErrMessageNotUTF8
+			}
+			return string(message), nil
+		}
+	}
+}
+
+// readFrame reads a frame whose payload is not larger than limit.
+// The payload is unmasked if the frame is sent by a client.
+// The frame must be read completely within the read timeout.
+func (c *Conn) readFrame(limit int) (bool, byte, []byte, error) {
+	var deadline time.Time
+	if c.readTimeout > 0 {
+		deadline = time.Now().Add(c.readTimeout)
+	}
+	err := c.conn.SetReadDeadline(deadline)
+	if err != nil {
+		return false, 0, nil, err
+	}
+	var header [2]byte
+	_, err = io.ReadFull(c.reader, header[:])
+	if err != nil {
+		return false, 0, nil, err
+	}
+	fin := header[0]&0x80 != 0
+	opcode := header[0] & 0x0f
+	masked := header[1]&0x80 != 0
+	if header[0]&0x70 != 0 || masked == c.client {
+		// NOTE: Extensions are not supported, and only the client masks frames
+		c.fail(closeProtocolError)
+		return false, 0, nil, common.ErrProtocolViolation
+	}
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		_, err = io.ReadFull(c.reader, ext[:])
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		_, err = io.ReadFull(c.reader, ext[:])
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if err != nil {
+		return false, 0, nil, err
+	}
+	if opcode >= opClose && (length > 125 || !fin) {
+		// NOTE: Control frames must not be fragmented nor larger than 125 bytes
+		c.fail(closeProtocolError)
+		return false, 0, nil, common.ErrProtocolViolation
+	}
+	if length > uint64(limit) {
+		c.fail(closeMessageTooBig)
+		return false, 0, nil, common.ErrMessageTooBig
+	}
+	var mask [4]byte
+	if masked {
+		_, err = io.ReadFull(c.reader, mask[:])
+		if err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload := make([]byte, length)
+	_, err = io.ReadFull(c.reader, payload)
+	if err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// WriteMessage writes a text message.
+//
+// Errors
+//
+// common.ErrConnectionClosed:
+// Will be returned if the connection is already closed.
+//
+// Errors returned from the underlying connection are returned without modifications.
+//
+func (c *Conn) WriteMessage(message string) error {
+	return c.writeFrame(opText, []byte(message))
+}
+
+// Ping writes a ping frame.
+// The peer answers it with a pong frame, which resets the read timeout.
+//
+// Errors
+//
+// common.ErrConnectionClosed:
+// Will be returned if the connection is already closed.
+//
+// Errors returned from the underlying connection are returned without modifications.
+//
+func (c *Conn) Ping() error {
+	return c.writeFrame(opPing, nil)
+}
+
+// writeFrame writes an unfragmented frame.
+// The payload is masked if the frame is sent by a client.
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	if c.closed {
+		return common.ErrConnectionClosed
+	}
+	frame := []byte{0x80 | opcode, 0}
+	length := len(payload)
+	switch {
+	case length <= 125:
+		frame[1] = byte(length)
+	case length <= 0xffff:
+		frame[1] = 126
+		frame = append(frame, byte(length>>8), byte(length))
+	default:
+		frame[1] = 127
+		var ext [8]byte
+		binary.BigEndian.PutUint64(ext[:], uint64(length))
+		frame = append(frame, ext[:]...)
+	}
+	if c.client {
+		var mask [4]byte
+		_, err := rand.Read(mask[:])
+		if err != nil {
+			return err
+		}
+		frame[1] |= 0x80
+		frame = append(frame, mask[:]...)
+		masked := make([]byte, length)
+		for i := range payload {
+			masked[i] = payload[i] ^ mask[i%4]
+		}
+		payload = masked
+	}
+	_, err := c.conn.Write(append(frame, payload...))
+	return err
+}
+
+// writeClose writes a close frame with status code.
+func (c *Conn) writeClose(code int) error {
+	err := c.writeFrame(opClose, []byte{byte(code >> 8), byte(code)})
+	c.writeMutex.Lock()
+	c.closed = true
+	c.writeMutex.Unlock()
+	return err
+}
+
+// fail closes the connection with status code.
+func (c *Conn) fail(code int) {
+	c.writeClose(code)
+	c.conn.Close()
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	c.writeClose(closeNormal)
+	return c.conn.Close()
+}
+
+// acceptKey returns the value of Sec-WebSocket-Accept for key.
+func acceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// sameOrigin returns whether the Origin header of r is absent,
+// or has the same host as r.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// headerContainsToken returns whether the comma-separated list
+// in the header called name contains token, case-insensitively.
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, value := range header[http.CanonicalHeaderKey(name)] {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
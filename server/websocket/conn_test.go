@@ -0,0 +1,283 @@
+package websocket
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asukakenji/drawing-challenge/common"
+)
+
+// newEchoServer returns a server echoing the messages received,
+// and a channel receiving the error ending each connection.
+func newEchoServer() (*httptest.Server, chan error) {
+	return newTimeoutEchoServer(DefaultReadTimeout)
+}
+
+// newTimeoutEchoServer is the same as newEchoServer,
+// but the read timeout of the connections is set to timeout.
+func newTimeoutEchoServer(timeout time.Duration) (*httptest.Server, chan error) {
+	errs := make(chan error, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			errs <- err
+			return
+		}
+		conn.SetReadTimeout(timeout)
+		for {
+			message, err := conn.ReadMessage()
+			if err != nil {
+				errs <- err
+				return
+			}
+			conn.WriteMessage(message)
+		}
+	}))
+	return ts, errs
+}
+
+// wsURL returns the WebSocket URL of ts.
+func wsURL(ts *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(ts.URL, "http")
+}
+
+func TestAcceptKey(t *testing.T) {
+	// NOTE: The example in RFC 6455
+	expected := "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="
+	got := acceptKey("dGhlIHNhbXBsZSBub25jZQ==")
+	if got != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, got)
+	}
+}
+
+func TestConn(t *testing.T) {
+	ts, errs := newEchoServer()
+	defer ts.Close()
+
+	conn, err := Dial(wsURL(ts))
+	if err != nil {
+		t.Fatalf("Expected: err == nil, Got: %#v", err)
+	}
+	cases := []string{
+		"",
+		"C 20 4",
+		strings.Repeat("x", 126),
+		strings.Repeat("y", 0x10000),
+		"日本語",
+	}
+	for _, c := range cases {
+		err := conn.WriteMessage(c)
+		if err != nil {
+			t.Errorf("Case: %d bytes, Expected: err == nil, Got: %#v", len(c), err)
+		}
+		got, err := conn.ReadMessage()
+		if err != nil {
+			t.Errorf("Case: %d bytes, Expected: err == nil, Got: %#v", len(c), err)
+		}
+		if got != c {
+			t.Errorf("Case: %d bytes, Expected: %d bytes, Got: %d bytes", len(c), len(c), len(got))
+		}
+	}
+
+	// A fragmented message with a ping in between
+	conn.conn.Write([]byte{0x01, 0x82, 0, 0, 0, 0, 'a', 'b'})
+	conn.conn.Write([]byte{0x89, 0x81, 0, 0, 0, 0, 'p'})
+	conn.conn.Write([]byte{0x80, 0x81, 0, 0, 0, 0, 'c'})
+	got, err := conn.ReadMessage()
+	if err != nil || got != "abc" {
+		t.Errorf("Case: Fragmented, Expected: %q, Got: %q, %#v", "abc", got, err)
+	}
+
+	conn.Close()
+	err = <-errs
+	if err != io.EOF {
+		t.Errorf("Case: Closed, Expected: %#v, Got: %#v", io.EOF, err)
+	}
+	err = conn.WriteMessage("C 20 4")
+	if err != common.ErrConnectionClosed {
+		t.Errorf("Case: Write after Close, Expected: %#v, Got: %#v", common.ErrConnectionClosed, err)
+	}
+}
+
+func TestConn_Neg(t *testing.T) {
+	ts, errs := newEchoServer()
+	defer ts.Close()
+
+	casesNeg := []struct {
+		name     string
+		frame    []byte
+		expected error
+	}{
+		{"Binary", []byte{0x82, 0x81, 0, 0, 0, 0, 'x'}, common.ErrMessageNotText},
+		{"Unmasked", []byte{0x81, 0x01, 'x'}, common.ErrProtocolViolation},
+		{"Reserved Bits", []byte{0xc1, 0x81, 0, 0, 0, 0, 'x'}, common.ErrProtocolViolation},
+		{"Unknown Opcode", []byte{0x83, 0x81, 0, 0, 0, 0, 'x'}, common.ErrProtocolViolation},
+		{"Unexpected Continuation", []byte{0x80, 0x81, 0, 0, 0, 0, 'x'}, common.ErrProtocolViolation},
+		{"Fragmented Ping", []byte{0x09, 0x81, 0, 0, 0, 0, 'x'}, common.ErrProtocolViolation},
+		{"Too Big", []byte{0x81, 0xff, 0, 0, 0, 0, 0, 0x20, 0, 0}, common.ErrMessageTooBig},
+		{"Invalid UTF-8", []byte{0x81, 0x82, 0, 0, 0, 0, 0xff, 0xfe}, common.ErrMessageNotUTF8},
+		{"Split UTF-8", []byte{0x01, 0x81, 0, 0, 0, 0, 0xe6, 0x80, 0x81, 0, 0, 0, 0, 0x97}, common.ErrMessageNotUTF8},
+	}
+	for _, c := range casesNeg {
+		conn, err := Dial(wsURL(ts))
+		if err != nil {
+			t.Fatalf("Case: %s, Expected: err == nil, Got: %#v", c.name, err)
+		}
+		conn.conn.Write(c.frame)
+		err = <-errs
+		if err != c.expected {
+			t.Errorf("Case: %s, Expected: %#v, Got: %#v", c.name, c.expected, err)
+		}
+		// The server closes the connection after sending a close frame
+		_, err = conn.ReadMessage()
+		if err != io.EOF {
+			t.Errorf("Case: %s, Expected: %#v, Got: %#v", c.name, io.EOF, err)
+		}
+		conn.Close()
+	}
+}
+
+func TestConn_ReadTimeout(t *testing.T) {
+	ts, errs := newTimeoutEchoServer(100 * time.Millisecond)
+	defer ts.Close()
+
+	// The read timeout is reset by the pong frames
+	conn, err := Dial(wsURL(ts))
+	if err != nil {
+		t.Fatalf("Expected: err == nil, Got: %#v", err)
+	}
+	for i := 0; i < 5; i++ {
+		time.Sleep(50 * time.Millisecond)
+		conn.conn.Write([]byte{0x8a, 0x80, 0, 0, 0, 0})
+	}
+	conn.WriteMessage("x")
+	got, err := conn.ReadMessage()
+	if err != nil || got != "x" {
+		t.Errorf("Case: Pong, Expected: %q, Got: %q, %#v", "x", got, err)
+	}
+	conn.Close()
+	<-errs
+
+	// A frame which is not completed times out
+	conn, err = Dial(wsURL(ts))
+	if err != nil {
+		t.Fatalf("Expected: err == nil, Got: %#v", err)
+	}
+	defer conn.Close()
+	conn.conn.Write([]byte{0x81, 0x85, 0, 0, 0, 0, 'x'})
+	err = <-errs
+	if nerr, ok := err.(net.Error); !ok || !nerr.Timeout() {
+		t.Errorf("Case: Incomplete, Expected: a timeout error, Got: %#v", err)
+	}
+}
+
+func TestUpgrade_Neg(t *testing.T) {
+	ts, errs := newEchoServer()
+	defer ts.Close()
+
+	casesNeg := []struct {
+		name   string
+		method string
+		header map[string]string
+	}{
+		{"Not WebSocket", "GET", map[string]string{}},
+		{"Wrong Method", "POST", map[string]string{"Connection": "Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "13", "Sec-WebSocket-Key": "x"}},
+		{"Wrong Version", "GET", map[string]string{"Connection": "Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "8", "Sec-WebSocket-Key": "x"}},
+		{"No Key", "GET", map[string]string{"Connection": "keep-alive, Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "13"}},
+	}
+	for _, c := range casesNeg {
+		req, err := http.NewRequest(c.method, ts.URL, nil)
+		if err != nil {
+			panic(err)
+		}
+		for name, value := range c.header {
+			req.Header.Set(name, value)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Case: %s, Expected: err == nil, Got: %#v", c.name, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Case: %s, Expected: %d, Got: %d", c.name, http.StatusBadRequest, resp.StatusCode)
+		}
+		err = <-errs
+		if err != common.ErrBadHandshake {
+			t.Errorf("Case: %s, Expected: %#v, Got: %#v", c.name, common.ErrBadHandshake, err)
+		}
+	}
+}
+
+func TestUpgrade_Origin(t *testing.T) {
+	ts, errs := newEchoServer()
+	defer ts.Close()
+
+	req, err := http.NewRequest("GET", ts.URL, nil)
+	if err != nil {
+		panic(err)
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "x")
+	req.Header.Set("Origin", "http://evil.example.com")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Expected: err == nil, Got: %#v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected: %d, Got: %d", http.StatusForbidden, resp.StatusCode)
+	}
+	err = <-errs
+	if err != common.ErrOriginNotAllowed {
+		t.Errorf("Expected: %#v, Got: %#v", common.ErrOriginNotAllowed, err)
+	}
+}
+
+func TestSameOrigin(t *testing.T) {
+	cases := []struct {
+		origin   string
+		host     string
+		expected bool
+	}{
+		{"", "localhost:8080", true},
+		{"http://localhost:8080", "localhost:8080", true},
+		{"https://LOCALHOST:8080", "localhost:8080", true},
+		{"http://localhost:8081", "localhost:8080", false},
+		{"http://evil.example.com", "localhost:8080", false},
+		{"null", "localhost:8080", false},
+		{"%", "localhost:8080", false},
+	}
+	for _, c := range cases {
+		r := &http.Request{Header: http.Header{}, Host: c.host}
+		if c.origin != "" {
+			r.Header.Set("Origin", c.origin)
+		}
+		got := sameOrigin(r)
+		if got != c.expected {
+			t.Errorf("Case: %q, Expected: %t, Got: %t", c.origin, c.expected, got)
+		}
+	}
+}
+
+func TestDial_Neg(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	casesNeg := []string{
+		ts.URL,
+		wsURL(ts),
+	}
+	for _, c := range casesNeg {
+		_, err := Dial(c)
+		if err != common.ErrBadHandshake {
+			t.Errorf("Case: %q, Expected: %#v, Got: %#v", c, common.ErrBadHandshake, err)
+		}
+	}
+}